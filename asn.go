@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// Looks up the announcing ASN and holder of each IP. IPs within a prefix already seen are
+// not looked up again. IPs that can not be looked up are omitted.
+func lookupASNs(ips []string) map[string]bsw.ASNInfo {
+	byIP := make(map[string]bsw.ASNInfo)
+	holders := make(map[string]string)
+	type network struct {
+		ipnet *net.IPNet
+		info  bsw.ASNInfo
+	}
+	networks := []network{}
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		found := false
+		for _, n := range networks {
+			if n.ipnet.Contains(parsed) {
+				byIP[ip] = n.info
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		info, err := bsw.RIPEStatNetwork(ip)
+		if err != nil {
+			continue
+		}
+		holder, ok := holders[info.ASN]
+		if !ok {
+			holder, _ = bsw.RIPEStatHolder(info.ASN)
+			holders[info.ASN] = holder
+		}
+		info.Holder = holder
+		byIP[ip] = info
+		if _, ipnet, err := net.ParseCIDR(info.Prefix); err == nil {
+			networks = append(networks, network{ipnet, info})
+		}
+	}
+	return byIP
+}
+
+// Parses a comma separated list of ASNs with or without the AS prefix into a set.
+func parseASNs(list string) map[string]bool {
+	asns := make(map[string]bool)
+	for _, a := range strings.Split(list, ",") {
+		a = strings.ToUpper(strings.TrimSpace(a))
+		if a == "" {
+			continue
+		}
+		if !strings.HasPrefix(a, "AS") {
+			a = "AS" + a
+		}
+		asns[a] = true
+	}
+	return asns
+}
+
+// Returns the IPs announced by an ASN in only, when only is not empty, and not announced by an
+// ASN in skip. IPs with an unknown ASN are only kept when only is empty.
+func filterASNs(ips []string, byIP map[string]bsw.ASNInfo, only, skip map[string]bool) []string {
+	filtered := []string{}
+	for _, ip := range ips {
+		info, ok := byIP[ip]
+		if len(only) > 0 && (!ok || !only[info.ASN]) {
+			continue
+		}
+		if ok && skip[info.ASN] {
+			continue
+		}
+		filtered = append(filtered, ip)
+	}
+	return filtered
+}
+
+// Writes the number of IPs and prefixes announced by each ASN.
+// Returns r with the ASN announcing its IP, if known, in its metadata.
+func withASN(r bsw.Result, byIP map[string]bsw.ASNInfo) bsw.Result {
+	info, ok := byIP[r.IP]
+	if !ok {
+		return r
+	}
+	meta := map[string]string{"asn": info.ASN, "asn_holder": info.Holder}
+	for k, v := range r.Meta {
+		meta[k] = v
+	}
+	r.Meta = meta
+	return r
+}
+
+func writeASNSummary(w io.Writer, ips []string, byIP map[string]bsw.ASNInfo) {
+	counts := make(map[string]int)
+	prefixes := make(map[string]map[string]bool)
+	holders := make(map[string]string)
+	for _, ip := range ips {
+		info, ok := byIP[ip]
+		if !ok {
+			info = bsw.ASNInfo{ASN: "unknown"}
+		}
+		counts[info.ASN]++
+		holders[info.ASN] = info.Holder
+		if prefixes[info.ASN] == nil {
+			prefixes[info.ASN] = make(map[string]bool)
+		}
+		if info.Prefix != "" {
+			prefixes[info.ASN][info.Prefix] = true
+		}
+	}
+	asns := []string{}
+	for a := range counts {
+		asns = append(asns, a)
+	}
+	sort.Slice(asns, func(i, j int) bool { return counts[asns[i]] > counts[asns[j]] })
+	fmt.Fprintln(w, "Scope by ASN:")
+	for _, a := range asns {
+		list := []string{}
+		for p := range prefixes[a] {
+			list = append(list, p)
+		}
+		sort.Strings(list)
+		fmt.Fprintf(w, "  %-10s %-40s %6d ips  %s\n", a, holders[a], counts[a], strings.Join(list, ", "))
+	}
+}
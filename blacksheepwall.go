@@ -5,72 +5,313 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"os"
+	"path"
 	"regexp"
 	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"text/tabwriter"
+	"text/template"
+	"time"
 
 	"github.com/tomsteele/blacksheepwall/bsw"
+	"golang.org/x/net/publicsuffix"
 )
 
 const usage = `
  Usage: blacksheepwall [options] <ip address or CIDR>
+        blacksheepwall wordlist <list|fetch> [options]
+        blacksheepwall run <scan file>
+        blacksheepwall capabilities
 
  Options:
   -h, --help            Show Usage and exit.
 
   -version              Show version and exit.
 
-  -debug                Enable debugging and show errors returned from tasks.
+  -debug                Enable debugging and show errors returned from tasks, and after
+                        the tasks complete the requests made by each HTTP source.
+
+  -profile <string>     Use the named profile from the config file. A profile holds a
+                        set of options and environment variables, such as the API keys
+                        and upload destination for one client. Other options on the
+                        command line take precedence, but API keys, -thehive, and
+                        -upload may only be set by the profile, and upload credentials
+                        are only read from the profile's env.
+
+  -config <string>      JSON config file containing profiles, e.g.
+                        {"profiles": {"acme": {"options": {"shodan": "KEY"},
+                        "env": {"AWS_ACCESS_KEY_ID": "..."}}}}
+                        [default: blacksheepwall/config.json in the user config directory]
+                        The file may also replace how a scraper (bing-html, baidu, yahoo,
+                        he, duckduckgo, netcraft, viewdns-html, robtex-html) finds hostnames,
+                        to work around a change to a site without rebuilding, e.g.
+                        {"extractors": {"bing-html": [{"selector": "cite"},
+                        {"selector": "a", "attr": "href", "pattern": "u=([^&]+)"}]}}
+                        selects elements, reads an attribute or else the text, and uses
+                        the pattern's first submatch when it matches.
+
+  -machine              Disable the progress spinner and write only newline delimited
+                        JSON events to stdout: each unique result as it is found, task
+                        errors, progress every 5 seconds, and a final summary. Intended
+                        for containers and log collectors.
+
+  -include-reserved     When a CIDR network is expanded, keep reserved addresses such as
+                        loopback, multicast, and broadcast, which are skipped by
+                        default. IPs given on their own are always scanned.
+
+  -skip-net-broadcast   When an IPv4 CIDR network larger than a /31 is expanded, leave
+                        out its network and broadcast addresses, e.g. 10.0.0.0 and
+                        10.0.0.255 of 10.0.0.0/24.
+
+  -low-memory           Trade speed for memory, for large scopes on small machines. IPs
+                        are generated as they are scanned rather than listed up front,
+                        unless -asn, -shodan, -rdns-file, or -template need the list,
+                        fewer tasks are queued at once, and results are written to disk
+                        once more than 100,000 are held. In -machine mode a result may
+                        be reported more than once.
+
+  -spill-threshold <int> Once more unique results than this are held in memory they are
+                        sorted and written to temporary files, which are merged when the
+                        results are output. Unless -anomalies, -parked, -summary, -geo,
+                        -template, -upload, -thehive, -indicators, or -openioc need every
+                        result at once, the merged results are written out as they are
+                        read. 0 keeps every result in memory.      [default: 1000000]
+
+  -dry-run              List the tasks that would be run, without running them. Tasks
+                        that depend on results, such as -delegations, -tftp, and
+                        -verify-ports, are not included.
+
+  -plan-json            With -dry-run, write the tasks as JSON along with the options
+                        and targets they were planned for, so that the plan can be
+                        split across machines. Credentials and the -redact key are
+                        named but not included.
+
+  -execute-plan <string> Run only the tasks in a plan written by -plan-json, or a subset
+                        of its tasks, using its options and targets. Credentials the plan
+                        names must be given again. A marker is logged as each task
+                        finishes, or with -machine a plan_task event with its id.
 
   -timeout              Maximum timeout in seconds for SOCKET connections.  [default .5 seconds]
 
   -concurrency <int>    Max amount of concurrent tasks.    [default: 100]
+                        Lowered with a warning when it would exceed the limit of open
+                        files of the process, since tasks past it fail to dial.
+
+  -max-fd <int>         Limit of open files to keep tasks within, instead of the limit
+                        detected from the process (ulimit -n). Windows has no such limit,
+                        so it is only kept when this is provided.
+
+  -retries <int>        Amount of times to retry a task that timed out.    [default: 0]
+                        Rate limited tasks are always retried, waiting as long as the
+                        source's Retry-After asks. Meanwhile the source's other tasks
+                        are paused, and then run one at a time rather than failing.
+
+  -quota <string>       Budget of requests for sources, in the form
+                        <source>=<requests>/<period> separated by commas, e.g.
+                        shodan=100/24h,virustotal=500/1h. Once a budget is used up the
+                        source is treated as rate limited until its period ends. Only
+                        sources that call a web API or site can be given a budget.
+
+  -quota-ledger <file>  File the requests counted against -quota are kept in, shared by
+                        every blacksheepwall process using it, so that parallel scans with
+                        the same keys do not exhaust the budget another scan needs.
+
+  -tor-control <string> Address of a Tor control port, e.g. 127.0.0.1:9051. When a
+                        scraper (-bing-html, -baidu, -yahoo, -he, -duckduckgo, -netcraft,
+                        -viewdns-html, -robtex-html) is served a CAPTCHA or block page its
+                        tasks are paused with a warning, and with this option a new Tor
+                        circuit is requested. Route requests through Tor with
+                        HTTP_PROXY/HTTPS_PROXY=socks5://127.0.0.1:9050.
+                        The control password is read from TOR_CONTROL_PASSWORD.
+
+  -delay <int>          Delay in milliseconds each goroutine waits before starting
+                        a task.    [default: 0]
+
+  -T1 ... -T5           Timing template that sets -concurrency, -retries, -delay, and
+                        -timeout together. -T1 and -T2 are slow and stealthy, -T3 is
+                        the default, and -T4 and -T5 are for fast and reliable networks.
+                        Any of these options provided explicitly take precedence.
 
   -server <string>      DNS server address.    [default: "8.8.8.8"]
 
+  -resolvers <string>   Line separated file of "<domain> <server>" rules selecting the
+                        DNS server used for matching names, e.g. "corp.example.com 10.0.0.53".
+                        A domain matches itself and all subdomains, "*.<domain>" matches
+                        only subdomains. Names that match no rule use -server.
+
+  -0x20                 Send each DNS query name in random mixed case and discard answers
+                        that do not echo it exactly. Some resolvers do not preserve case.
+
+  -random-ports         Send each DNS query from a randomly chosen source port.
+
+  -scan-id[=<label>]    Tag the scan's DNS queries so they can be attributed to it in the
+                        target's DNS logs. The label, random if not given, is prepended to
+                        the name queried by wildcard checks and, unless -edns-client is
+                        set, sent as "blacksheepwall/<label>" in an EDNS0 option (code
+                        65001) with every query.
+
+  -edns-client <string> Send this string in an EDNS0 option (code 65001) with every query.
+
+  -dnssec               Validate answers from signed zones back to the root trust anchors
+                        and record the status (secure, insecure, or bogus) in each result's
+                        metadata. Requires a resolver that returns DNSSEC records.
+
+  -prefer-authoritative Send queries for names within each domain to the domain's
+                        authoritative name servers instead of -server, bypassing stale
+                        recursive caches. Queries that a name server refuses, fails, or
+                        does not answer are retried with -server, and a name server that
+                        does so repeatedly is no longer used.
+
   -input <string>       Line separated file of networks (CIDR) or
                         IP Addresses.
 
+  -asn                  Before scanning, lookup the ASN and holder announcing each ip using
+                        RIPEstat and print a summary of the scope grouped by ASN. Results
+                        are annotated with the ASN in their metadata.
+
+  -only-asn <string>    Comma separated list of ASNs. Only scan ips announced by these
+                        ASNs. Implies -asn.
+
+  -skip-asn <string>    Comma separated list of ASNs. Do not scan ips announced by these
+                        ASNs, e.g. CDN or ISP space. Implies -asn.
+
   -ipv6                 Look for additional AAAA records where applicable.
 
   -domain <string>      Target domain to use for certain tasks, can be a
                         single domain or a file of line separated domains.
 
-  -fcrdns               Verify results by attempting to retrieve the A or AAAA record for
-                        each result previously identified hostname.
+  -fcrdns[=<mode>]      Verify results by attempting to retrieve the A or AAAA records for
+                        each previously identified hostname and comparing them to the
+                        result's ip. Modes are "strict", only keeping confirmed results,
+                        "annotate", keeping every result marked as confirmed or
+                        unconfirmed, and "off".   [default: off, strict if no mode given]
+
+  -anomalies            Flag results that resolve to known parking or sinkhole networks,
+                        or whose hostname does not fit the naming of the other hostnames
+                        found for the domain. Reasons are recorded in the "anomaly"
+                        metadata of each result.
+
+  -parked               Mark results that resolve to known parking or sinkhole networks,
+                        that are a parking or sinkhole name server found by -ns or
+                        -delegations, or that are within a zone served by one. The service
+                        is recorded in the "parked" metadata of each result.
+  -skip-parked          Leave out the results -parked would mark.
+  -parked-list <string> Line separated file extending the built in parking and sinkhole
+                        list used by -parked, -skip-parked, and -anomalies, with lines
+                        of "<ip, network, or name server domain> [name]".
+
+  -summary              After the results, list each registrable domain with the number
+                        of unique hostnames, IPs, and IP ranges (/24 or /64) found within
+                        it, and the sources that contributed. With -json, -csv, -template,
+                        or -machine the summary is written to stderr.
+
+  -geo                  After the results, group the IPs found by country, city, and
+                        hosting provider, located with ip-api.com's free batch API, with
+                        the number of unique IPs and hostnames in each. Written to stderr
+                        in the same cases as -summary.
 
   -parse <string>       Generate output by parsing JSON from a file from a previous scan.
 
   -validate             Validate hostnames using a RFC compliant regex.
 
+  -level <string>       Highest level of active task that may run, one of safe,
+                        normal, or intrusive. At safe, only passive tasks may run.
+                        Tasks in the Active section require normal and tasks in
+                        the Intrusive section require intrusive.    [default: "normal"]
+
  Passive:
   -dictionary <string>  Attempt to retrieve the CNAME and A record for
-                        each subdomain in the line separated file.
+                        each subdomain in the line separated file. Use
+                        builtin:small or builtin:medium for a wordlist
+                        embedded in the binary, or wordlist:<name> for one
+                        downloaded with blacksheepwall wordlist fetch.
+                        Append :top=N to use only the N most frequent names.
+                        Names are resolved most frequent first, using a count
+                        on each line if present, or else the line order.
 
   -ns                   Lookup the ip and hostname of any nameservers for the domain.
 
   -mx                   Lookup the ip and hostmame of any mx records for the domain.
 
+  -caa                  Lookup the CAA records for the domain. The certificate authorities
+                        and accounts allowed to issue are kept in metadata, and the hosts
+                        of iodef contact URLs are resolved.
+
+  -soa                  Lookup the SOA record for the domain and the ip and hostname of its
+                        primary nameserver and of its administrator mailbox's domain.
+
+  -txt                  Lookup the TXT records for the domain and follow the includes and
+                        redirects of its SPF record, up to 10 lookups. Hosts named by a
+                        and mx mechanisms are resolved, and networks named by ip4 and ip6
+                        mechanisms are kept in "netblock" metadata.
+  -txt-expand           Scan each address of the networks found by -txt in the SPF
+                        records of the domain and its subdomains with the ip based
+                        options. Networks of more than 65536 addresses are skipped.
+
+  -dmarc                Lookup the DMARC record for the domain and find the ips/hostnames
+                        of the domains its aggregate (rua) and forensic (ruf) reports are
+                        sent to, which often name the mail security provider.
+
+  -dkim[=<selectors>]   Lookup the DKIM key record of each selector for the domain,
+                        confirming <selector>._domainkey.<domain> names. Selectors are
+                        read from a file, builtin:<name>, or wordlist:<name> like
+                        -dictionary, or a built in list of common selectors by default.
+
+  -typosquat            Generate lookalikes of the registrable domain by replacing each
+                        character with a homoglyph (ASCII or Unicode, in punycode) or a
+                        bit flipped character, and by swapping the top level domain.
+                        Registered lookalikes are reported with their ips when they
+                        resolve, marked "live" in metadata.
+
   -yandex <string>      Provided a Yandex search XML API url. Use the Yandex
                         search 'rhost:' operator to find subdomains of a
                         provided domain.
 
-  -bing <string>        Provided a base64 encoded API key. Use the Bing search
-                        API's 'ip:' operator to lookup hostnames for each ip, and the
-                        'domain:' operator to find ips/hostnames for a domain.
+  -bing <string>        Provided a Bing Web Search API v7 subscription key. Use the Bing
+                        search API's 'ip:' operator to lookup hostnames for each ip, and
+                        the 'domain:' operator to find ips/hostnames for a domain.
+
+  -bing-legacy          Treat the -bing key as a base64 encoded key for the deprecated
+                        Azure DataMarket Bing Search API.
 
   -bing-html            Use Bing search 'ip:' operator to lookup hostname for each ip, and the
                         'domain:' operator to find ips/hostnames for a domain. Only
                         the first page is scraped. This does not use the API.
 
+  -baidu                Use Baidu search 'site:' operator to find ips/hostnames for a
+                        domain. Only the first page is scraped. This does not use an API.
+
+  -yahoo                Use Yahoo search 'ip:' operator to lookup hostnames for each ip,
+                        and the 'site:' operator to find ips/hostnames for a domain. Only
+                        the first page is scraped. This does not use an API.
+
+  -he                   Scrape the DNS tab of Hurricane Electric's BGP Toolkit
+                        (bgp.he.net) for the hostnames associated with each ip, and the
+                        ips of the domain and the hostnames it lists within the domain,
+                        at most one request every 2 seconds. This does not use an API.
+
+  -netcraft             Search Netcraft's searchdns for sites ending with the domain,
+                        scraping up to 10 pages of the HTML results at most one request
+                        every 2 seconds. This does not use an API.
+
+  -duckduckgo           Use DuckDuckGo's 'site:' operator to find hostnames for a domain,
+                        scraping up to 5 pages of the HTML results at most one request
+                        every 3 seconds. This does not use an API.
+
   -shodan <string>      Provided a Shodan API key. Use Shodan's API '/dns/reverse' to lookup hostnames for
                         each ip, and '/shodan/host/search' to lookup ips/hostnames for a domain.
                         A single call is made for all ips.
@@ -78,51 +319,339 @@ const usage = `
 
   -reverse              Retrieve the PTR for each host.
 
+  -reverse-confirm      With -reverse, check that each PTR name resolves back to the host
+                        as part of the same task, recording the result in the FCRDNS
+                        column. Unlike -fcrdns, other sources are not checked.
+
+  -skip-bogons          With -reverse, skip hosts in private (RFC 1918) and other bogon
+                        ranges, such as shared, link-local, documentation, and multicast.
 
   -viewdns-html         Lookup each host using viewdns.info's Reverse IP
                         Lookup function. Use sparingly as they will block you.
 
   -viewdns <string>     Lookup each host using viewdns.info's API and Reverse IP Lookup function.
 
-  -robtex               Lookup each host using robtex.com
+  -reverse-mx           Find other domains using each mail server found by -mx with
+                        viewdns.info's API and Reverse MX Lookup function. Requires -viewdns.
+
+  -reverse-ns           Find other domains using each name server found by -ns with
+                        viewdns.info's API and Reverse NS Lookup function. Requires -viewdns.
+
+  -robtex               Lookup each host and/or domain using robtex.com's passive DNS API.
+
+  -robtex-html          Lookup each host by scraping robtex.com's HTML pages.
+
+  -reverseip-api        Lookup other domains hosted on each host, and on the IP of each
+                        domain, using the reverse IP lookup at domains.yougetsignal.com.
+                        The free lookup allows a limited number of requests a day.
+
+  -logontube            Deprecated, logontube.com no longer responds. Runs -reverseip-api,
+                        or -hackertarget if it is not responding, with a warning.
+
+  -skip-health-check    Sources that may have gone offline (-reverseip-api, -hackertarget,
+                        -robtex) are checked at startup and disabled with a warning if they
+                        do not respond. This skips the check.
+
+  -crtsh                Search crt.sh's certificate transparency logs for names on
+                        certificates issued to the domain and its subdomains.
+
+  -certspotter[=<key>]  Search SSLMate's Cert Spotter issuances API for names on
+                        certificates issued to the domain and its subdomains. The
+                        API key is optional, without one few requests are allowed.
 
-  -logontube            Lookup each host and/or domain using logontube.com's API.
+  -fb-ct <string>       Provided a Facebook Graph API access token. Search Facebook's
+                        certificate transparency monitor for names on certificates
+                        issued to the domain and its subdomains.
+
+  -securitytrails <string> Provided a SecurityTrails API key. Use SecurityTrails' API
+                        '/domains/list' to lookup hostnames for each ip, and
+                        '/domain/{domain}/subdomains' to find subdomains of a domain.
+
+  -virustotal <string>  Provided a VirusTotal API key. Use VirusTotal's v3 API
+                        '/ip_addresses/{ip}/resolutions' to lookup hostnames for each
+                        ip, and '/domains/{domain}/subdomains' to find subdomains of
+                        a domain.
+
+  -dnsdb <string>       Provided a Farsight DNSDB API key. Use DNSDB's rdata lookup to
+                        find hostnames for each ip, and rrset lookup to find A and AAAA
+                        records for a domain and its subdomains.
+
+  -dnsdb-after <string> Only return DNSDB records last observed after this time.
+
+  -dnsdb-before <string> Only return DNSDB records first observed before this time.
+                        Times are a date (2006-01-02), a unix timestamp, or a duration
+                        before now (e.g. 720h).
+
+  -circl <user:pass>    Provided CIRCL passive DNS credentials. Lookup hostnames that
+                        have resolved to each ip, and A and AAAA records for a domain.
+
+  -otx                  Use AlienVault OTX's passive DNS API to lookup hostnames for each
+                        ip, and ips/hostnames for a domain and its subdomains. No API
+                        key is required.
+
+  -binaryedge <string>  Provided a BinaryEdge API key. Use BinaryEdge's API
+                        '/query/domains/ip/' to lookup hostnames for each ip, and
+                        '/query/domains/subdomain/' to find subdomains of a domain.
+
+  -zoomeye <string>     Provided a ZoomEye API key. Use ZoomEye's web search and 'ip:'
+                        filter to lookup hostnames for each ip, and 'site:' filter to
+                        find ips/hostnames for a domain. At most 10 pages are requested.
+
+  -fofa <email:key>     Provided FOFA API credentials. Use FOFA's search API and 'ip='
+                        query to lookup hostnames for each ip, and 'domain=' query to
+                        find ips/hostnames for a domain.
+
+  -onyphe <string>      Provided an Onyphe API key. Use Onyphe's resolver/forward and
+                        resolver/reverse APIs to lookup hostnames for each ip, and
+                        search its resolver category to find ips/hostnames for a domain.
+
+  -netlas <string>      Provided a Netlas API key. Use Netlas's domains search and 'a:'
+                        query to lookup hostnames for each ip, and 'domain:' query to
+                        find ips/hostnames for a domain. At most 10 pages are requested.
+
+  -leakix[=<key>]       Use LeakIX's host endpoint to lookup hostnames for each ip, and
+                        domain endpoint to find ips/hostnames for a domain. The API key
+                        is optional. Requests are limited to one per second for each key.
+
+  -whoisxml <string>    Provided a WhoisXML API key. Use WhoisXML's reverse IP API to
+                        lookup hostnames for each ip, and subdomains lookup API to find
+                        subdomains of a domain. At most 10 pages are requested.
+
+  -reverse-whois <string> With -whoisxml, use WhoisXML's reverse WHOIS API to find domains
+                        whose WHOIS record contains a registrant email address or
+                        organization. The domains are scanned with the domain options,
+                        except those that guess names or query the domain's own
+                        servers: -dictionary, -srv, -dkim, -axfr, -nsec-walk, and
+                        -nsec3-hashes.
+
+  -reverse-whois-active Also run the options that guess names or query the domain's own
+                        servers against the domains found by -reverse-whois.
+
+  -ipinfo <string>      Provided an IPinfo token. Use IPinfo's hosted domains API to find
+                        the domains hosted on each ip. At most 10 pages of 1000 are
+                        requested.
+
+  -hunterio <string>    Provided a Hunter API key. Use Hunter's domain search API to find
+                        email addresses for a domain, and return the hostnames of the
+                        addresses and of the pages they were found on that resolve.
+
+  -hackertarget         Use hackertarget.com's reverseiplookup API to lookup hostnames for
+                        each ip, and hostsearch API to find ips/hostnames for a domain.
+                        Requests are limited to one per second.
+
+  -bufferover[=<key>]   Query bufferover.run's DNS and TLS datasets for ips/hostnames
+                        for a domain and its subdomains. The API key is optional.
+
+  -threatcrowd          Use ThreatCrowd's IP report to lookup hostnames for each ip, and
+                        domain report to find ips and subdomains for a domain. Requests
+                        are limited to one every ten seconds as its terms of use ask.
+
+  -threatminer          Use ThreatMiner's host passive DNS report to lookup hostnames for
+                        each ip, and domain passive DNS and subdomain reports to find
+                        ips/hostnames for a domain. Requests are limited to ten a minute.
+
+  -wayback              Search the Internet Archive's Wayback Machine for hostnames in
+                        archived URLs for the domain and its subdomains.
+
+  -anubis               Find subdomains of the domain in the Anubis database at jldc.me.
+
+  -chaos <string>       Provided a ProjectDiscovery Chaos API key. Find subdomains of the
+                        domain in the Chaos dataset, returning those that resolve.
+
+  -github <string>      Provided a GitHub token. Search code on GitHub for the domain and
+                        extract subdomains from matched fragments, returning those that
+                        resolve. Requests are limited to ten a minute.
+
+  -fdns <string>        Path to a gzip compressed Rapid7 Project Sonar forward DNS dataset.
+                        The file is streamed once for records of each domain and its
+                        subdomains.
+
+  -rdns-file <string>   Path to a gzip compressed Rapid7 Project Sonar reverse DNS dataset.
+                        The file is streamed once for the PTR names of every ip.
+
+  -resolve              Resolve hostnames found by sources that do not provide ips
+                        (-wayback, -anubis), discarding those that do not resolve.
+                        Otherwise they are returned without an ip.
 
 
  Active:
   -srv                  Find DNS SRV record and retrieve associated hostname/IP info.
 
-  -axfr                 Attempt a zone transfer on the domain.
+  -delegations          Lookup NS records for the parents of each hostname found within the
+                        domain to find delegated subzones. Subzones are scanned with the
+                        same options as the domain, querying their authoritative servers.
+
+  -axfr                 Attempt a zone transfer on the domain. SRV, MX, CNAME, TXT, and NS
+                        records are kept with their data in metadata, and delegated
+                        subzones are scanned with the same options as the domain.
+
+  -nsec-walk            Enumerate every name in a domain signed with DNSSEC NSEC records
+                        by following the chain of records, each naming the next name in
+                        the zone, for a complete listing when a zone transfer is refused.
+                        The record types at each name are kept in "nsec_types" metadata.
+                        Zones signed with NSEC3 or online signers can not be walked.
+
+  -nsec3-hashes <file>  Collect the hashed names of each domain signed with DNSSEC NSEC3
+                        records by querying names that hash into gaps of the chain, and
+                        write them to <file> for cracking offline.
+  -nsec3-format <fmt>   Format of -nsec3-hashes, hashcat (mode 8300) or john.
+                        Default: hashcat.
+  -nsec3-cracked <file> Read hashes cracked by hashcat in the form
+                        <hash>:.<zone>:<salt>:<iterations>:<label>, and add each
+                        hostname with its ip. The hash is kept in "nsec3_hash" metadata.
 
   -headers              Perform HTTP(s) requests to each host and look for
                         hostnames in a possible Location header.
 
   -tls                  Attempt to retrieve names from TLS certificates
-                        (CommonName and Subject Alternative Name).
+                        (CommonName and Subject Alternative Name). With -headers
+                        the certificate is read from the same connection as the
+                        https request, so each host is connected to once.
+                        Hostnames covered by a wildcard name are marked with it in
+                        metadata, and the domain of a wildcard that is a subdomain of
+                        -domain is scanned with the same options as the domain.
+
+  -http-ports <string>  Comma separated ports, beyond 80 and 443, to probe with -headers
+                        and -tls, e.g. "8080,8443,10443". TLS is tried first on each
+                        port, or plaintext first on ports usually serving plain HTTP,
+                        falling back to the other. The scheme found is kept in metadata.
+
+  -tls-cert <string>    PEM client certificate presented by -tls, -headers, and -smtp,
+  -tls-key <string>     and its private key, for mTLS gated services.
+
+  -tls-min <string>     Minimum and maximum TLS versions, one of 1.0, 1.1, 1.2, or 1.3.
+  -tls-max <string>     Lower -tls-min to reach old appliances.    [default: Go's defaults]
+
+  -tls-ciphers <string> Comma separated cipher suite names to offer, e.g.
+                        "TLS_RSA_WITH_AES_128_CBC_SHA,TLS_RSA_WITH_3DES_EDE_CBC_SHA".
+                        Insecure suites may be named. Does not apply to TLS 1.3.
+
+  -smtp                 Connect to SMTP on each host and look for hostnames in the
+                        banner, EHLO, NOOP, VRFY, and EXPN responses, as well as
+                        the certificate offered by STARTTLS.
+
+  -verify-ports <string> After all other tasks complete, attempt a TCP connection to
+                        each comma separated port for every identified hostname and
+                        IP, e.g. "80,443,22". Open ports are added to the results.
+
+  -ntp                  Send an NTP mode 6 'readvar' request to each host and look
+                        for hostnames in the refid and system variables. Requests
+                        are sent at a low rate and results are low confidence.
+
+ Intrusive:
+  -intrusive            Shorthand for -level intrusive.
+
+  -tftp                 After all other tasks complete, request common configuration
+                        files and files named after each identified hostname from a
+                        TFTP server on each IP. Retrieved files are parsed for hostnames.
 
  Output Options:
   -clean                Print results as unique hostnames for each host.
   -csv                  Print results in csv format.
   -json                 Print results as JSON.
+  -redact[=<key>]       Mask the last octet of each ip (the last 64 bits for IPv6) and
+                        replace each hostname with a keyed HMAC-SHA256 hash, for sharing
+                        results. The same key always gives the same hash, so redacted
+                        sets can be correlated. Without a key a random one is used.
+                        Hostnames, ips, and networks within metadata, such as TXT
+                        records, are redacted the same way, networks being hashed.
+                        Applies to all output, -upload, -thehive, and -parse.
+  -template <string>    Print results using a Go text/template file. The template is
+                        executed with .Version, .Started, .Finished, .Domains, .IPs,
+                        and .Results, each result having .Source, .IP, .Hostname,
+                        .Port, and .Protocol.
+
+ Export Options:
+  -thehive <string>     Base URL of a TheHive instance. After output, raise an alert
+                        with an observable for each hostname and IP found.
+  -thehive-key <string> TheHive API key used with -thehive.
+
+  -indicators <string>  After output, write a CSV file of indicators for blocklist and
+                        abuse teams, one row per unique hostname and IP with the columns
+                        hostname, ip, first_seen, and source. first_seen is when the scan
+                        first found the pair, and multiple sources are space separated.
+  -openioc <string>     After output, write an OpenIOC 1.0 XML file matching any of the
+                        unique hostnames (DnsEntryItem/Host) or IPs (PortItem/remoteIP).
+
+  -upload <string>      Upload the output and a JSON archive of the raw results to
+                        object storage under a timestamped key, e.g.
+                        "s3://bucket/prefix/" or "gs://bucket/prefix/". S3 credentials
+                        are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY,
+                        AWS_SESSION_TOKEN, and AWS_REGION. GCS uses HMAC keys read
+                        from GOOGLE_HMAC_ACCESS_ID and GOOGLE_HMAC_SECRET.
 
 `
 
+// ipExpansion holds the options for turning networks into IP addresses. Reserved addresses
+// within a network, such as multicast and broadcast, are skipped unless includeReserved is set.
+// Addresses given on their own are always kept. With skipNetBroadcast the network and broadcast
+// addresses of IPv4 networks larger than a /31 are left out.
+type ipExpansion struct {
+	includeReserved  bool
+	skipNetBroadcast bool
+}
+
 // Processes a list of IP addresses or networks in CIDR format.
-// Returning a list of all possible IP addresses.
-func linesToIPList(lines []string) ([]string, error) {
+// Returning a list of all possible IP addresses and the number that were skipped.
+func linesToIPList(lines []string, opts ipExpansion) ([]string, int, error) {
 	ipList := []string{}
+	skipped, err := eachIP(lines, opts, func(ip string) { ipList = append(ipList, ip) })
+	return ipList, skipped, err
+}
+
+// Returns the network and broadcast addresses of an IPv4 network larger than a /31, or nil
+// for other networks.
+func netBroadcast(network *net.IPNet) (net.IP, net.IP) {
+	ip := network.IP.To4()
+	ones, bits := network.Mask.Size()
+	if ip == nil || bits != 32 || ones > 30 {
+		return nil, nil
+	}
+	last := make(net.IP, len(ip))
+	for i := range ip {
+		last[i] = ip[i] | ^network.Mask[i]
+	}
+	return ip, last
+}
+
+// Checks that each line is an IP address or a network in CIDR format.
+func checkIPLines(lines []string) error {
+	for _, line := range lines {
+		if _, _, err := net.ParseCIDR(line); net.ParseIP(line) == nil && err != nil {
+			return errors.New("\"" + line + "\" is not an IP Address or CIDR Network")
+		}
+	}
+	return nil
+}
+
+// Calls fn with each IP address in a list of IP addresses or networks in CIDR format, without
+// holding the addresses in memory, returning the number that were skipped. Every line is
+// checked before fn is called.
+func eachIP(lines []string, opts ipExpansion, fn func(ip string)) (int, error) {
+	if err := checkIPLines(lines); err != nil {
+		return 0, err
+	}
+	skipped := 0
 	for _, line := range lines {
 		if net.ParseIP(line) != nil {
-			ipList = append(ipList, line)
-		} else if ip, network, err := net.ParseCIDR(line); err == nil {
-			for ip := ip.Mask(network.Mask); network.Contains(ip); increaseIP(ip) {
-				ipList = append(ipList, ip.String())
+			fn(line)
+			continue
+		}
+		ip, network, _ := net.ParseCIDR(line)
+		first, last := netBroadcast(network)
+		for ip := ip.Mask(network.Mask); network.Contains(ip); increaseIP(ip) {
+			if opts.skipNetBroadcast && (ip.Equal(first) || ip.Equal(last)) {
+				continue
 			}
-		} else {
-			return ipList, errors.New("\"" + line + "\" is not an IP Address or CIDR Network")
+			s := ip.String()
+			if !opts.includeReserved && bsw.IsReserved(s) {
+				skipped++
+				continue
+			}
+			fn(s)
 		}
 	}
-	return ipList, nil
+	return skipped, nil
 }
 
 // Increases an IP by a single address.
@@ -135,6 +664,244 @@ func increaseIP(ip net.IP) {
 	}
 }
 
+// sourceThrottle pauses every task from a source while the source is rate limiting. A source
+// that has been rate limited is degraded: rather than failing, its remaining tasks run one at
+// a time, at most one per second.
+type sourceThrottle struct {
+	mu      sync.Mutex
+	until   map[string]time.Time
+	limited map[string]int
+	// Called when a scraper is served a CAPTCHA or block page, such as to request a new circuit.
+	onBlock func()
+}
+
+func newSourceThrottle() *sourceThrottle {
+	return &sourceThrottle{until: make(map[string]time.Time), limited: make(map[string]int)}
+}
+
+// Blocks until a task from source may run.
+func (s *sourceThrottle) wait(source string) {
+	s.mu.Lock()
+	until := s.until[source]
+	now := time.Now()
+	if s.limited[source] > 0 {
+		if until.Before(now) {
+			until = now
+		}
+		s.until[source] = until.Add(time.Second)
+	}
+	s.mu.Unlock()
+	time.Sleep(time.Until(until))
+}
+
+// Pauses tasks from source for d. Returns true the first time source is rate limited.
+func (s *sourceThrottle) pause(source string, d time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if until := time.Now().Add(d); until.After(s.until[source]) {
+		s.until[source] = until
+	}
+	s.limited[source]++
+	return s.limited[source] == 1
+}
+
+// Returns a description of each degraded source, sorted by name.
+func (s *sourceThrottle) degraded() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sources := []string{}
+	for source, n := range s.limited {
+		sources = append(sources, fmt.Sprintf("-%s (rate limited %d times)", source, n))
+	}
+	sort.Strings(sources)
+	return sources
+}
+
+// Longest a rate limited task will wait before retrying, and how many times it is retried.
+const (
+	rateLimitMaxWait  = 10 * time.Minute
+	rateLimitAttempts = 5
+)
+
+// Runs t, retrying it up to retries times when it times out. When it is rate limited every task
+// from the same source is paused for the period the source asked for, or an increasing backoff,
+// and t is retried up to rateLimitAttempts times, paginated sources starting again from their
+// first page. Other errors, such as a missing record, will not change and are returned
+// immediately.
+func runTask(t task, retries int, delay time.Duration, throttle *sourceThrottle) (string, bsw.Results, error) {
+	throttle.wait(t.source)
+	name, results, err := t.run()
+	timeouts, limits := 0, 0
+	backoff := time.Second
+	for {
+		switch bsw.Classify(err) {
+		case bsw.ErrTimeout:
+			if timeouts >= retries {
+				return name, results, err
+			}
+			timeouts++
+			time.Sleep(delay)
+		case bsw.ErrRateLimited:
+			d := bsw.RetryAfter(err)
+			if d == 0 {
+				d = backoff
+				backoff *= 2
+			}
+			if limits >= rateLimitAttempts || d > rateLimitMaxWait {
+				return name, results, err
+			}
+			limits++
+			first := throttle.pause(t.source, d)
+			if bsw.IsBlocked(err) {
+				log.Printf("WARNING: %v: served a CAPTCHA or block page, pausing -%s tasks for %s", name, t.source, d)
+				if throttle.onBlock != nil {
+					throttle.onBlock()
+				}
+			} else if first {
+				log.Printf("%v: rate limited, pausing -%s tasks for %s and running the rest one at a time", name, t.source, d)
+			}
+			throttle.wait(t.source)
+		default:
+			return name, results, err
+		}
+		name, results, err = t.run()
+	}
+}
+
+// Returns the name of the bucket a task error is counted in for the summary.
+func failureBucket(err error) string {
+	if c := bsw.Classify(err); c != nil {
+		return c.Error()
+	}
+	return "other"
+}
+
+// Returns an error if a quota is given for an unknown source, or for a source whose requests
+// are not counted against a quota, such as one that only sends DNS queries.
+func checkQuotas(budgets map[string]bsw.Quota) error {
+	for name := range budgets {
+		known := false
+		for _, c := range sourceCapabilities {
+			known = known || c.Flag == name
+		}
+		if !known {
+			return errors.New("-quota names an unknown source " + name)
+		}
+		if !bsw.Metered(name) {
+			return errors.New("-quota can not limit " + name + ", its requests are not counted against a quota")
+		}
+	}
+	return nil
+}
+
+// Parses a time given as a date, unix timestamp, or duration before now into a unix timestamp.
+func parseTimeFence(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t.Unix(), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.Unix(), nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d).Unix(), nil
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+	return 0, errors.New("\"" + s + "\" is not a valid time")
+}
+
+// Returns the parents of hostname that are subdomains of one of domains and have not been
+// probed yet, marking them as probed.
+func delegationCandidates(hostname string, domains []string, probed map[string]bool) []string {
+	candidates := []string{}
+	name := strings.ToLower(strings.TrimRight(hostname, "."))
+	for _, d := range domains {
+		d = strings.ToLower(d)
+		if !strings.HasSuffix(name, "."+d) {
+			continue
+		}
+		labels := strings.Split(strings.TrimSuffix(name, "."+d), ".")
+		for i := 1; i < len(labels); i++ {
+			zone := strings.Join(labels[i:], ".") + "." + d
+			if !probed[zone] {
+				probed[zone] = true
+				candidates = append(candidates, zone)
+			}
+		}
+	}
+	return candidates
+}
+
+// Returns the wildcard certificate domains in results that are subdomains of one of domains and
+// have not been scanned yet, marking them as scanned.
+func wildcardCertCandidates(results bsw.Results, domains []string, scanned map[string]bool) []string {
+	candidates := []string{}
+	for _, w := range bsw.WildcardCertDomains(results) {
+		if scanned[w] {
+			continue
+		}
+		for _, d := range domains {
+			if strings.HasSuffix(w, "."+strings.ToLower(d)) {
+				scanned[w] = true
+				candidates = append(candidates, w)
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// The most addresses in a network found by -txt that is expanded with -txt-expand.
+const maxSPFExpansion = 65536
+
+// Returns the unique networks found by -txt in the SPF records of domains or their
+// subdomains, leaving out those with more than maxSPFExpansion addresses.
+func spfNetblocks(results bsw.Results, domains []string) []string {
+	netblocks := []string{}
+	seen := make(map[string]bool)
+	for _, r := range results {
+		netblock, owner := r.Meta["netblock"], r.Meta["spf_record"]
+		if r.Source != "txt" || netblock == "" || seen[netblock] {
+			continue
+		}
+		for _, d := range domains {
+			d = strings.ToLower(d)
+			if owner != d && !strings.HasSuffix(owner, "."+d) {
+				continue
+			}
+			seen[netblock] = true
+			_, network, err := net.ParseCIDR(netblock)
+			if err != nil {
+				break
+			}
+			if ones, bits := network.Mask.Size(); bits-ones > 16 {
+				log.Printf("Skipping SPF network %s of %s, it is too large to expand", netblock, owner)
+				break
+			}
+			netblocks = append(netblocks, netblock)
+			break
+		}
+	}
+	return netblocks
+}
+
+// Parses a comma separated list of ports.
+func parsePorts(list string) ([]int, error) {
+	ports := []int{}
+	for _, p := range strings.Split(list, ",") {
+		port, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil || port < 1 || port > 65535 {
+			return ports, errors.New("\"" + p + "\" is not a valid port")
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
 func readFileLines(path string) ([]string, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -149,7 +916,28 @@ func readFileLines(path string) ([]string, error) {
 	return lines, scanner.Err()
 }
 
-func readDataAndOutput(path string, ojson, ocsv, oclean bool) {
+// scanInfo describes a scan and its results. It is the data passed to a template
+// provided with -template.
+type scanInfo struct {
+	Version  string
+	Started  time.Time
+	Finished time.Time
+	Domains  []string
+	IPs      []string
+	Results  bsw.Results
+}
+
+func outputTemplate(out io.Writer, path string, info scanInfo) {
+	t, err := template.ParseFiles(path)
+	if err != nil {
+		log.Fatal("Error parsing template provided to -template " + err.Error())
+	}
+	if err := t.Execute(out, info); err != nil {
+		log.Fatal("Error executing template provided to -template " + err.Error())
+	}
+}
+
+func readDataAndOutput(path string, ojson, ocsv, oclean bool, tmpl string, redact *redactor) {
 	data, err := ioutil.ReadFile(path)
 	if err != nil {
 		log.Fatal("Error reading file provided to -parse")
@@ -158,106 +946,540 @@ func readDataAndOutput(path string, ojson, ocsv, oclean bool) {
 	if err := json.Unmarshal(data, &r); err != nil {
 		log.Fatal("Error parsing JSON from file provided to -parse")
 	}
-	output(r, ojson, ocsv, oclean)
+	if redact != nil {
+		r = redact.results(r)
+	}
+	if tmpl != "" {
+		outputTemplate(os.Stdout, tmpl, scanInfo{Version: bsw.VERSION, Results: r})
+		return
+	}
+	output(os.Stdout, r, ojson, ocsv, oclean)
 }
 
-func output(results bsw.Results, ojson, ocsv, oclean bool) {
+func output(out io.Writer, results bsw.Results, ojson, ocsv, oclean bool) {
 	switch {
 	case ojson:
 		j, _ := json.MarshalIndent(results, "", "    ")
-		fmt.Println(string(j))
+		fmt.Fprintln(out, string(j))
 	case ocsv:
 		for _, r := range results {
-			fmt.Printf("%s,%s,%s\n", r.Hostname, r.IP, r.Source)
+			fmt.Fprintf(out, "%s,%s,%s\n", r.Hostname, r.IP, r.Source)
 		}
 	case oclean:
-		cleanSet := make(map[string][]string)
-		for _, r := range results {
-			cleanSet[r.IP] = append(cleanSet[r.IP], r.Hostname)
-		}
-		for k, v := range cleanSet {
-			fmt.Printf("%s:\n", k)
-			for _, h := range v {
-				fmt.Printf("\t%s\n", h)
+		for ip, rs := range results.ByIP() {
+			if ip == "" {
+				ip = "unresolved"
+			}
+			fmt.Fprintf(out, "%s:\n", ip)
+			for _, r := range rs {
+				fmt.Fprintf(out, "\t%s\n", r.Hostname)
 			}
 		}
 	default:
-		w := tabwriter.NewWriter(os.Stdout, 0, 8, 4, ' ', 0)
-		fmt.Fprintln(w, "IP\tHostname\tSource")
+		// The FCRDNS column is only shown when results have been checked.
+		fcrdns := false
+		for _, r := range results {
+			if r.FCRDNS != "" {
+				fcrdns = true
+			}
+		}
+		w := tabwriter.NewWriter(out, 0, 8, 4, ' ', 0)
+		if fcrdns {
+			fmt.Fprintln(w, "IP\tHostname\tPort\tSource\tFCRDNS")
+		} else {
+			fmt.Fprintln(w, "IP\tHostname\tPort\tSource")
+		}
 		for _, r := range results {
-			fmt.Fprintf(w, "%s\t%s\t%s\n", r.IP, r.Hostname, r.Source)
+			var port string
+			if r.Port > 0 {
+				port = fmt.Sprintf("%d/%s", r.Port, r.Protocol)
+			}
+			if fcrdns {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.IP, r.Hostname, port, r.Source, r.FCRDNS)
+			} else {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.IP, r.Hostname, port, r.Source)
+			}
 		}
 		w.Flush()
 	}
 }
 
+// Writes results in the same formats as output, one at a time as each provides them. The
+// table is aligned in blocks of rows rather than as a whole.
+func outputStream(out io.Writer, each func(fn func(bsw.Result) error) error, ojson, ocsv, oclean bool) error {
+	switch {
+	case ojson:
+		n := 0
+		err := each(func(r bsw.Result) error {
+			j, _ := json.MarshalIndent(r, "    ", "    ")
+			sep := ",\n    "
+			if n == 0 {
+				sep = "[\n    "
+			}
+			n++
+			_, err := fmt.Fprint(out, sep+string(j))
+			return err
+		})
+		if n == 0 {
+			fmt.Fprintln(out, "[]")
+		} else {
+			fmt.Fprintln(out, "\n]")
+		}
+		return err
+	case ocsv:
+		return each(func(r bsw.Result) error {
+			_, err := fmt.Fprintf(out, "%s,%s,%s\n", r.Hostname, r.IP, r.Source)
+			return err
+		})
+	case oclean:
+		last := "-"
+		return each(func(r bsw.Result) error {
+			if r.IP != last {
+				last = r.IP
+				ip := r.IP
+				if ip == "" {
+					ip = "unresolved"
+				}
+				fmt.Fprintf(out, "%s:\n", ip)
+			}
+			_, err := fmt.Fprintf(out, "\t%s\n", r.Hostname)
+			return err
+		})
+	}
+	// The FCRDNS column is only shown when results have been checked.
+	fcrdns := false
+	if err := each(func(r bsw.Result) error {
+		if r.FCRDNS != "" {
+			fcrdns = true
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 4, ' ', 0)
+	if fcrdns {
+		fmt.Fprintln(w, "IP\tHostname\tPort\tSource\tFCRDNS")
+	} else {
+		fmt.Fprintln(w, "IP\tHostname\tPort\tSource")
+	}
+	rows := 0
+	err := each(func(r bsw.Result) error {
+		var port string
+		if r.Port > 0 {
+			port = fmt.Sprintf("%d/%s", r.Port, r.Protocol)
+		}
+		if fcrdns {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.IP, r.Hostname, port, r.Source, r.FCRDNS)
+		} else {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.IP, r.Hostname, port, r.Source)
+		}
+		rows++
+		if rows%streamTableRows == 0 {
+			return w.Flush()
+		}
+		return nil
+	})
+	w.Flush()
+	return err
+}
+
+// Rows of a streamed table that are aligned together.
+const streamTableRows = 10000
+
+// Intrusiveness levels that gate which active tasks may run.
+const (
+	levelSafe = iota
+	levelNormal
+	levelIntrusive
+)
+
+var levelNames = []string{"safe", "normal", "intrusive"}
+
+// fcrdnsMode is the value of -fcrdns. Given without a value it is "strict".
+type fcrdnsMode string
+
+func (m *fcrdnsMode) String() string { return string(*m) }
+
+func (m *fcrdnsMode) Set(s string) error {
+	switch s {
+	case "true":
+		*m = "strict"
+	case "false":
+		*m = "off"
+	case "strict", "annotate", "off":
+		*m = fcrdnsMode(s)
+	default:
+		return errors.New("must be one of strict, annotate, or off")
+	}
+	return nil
+}
+
+func (m *fcrdnsMode) IsBoolFlag() bool { return true }
+
+// optionalString is an option that may be given alone or with a value, such as an optional
+// API key.
+type optionalString struct {
+	set   bool
+	value string
+}
+
+func (o *optionalString) String() string { return o.value }
+
+func (o *optionalString) Set(s string) error {
+	o.set = s != "false"
+	if s == "true" || s == "false" {
+		s = ""
+	}
+	o.value = s
+	return nil
+}
+
+func (o *optionalString) IsBoolFlag() bool { return true }
+
+// A timing template bundles the options that control the speed of a scan.
+type timing struct {
+	concurrency int
+	retries     int
+	delay       int
+	timeout     int64
+}
+
+// Timing templates selected with -T1 through -T5. Timeouts and delays are in milliseconds.
+var timings = []timing{
+	{concurrency: 1, retries: 3, delay: 5000, timeout: 5000},
+	{concurrency: 5, retries: 2, delay: 1000, timeout: 3000},
+	{concurrency: 100, retries: 0, delay: 0, timeout: 600},
+	{concurrency: 250, retries: 1, delay: 0, timeout: 400},
+	{concurrency: 500, retries: 0, delay: 0, timeout: 250},
+}
+
+// A -scan-id label, kept short enough that the wildcard check name is a valid DNS label.
+var scanIDRegex = regexp.MustCompile(`^[A-Za-z0-9-]{1,32}$`)
+
 const domainReg = `^\.?[a-z\d]+(?:(?:[a-z\d]*)|(?:[a-z\d\-]*[a-z\d]))(?:\.[a-z\d]+(?:(?:[a-z\d]*)|(?:[a-z\d\-]*[a-z\d])))*$`
 
-type task func() (string, bsw.Results, error)
+// A task is a function wrapper that returns the name of the task, a slice of results, and a
+// possible error. Source is the option that enabled the task, if a task fails authentication
+// the remaining tasks from the same source are skipped.
+type task struct {
+	source string
+	target string
+	run    func() (string, bsw.Results, error)
+}
 type empty struct{}
 
+// Most results held in memory with -low-memory.
+const lowMemoryResults = 100000
+
+// job is a task along with its position in the order tasks were queued.
+type job struct {
+	task
+	id int
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "wordlist" {
+		runWordlistCommand(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "run" {
+		runScanCommand(os.Args[2:])
+		return
+	}
+
 	// Command line options. For usage information see the
 	// usage variable above.
 	var (
-		flVersion        = flag.Bool("version", false, "")
-		flTimeout        = flag.Int64("timeout", 600, "")
-		flConcurrency    = flag.Int("concurrency", 100, "")
-		flDebug          = flag.Bool("debug", false, "")
-		flValidate       = flag.Bool("validate", false, "")
-		flipv6           = flag.Bool("ipv6", false, "")
-		flServerAddr     = flag.String("server", "8.8.8.8", "")
-		flIPFile         = flag.String("input", "", "")
-		flParse          = flag.String("parse", "", "")
-		flReverse        = flag.Bool("reverse", false, "")
-		flHeader         = flag.Bool("headers", false, "")
-		flTLS            = flag.Bool("tls", false, "")
-		flAXFR           = flag.Bool("axfr", false, "")
-		flMX             = flag.Bool("mx", false, "")
-		flNS             = flag.Bool("ns", false, "")
-		flViewDNSInfo    = flag.Bool("viewdns-html", false, "")
-		flViewDNSInfoAPI = flag.String("viewdns", "", "")
-		flRobtex         = flag.Bool("robtex", false, "")
-		flLogonTube      = flag.Bool("logontube", false, "")
-		flSRV            = flag.Bool("srv", false, "")
-		flBing           = flag.String("bing", "", "")
-		flShodan         = flag.String("shodan", "", "")
-		flBingHTML       = flag.Bool("bing-html", false, "")
-		flYandex         = flag.String("yandex", "", "")
-		flDomain         = flag.String("domain", "", "")
-		flDictFile       = flag.String("dictionary", "", "")
-		flFcrdns         = flag.Bool("fcrdns", false, "")
-		flClean          = flag.Bool("clean", false, "")
-		flCsv            = flag.Bool("csv", false, "")
-		flJSON           = flag.Bool("json", false, "")
+		flVersion          = flag.Bool("version", false, "")
+		flTimeout          = flag.Int64("timeout", 600, "")
+		flConcurrency      = flag.Int("concurrency", 100, "")
+		flMaxFD            = flag.Int("max-fd", 0, "")
+		flRetries          = flag.Int("retries", 0, "")
+		flQuota            = flag.String("quota", "", "")
+		flQuotaLedger      = flag.String("quota-ledger", "", "")
+		flDelay            = flag.Int("delay", 0, "")
+		flDebug            = flag.Bool("debug", false, "")
+		flProfile          = flag.String("profile", "", "")
+		flConfig           = flag.String("config", defaultConfigPath(), "")
+		flMachine          = flag.Bool("machine", false, "")
+		flLowMemory        = flag.Bool("low-memory", false, "")
+		flIncludeReserved  = flag.Bool("include-reserved", false, "")
+		flSkipNetBroadcast = flag.Bool("skip-net-broadcast", false, "")
+		flSpillThreshold   = flag.Int("spill-threshold", 1000000, "")
+		flDryRun           = flag.Bool("dry-run", false, "")
+		flPlanJSON         = flag.Bool("plan-json", false, "")
+		flExecutePlan      = flag.String("execute-plan", "", "")
+		flValidate         = flag.Bool("validate", false, "")
+		flLevel            = flag.String("level", "normal", "")
+		flipv6             = flag.Bool("ipv6", false, "")
+		flServerAddr       = flag.String("server", "8.8.8.8", "")
+		flResolvers        = flag.String("resolvers", "", "")
+		fl0x20             = flag.Bool("0x20", false, "")
+		flEDNSClient       = flag.String("edns-client", "", "")
+		flRandomPorts      = flag.Bool("random-ports", false, "")
+		flDNSSEC           = flag.Bool("dnssec", false, "")
+		flPreferAuth       = flag.Bool("prefer-authoritative", false, "")
+		flIPFile           = flag.String("input", "", "")
+		flASN              = flag.Bool("asn", false, "")
+		flOnlyASN          = flag.String("only-asn", "", "")
+		flSkipASN          = flag.String("skip-asn", "", "")
+		flParse            = flag.String("parse", "", "")
+		flReverse          = flag.Bool("reverse", false, "")
+		flReverseConfirm   = flag.Bool("reverse-confirm", false, "")
+		flSkipBogons       = flag.Bool("skip-bogons", false, "")
+		flHeader           = flag.Bool("headers", false, "")
+		flTLS              = flag.Bool("tls", false, "")
+		flTLSCert          = flag.String("tls-cert", "", "")
+		flTLSKey           = flag.String("tls-key", "", "")
+		flTLSMin           = flag.String("tls-min", "", "")
+		flTLSMax           = flag.String("tls-max", "", "")
+		flTLSCiphers       = flag.String("tls-ciphers", "", "")
+		flSMTP             = flag.Bool("smtp", false, "")
+		flVerifyPorts      = flag.String("verify-ports", "", "")
+		flHTTPPorts        = flag.String("http-ports", "", "")
+		flNTP              = flag.Bool("ntp", false, "")
+		flTFTP             = flag.Bool("tftp", false, "")
+		flIntrusive        = flag.Bool("intrusive", false, "")
+		flAXFR             = flag.Bool("axfr", false, "")
+		flNSECWalk         = flag.Bool("nsec-walk", false, "")
+		flNSEC3Hashes      = flag.String("nsec3-hashes", "", "")
+		flNSEC3Format      = flag.String("nsec3-format", "hashcat", "")
+		flNSEC3Cracked     = flag.String("nsec3-cracked", "", "")
+		flMX               = flag.Bool("mx", false, "")
+		flTXT              = flag.Bool("txt", false, "")
+		flCAA              = flag.Bool("caa", false, "")
+		flSOA              = flag.Bool("soa", false, "")
+		flTXTExpand        = flag.Bool("txt-expand", false, "")
+		flDMARC            = flag.Bool("dmarc", false, "")
+		flTyposquat        = flag.Bool("typosquat", false, "")
+		flNS               = flag.Bool("ns", false, "")
+		flViewDNSInfo      = flag.Bool("viewdns-html", false, "")
+		flViewDNSInfoAPI   = flag.String("viewdns", "", "")
+		flReverseMX        = flag.Bool("reverse-mx", false, "")
+		flReverseNS        = flag.Bool("reverse-ns", false, "")
+		flRobtex           = flag.Bool("robtex", false, "")
+		flRobtexHTML       = flag.Bool("robtex-html", false, "")
+		flReverseIPAPI     = flag.Bool("reverseip-api", false, "")
+		flSkipHealthCheck  = flag.Bool("skip-health-check", false, "")
+		flCrtSh            = flag.Bool("crtsh", false, "")
+		flFacebookCT       = flag.String("fb-ct", "", "")
+		flSecurityTrails   = flag.String("securitytrails", "", "")
+		flVirusTotal       = flag.String("virustotal", "", "")
+		flDNSDB            = flag.String("dnsdb", "", "")
+		flDNSDBAfter       = flag.String("dnsdb-after", "", "")
+		flDNSDBBefore      = flag.String("dnsdb-before", "", "")
+		flCIRCL            = flag.String("circl", "", "")
+		flOTX              = flag.Bool("otx", false, "")
+		flHackerTarget     = flag.Bool("hackertarget", false, "")
+		flThreatCrowd      = flag.Bool("threatcrowd", false, "")
+		flThreatMiner      = flag.Bool("threatminer", false, "")
+		flBinaryEdge       = flag.String("binaryedge", "", "")
+		flZoomEye          = flag.String("zoomeye", "", "")
+		flFOFA             = flag.String("fofa", "", "")
+		flOnyphe           = flag.String("onyphe", "", "")
+		flNetlas           = flag.String("netlas", "", "")
+		flHunterIO         = flag.String("hunterio", "", "")
+		flWhoisXML         = flag.String("whoisxml", "", "")
+		flIPInfo           = flag.String("ipinfo", "", "")
+		flReverseWhois     = flag.String("reverse-whois", "", "")
+		flWhoisActive      = flag.Bool("reverse-whois-active", false, "")
+		flWayback          = flag.Bool("wayback", false, "")
+		flAnubis           = flag.Bool("anubis", false, "")
+		flChaos            = flag.String("chaos", "", "")
+		flGitHub           = flag.String("github", "", "")
+		flResolve          = flag.Bool("resolve", false, "")
+		flFDNS             = flag.String("fdns", "", "")
+		flRDNSFile         = flag.String("rdns-file", "", "")
+		flDelegations      = flag.Bool("delegations", false, "")
+		flSRV              = flag.Bool("srv", false, "")
+		flBing             = flag.String("bing", "", "")
+		flBingLegacy       = flag.Bool("bing-legacy", false, "")
+		flShodan           = flag.String("shodan", "", "")
+		flBingHTML         = flag.Bool("bing-html", false, "")
+		flDuckDuckGo       = flag.Bool("duckduckgo", false, "")
+		flNetcraft         = flag.Bool("netcraft", false, "")
+		flBaidu            = flag.Bool("baidu", false, "")
+		flYahoo            = flag.Bool("yahoo", false, "")
+		flHE               = flag.Bool("he", false, "")
+		flYandex           = flag.String("yandex", "", "")
+		flDomain           = flag.String("domain", "", "")
+		flDictFile         = flag.String("dictionary", "", "")
+		flClean            = flag.Bool("clean", false, "")
+		flAnomalies        = flag.Bool("anomalies", false, "")
+		flParked           = flag.Bool("parked", false, "")
+		flSkipParked       = flag.Bool("skip-parked", false, "")
+		flParkedList       = flag.String("parked-list", "", "")
+		flSummary          = flag.Bool("summary", false, "")
+		flGeo              = flag.Bool("geo", false, "")
+		flCsv              = flag.Bool("csv", false, "")
+		flJSON             = flag.Bool("json", false, "")
+		flTemplate         = flag.String("template", "", "")
+		flTheHive          = flag.String("thehive", "", "")
+		flTheHiveKey       = flag.String("thehive-key", "", "")
+		flUpload           = flag.String("upload", "", "")
+		flIndicators       = flag.String("indicators", "", "")
+		flOpenIOC          = flag.String("openioc", "", "")
+		flTorControl       = flag.String("tor-control", "", "")
 	)
+	flFcrdns := fcrdnsMode("off")
+	flag.Var(&flFcrdns, "fcrdns", "")
+	var flCertSpotter optionalString
+	flag.Var(&flCertSpotter, "certspotter", "")
+	var flBufferOver optionalString
+	flag.Var(&flBufferOver, "bufferover", "")
+	var flScanID optionalString
+	flag.Var(&flScanID, "scan-id", "")
+	var flDKIM optionalString
+	flag.Var(&flDKIM, "dkim", "")
+	var flLeakIX optionalString
+	flag.Var(&flLeakIX, "leakix", "")
+	var flRedact optionalString
+	flag.Var(&flRedact, "redact", "")
+	flag.Bool("logontube", false, "")
+	flTimings := make([]*bool, len(timings))
+	for i := range timings {
+		flTimings[i] = flag.Bool(fmt.Sprintf("T%d", i+1), false, "")
+	}
+	// The capabilities subcommand is handled once every option is defined, so that it can
+	// list them.
+	if len(os.Args) > 1 && os.Args[1] == "capabilities" {
+		if err := writeCapabilities(os.Stdout, flag.CommandLine); err != nil {
+			log.Fatal(err.Error())
+		}
+		return
+	}
 	flag.Usage = func() { fmt.Print(usage) }
 	flag.Parse()
+	if err := applyScanSecrets(); err != nil {
+		log.Fatal(err.Error())
+	}
 
-	if *flVersion {
-		fmt.Println("blacksheepwall version ", bsw.VERSION)
-		os.Exit(0)
+	// Extractors in the config file are applied whenever it exists, with or without -profile.
+	if *flConfig != "" {
+		c, err := loadConfig(*flConfig)
+		if err != nil && !os.IsNotExist(err) {
+			log.Fatal(err.Error())
+		}
+		if err := applyExtractors(c); err != nil {
+			log.Fatal(err.Error())
+		}
 	}
 
-	if *flParse != "" {
-		readDataAndOutput(*flParse, *flJSON, *flCsv, *flClean)
-		os.Exit(0)
+	if *flProfile != "" {
+		p, err := loadProfile(*flConfig, *flProfile)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		if err := applyProfile(p); err != nil {
+			log.Fatal(err.Error())
+		}
 	}
 
-	// Modify timeout to Milliseconds for function calls
-	if *flTimeout != 600 {
-		*flTimeout = *flTimeout * 1000
+	var execPlan *plan
+	if *flExecutePlan != "" {
+		p, err := loadPlan(*flExecutePlan)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		if err := applyPlan(p); err != nil {
+			log.Fatal(err.Error())
+		}
+		execPlan = p
 	}
 
-	// Holds all IP addresses for testing.
-	ipAddrList := []string{}
+	// The options are recorded for -dry-run before any are modified.
+	// Sources that have gone away are replaced or disabled before anything is planned.
+	// Health checks make requests, so they are skipped with -dry-run.
+	healthFailures := make(map[string]error)
+	if !*flDryRun && !*flSkipHealthCheck {
+		healthFailures = checkSourceHealth()
+	}
+	applyDeprecations(healthFailures)
+
+	var dryRunPlan *plan
+	if *flDryRun {
+		dryRunPlan = newPlan()
+	}
+
+	if *flVersion {
+		fmt.Println("blacksheepwall version ", bsw.VERSION)
+		os.Exit(0)
+	}
+
+	var redact *redactor
+	if flRedact.set {
+		redact = newRedactor(flRedact.value)
+	}
+
+	if *flParse != "" {
+		readDataAndOutput(*flParse, *flJSON, *flCsv, *flClean, *flTemplate, redact)
+		os.Exit(0)
+	}
+
+	started := time.Now()
+
+	// Modify timeout to Milliseconds for function calls
+	if *flTimeout != 600 {
+		*flTimeout = *flTimeout * 1000
+	}
+
+	// Apply a timing template to any of its options that were not explicitly provided.
+	for i, t := range timings {
+		if !*flTimings[i] {
+			continue
+		}
+		set := make(map[string]bool)
+		flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if !set["concurrency"] {
+			*flConcurrency = t.concurrency
+		}
+		if !set["retries"] {
+			*flRetries = t.retries
+		}
+		if !set["delay"] {
+			*flDelay = t.delay
+		}
+		if !set["timeout"] {
+			*flTimeout = t.timeout
+		}
+	}
+
+	// Keep the concurrent tasks within the limit of open files, past which dials fail.
+	if *flMaxFD < 0 {
+		log.Fatal("-max-fd must be a positive number")
+	}
+	maxFD, limited := fdLimit()
+	if *flMaxFD > 0 {
+		maxFD, limited = uint64(*flMaxFD), true
+	}
+	if allowed := fdConcurrency(maxFD); limited && *flConcurrency > 0 && uint64(*flConcurrency) > allowed {
+		if allowed == 0 {
+			log.Fatalf("The limit of %d open files is too low to run any tasks, raise it with ulimit -n", maxFD)
+		}
+		log.Printf("WARNING: lowering -concurrency from %d to %d to stay within the limit of %d open files, raise it with ulimit -n or override it with -max-fd", *flConcurrency, allowed, maxFD)
+		*flConcurrency = int(allowed)
+	}
+
+	// Holds all IP addresses for testing.
+	ipAddrList := []string{}
 
 	// Used to hold a ip or CIDR range passed as fl.Arg(0).
 
 	// Verify that some sort of work load was given in commands.
-	if *flIPFile == "" && *flDomain == "" && len(flag.Args()) < 1 {
+	if *flIPFile == "" && *flDomain == "" && len(flag.Args()) < 1 && execPlan == nil && *flReverseWhois == "" && *flNSEC3Cracked == "" {
 		log.Fatal("You didn't provide any work for me to do")
 	}
+	var uploadDest *objectStore
+	if *flUpload != "" {
+		store, err := parseObjectStore(*flUpload)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		uploadDest = store
+	}
+	if *flTheHive != "" && *flTheHiveKey == "" {
+		log.Fatal("TheHive alerts require an API key set with -thehive-key")
+	}
 	if *flYandex != "" && *flDomain == "" {
 		log.Fatal("Yandex API requires domain set with -domain")
 	}
@@ -267,9 +1489,166 @@ func main() {
 	if *flDomain == "" && *flSRV == true {
 		log.Fatal("SRV lookup requires domain set with -domain")
 	}
-	if *flDomain != "" && *flYandex == "" && *flDictFile == "" && !*flSRV && !*flLogonTube && *flShodan == "" && *flBing == "" && !*flBingHTML && !*flAXFR && !*flNS && !*flMX {
+
+	// Verify that every requested active task is allowed at the requested level.
+	level := -1
+	for l, name := range levelNames {
+		if name == *flLevel {
+			level = l
+		}
+	}
+	if level < 0 {
+		log.Fatal("-level must be one of safe, normal, or intrusive")
+	}
+	if *flIntrusive {
+		level = levelIntrusive
+	}
+	// The level each of these requires is listed in sourceCapabilities.
+	activeTasks := map[string]bool{
+		"srv":          *flSRV,
+		"delegations":  *flDelegations,
+		"axfr":         *flAXFR,
+		"nsec-walk":    *flNSECWalk,
+		"nsec3-hashes": *flNSEC3Hashes != "",
+		"headers":      *flHeader,
+		"tls":          *flTLS,
+		"smtp":         *flSMTP,
+		"verify-ports": *flVerifyPorts != "",
+		"ntp":          *flNTP,
+		"tftp":         *flTFTP,
+	}
+	if err := checkLevel(activeTasks, level); err != nil {
+		log.Fatal(err)
+	}
+	if *flDomain != "" && *flYandex == "" && *flDictFile == "" && !*flSRV && !*flReverseIPAPI && !*flRobtex && *flShodan == "" && *flBing == "" && !*flBingHTML && !*flDuckDuckGo && !*flNetcraft && !*flBaidu && !*flYahoo && !*flHE && !*flAXFR && !*flNSECWalk && *flNSEC3Hashes == "" && !*flNS && !*flMX && !*flCAA && !*flSOA && !*flTXT && !*flDMARC && !flDKIM.set && !*flTyposquat && !*flCrtSh && *flSecurityTrails == "" && *flVirusTotal == "" && *flDNSDB == "" && *flCIRCL == "" && !*flOTX && !*flHackerTarget && !*flWayback && *flFDNS == "" && *flBinaryEdge == "" && *flZoomEye == "" && *flFOFA == "" && *flOnyphe == "" && *flNetlas == "" && *flHunterIO == "" && *flWhoisXML == "" && !flLeakIX.set && !*flThreatCrowd && !*flThreatMiner && !flCertSpotter.set && *flFacebookCT == "" && !flBufferOver.set && !*flAnubis && *flChaos == "" && *flGitHub == "" {
 		log.Fatal("-domain provided but no methods provided that use it")
 	}
+	if (*flReverseMX || *flReverseNS) && *flViewDNSInfoAPI == "" {
+		log.Fatal("-reverse-mx and -reverse-ns require -viewdns")
+	}
+	if *flNSEC3Format != "hashcat" && *flNSEC3Format != "john" {
+		log.Fatal("-nsec3-format must be hashcat or john")
+	}
+	if *flNSEC3Hashes != "" && *flDomain == "" {
+		log.Fatal("-nsec3-hashes requires domain set with -domain")
+	}
+	if *flTXTExpand && !*flTXT {
+		log.Fatal("-txt-expand requires -txt")
+	}
+	if (*flReverseMX && !*flMX) || (*flReverseNS && !*flNS) {
+		log.Fatal("-reverse-mx requires -mx and -reverse-ns requires -ns")
+	}
+	if *flCIRCL != "" && !strings.Contains(*flCIRCL, ":") {
+		log.Fatal("-circl must be in the form user:pass")
+	}
+	if *flFOFA != "" && !strings.Contains(*flFOFA, ":") {
+		log.Fatal("-fofa must be in the form email:key")
+	}
+
+	if *flResolvers != "" {
+		lines, err := readFileLines(*flResolvers)
+		if err != nil {
+			log.Fatal("Error reading " + *flResolvers + " " + err.Error())
+		}
+		rules, err := bsw.ParseResolverRules(lines)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		bsw.SetResolverRules(rules)
+	}
+	if *flParkedList != "" {
+		lines, err := readFileLines(*flParkedList)
+		if err != nil {
+			log.Fatal("Error reading " + *flParkedList + " " + err.Error())
+		}
+		if err := bsw.AddParked(lines); err != nil {
+			log.Fatal(err.Error())
+		}
+	}
+	bsw.SetQueryHardening(*fl0x20, *flRandomPorts)
+	bsw.SetDNSSEC(*flDNSSEC)
+	if *flQuotaLedger != "" && *flQuota == "" {
+		log.Fatal("-quota-ledger requires -quota")
+	}
+	if *flQuota != "" {
+		budgets, err := bsw.ParseQuotas(*flQuota)
+		if err != nil {
+			log.Fatal("Error parsing -quota " + err.Error())
+		}
+		if err := checkQuotas(budgets); err != nil {
+			log.Fatal(err)
+		}
+		bsw.SetQuotaLedger(*flQuotaLedger, budgets)
+	}
+	if flScanID.set {
+		if flScanID.value == "" {
+			b := make([]byte, 4)
+			rand.Read(b)
+			flScanID.value = hex.EncodeToString(b)
+		}
+		if !scanIDRegex.MatchString(flScanID.value) {
+			log.Fatal("-scan-id must be up to 32 letters, digits, or hyphens")
+		}
+		log.Printf("Scan ID: %s", flScanID.value)
+		if *flEDNSClient == "" {
+			*flEDNSClient = "blacksheepwall/" + flScanID.value
+		}
+	}
+	bsw.SetAttribution(flScanID.value, *flEDNSClient)
+	tlsConfig, err := buildTLSConfig(*flTLSCert, *flTLSKey, *flTLSMin, *flTLSMax, *flTLSCiphers)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	bsw.SetTLSConfig(tlsConfig)
+
+	if *flReverseWhois != "" && *flWhoisXML == "" {
+		log.Fatal("-reverse-whois requires -whoisxml")
+	}
+	if *flWhoisActive && *flReverseWhois == "" {
+		log.Fatal("-reverse-whois-active requires -reverse-whois")
+	}
+	if *flPlanJSON && !*flDryRun {
+		log.Fatal("-plan-json requires -dry-run")
+	}
+
+	var httpPorts []int
+	if *flHTTPPorts != "" {
+		if !*flHeader && !*flTLS {
+			log.Fatal("-http-ports requires -headers or -tls")
+		}
+		ports, err := parsePorts(*flHTTPPorts)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		httpPorts = ports
+	}
+
+	var dkimSelectors []string
+	if flDKIM.value != "" {
+		dkimSelectors, err = loadDictionary(flDKIM.value)
+		if err != nil {
+			log.Fatal("Error reading " + flDKIM.value + " " + err.Error())
+		}
+	}
+
+	var verifyPorts []int
+	if *flVerifyPorts != "" {
+		ports, err := parsePorts(*flVerifyPorts)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		verifyPorts = ports
+	}
+
+	after, err := parseTimeFence(*flDNSDBAfter)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	before, err := parseTimeFence(*flDNSDBBefore)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	dnsdbWindow := bsw.DNSDBWindow{After: after, Before: before}
 
 	// Build list of domains.
 	domains := []string{}
@@ -285,117 +1664,334 @@ func main() {
 		}
 	}
 
-	// Get first argument that is not an option and turn it into a list of IPs.
+	// Get first argument that is not an option, and the lines of the file given as -input,
+	// each of which is an IP or network.
+	ipLines := []string{}
 	if len(flag.Args()) > 0 {
-		flNetwork := flag.Arg(0)
-		list, err := linesToIPList([]string{flNetwork})
-		if err != nil {
-			log.Fatal(err.Error())
-		}
-		ipAddrList = append(ipAddrList, list...)
+		ipLines = append(ipLines, flag.Arg(0))
 	}
-
-	// If file given as -input, read lines and turn each possible IP or network into
-	// a list of IPs. Appends list to ipAddrList. Will fail fatally if line in file
-	// is not a valid IP or CIDR range.
 	if *flIPFile != "" {
 		lines, err := readFileLines(*flIPFile)
 		if err != nil {
 			log.Fatal("Error reading " + *flIPFile + " " + err.Error())
 		}
-		list, err := linesToIPList(lines)
+		ipLines = append(ipLines, lines...)
+	}
+
+	// Turn the networks into a list of IPs. Will fail fatally if a line is not a valid IP or
+	// CIDR range. With -low-memory the IPs are instead generated as their tasks are queued,
+	// unless an option needs the whole list.
+	streamIPs := *flLowMemory && !*flASN && *flOnlyASN == "" && *flSkipASN == "" && *flShodan == "" &&
+		*flRDNSFile == "" && !*flDryRun && *flTemplate == "" && execPlan == nil
+	expansion := ipExpansion{includeReserved: *flIncludeReserved, skipNetBroadcast: *flSkipNetBroadcast}
+	logSkipped := func(skipped int) {
+		if skipped > 0 {
+			log.Printf("Skipped %d reserved addresses, use -include-reserved to scan them", skipped)
+		}
+	}
+	if streamIPs {
+		if err := checkIPLines(ipLines); err != nil {
+			log.Fatal(err.Error())
+		}
+	} else {
+		list, skipped, err := linesToIPList(ipLines, expansion)
 		if err != nil {
 			log.Fatal(err.Error())
 		}
+		logSkipped(skipped)
 		ipAddrList = append(ipAddrList, list...)
 	}
 
-	// tracker: Chanel uses an empty struct to track when all goroutines in the pool
-	//          have completed as well as a single call from the gatherer.
-	//
-	// tasks:   Chanel used in the goroutine pool to manage incoming work. A task is
-	//          a function wrapper that returns a slice of results and a possible error.
-	//
-	// res:     When each task is called in the pool, it will send valid results to
-	//          the res channel.
-	tracker := make(chan empty)
-	tasks := make(chan task, *flConcurrency)
-	res := make(chan bsw.Results, *flConcurrency)
-	// Use a map that acts like a set to store only unique results.
-	resMap := make(map[bsw.Result]bool)
-
-	// Start up *flConcurrency amount of goroutines.
-	log.Printf("Spreading tasks across %d goroutines", *flConcurrency)
-	for i := 0; i < *flConcurrency; i++ {
+	if execPlan != nil {
+		domains, ipAddrList = execPlan.Domains, execPlan.IPs
+	}
+
+	// Group the ips by announcing ASN so that the scope can be reviewed and narrowed.
+	var asnByIP map[string]bsw.ASNInfo
+	if (*flASN || *flOnlyASN != "" || *flSkipASN != "") && len(ipAddrList) > 0 {
+		log.Printf("Looking up ASNs for %d ips", len(ipAddrList))
+		asnByIP = lookupASNs(ipAddrList)
+		ipAddrList = filterASNs(ipAddrList, asnByIP, parseASNs(*flOnlyASN), parseASNs(*flSkipASN))
+		writeASNSummary(os.Stderr, ipAddrList, asnByIP)
+		if len(ipAddrList) == 0 && len(domains) == 0 {
+			log.Fatal("No ips remain in scope after ASN selection")
+		}
+	}
+
+	// In -machine mode events are written to stdout in place of the usual output.
+	var events *eventWriter
+	progressDone := make(chan empty)
+	if *flMachine {
+		events = newEventWriter(os.Stdout, redact)
+		go events.progress(5*time.Second, progressDone)
+	}
+
+	// Count task failures by category, and track sources whose remaining tasks are
+	// skipped after they fail authentication.
+	var failuresMu sync.Mutex
+	throttle := newSourceThrottle()
+	if *flTorControl != "" {
+		var torMu sync.Mutex
+		var lastCircuit time.Time
+		throttle.onBlock = func() {
+			torMu.Lock()
+			defer torMu.Unlock()
+			// Tor ignores requests for a new circuit made within ten seconds of the last.
+			if time.Since(lastCircuit) < 10*time.Second {
+				return
+			}
+			lastCircuit = time.Now()
+			if err := newTorCircuit(*flTorControl); err != nil {
+				log.Printf("Error requesting a new Tor circuit: %s", err.Error())
+				return
+			}
+			log.Println("Requested a new Tor circuit")
+		}
+	}
+	failures := make(map[string]int)
+	aborted := make(map[string]bool)
+
+	// Store only unique results, writing them to disk once more than -spill-threshold are
+	// held, or lowMemoryResults with -low-memory.
+	threshold := *flSpillThreshold
+	if *flLowMemory && (threshold == 0 || threshold > lowMemoryResults) {
+		threshold = lowMemoryResults
+	}
+	store := newResultStore(threshold)
+	defer store.close()
+	var seen sightings
+	if *flIndicators != "" || *flOpenIOC != "" {
+		seen = make(sightings)
+	}
+	addResult := func(r bsw.Result) {
+		added, err := store.add(r)
+		if err != nil {
+			log.Fatal("Error writing results to disk " + err.Error())
+		}
+		if seen != nil {
+			seen.add(r)
+		}
+		if !added {
+			return
+		}
+		if events != nil {
+			events.result(r)
+		}
+	}
+	// Create a results slice from the unique results in store.
+	gathered := func() bsw.Results {
+		results, err := store.results()
+		if err != nil {
+			log.Fatal("Error reading results from disk " + err.Error())
+		}
+		return results
+	}
+	// Calls fn with each unique result. Once results have been written to disk they are read
+	// back one at a time, so the passes that follow up on a few of them keep only those.
+	eachGathered := func(fn func(r bsw.Result)) {
+		err := store.each(func(r bsw.Result) error {
+			fn(r)
+			return nil
+		})
+		if err != nil {
+			log.Fatal("Error reading results from disk " + err.Error())
+		}
+	}
+
+	// startTasks starts a pool of *flConcurrency goroutines and a result gatherer. It returns
+	// the channel that tasks should be sent on and a function that waits for every task to
+	// complete and its results to be gathered into store.
+	// With -execute-plan a marker is written as each task in the plan finishes.
+	planned := make(map[int]planTask)
+	queuedPlanTasks := 0
+	if execPlan != nil {
+		for _, t := range execPlan.Tasks {
+			planned[t.ID] = t
+		}
+	}
+	markPlanTask := func(id int, err error) {
+		switch {
+		case execPlan == nil:
+		case events != nil:
+			events.planTask(id, err)
+		case err != nil:
+			log.Printf("Plan task %d failed: %s", id, err.Error())
+		default:
+			log.Printf("Plan task %d completed", id)
+		}
+	}
+
+	startTasks := func() (chan<- task, func()) {
+		// With -dry-run tasks are recorded in the order they are queued rather than run.
+		if *flDryRun {
+			tasks := make(chan task)
+			done := make(chan empty)
+			go func() {
+				for t := range tasks {
+					dryRunPlan.Tasks = append(dryRunPlan.Tasks, planTask{ID: len(dryRunPlan.Tasks), Source: t.source, Target: t.target})
+				}
+				done <- empty{}
+			}()
+			return tasks, func() {
+				close(tasks)
+				<-done
+			}
+		}
+		// tracker: Chanel uses an empty struct to track when all goroutines in the pool
+		//          have completed as well as a single call from the gatherer.
+		//
+		// tasks:   Chanel used to queue work. A task is a function wrapper that returns a
+		//          slice of results and a possible error.
+		//
+		// jobs:    Chanel used in the goroutine pool to manage incoming work. Each task is
+		//          numbered in the order it was queued, and with -execute-plan only the
+		//          tasks in the plan are sent.
+		//
+		// res:     When each task is called in the pool, it will send valid results to
+		//          the res channel.
+		tracker := make(chan empty)
+		buffer := *flConcurrency
+		if *flLowMemory {
+			buffer = 1
+		}
+		tasks := make(chan task, buffer)
+		jobs := make(chan job, buffer)
+		res := make(chan bsw.Results, buffer)
+
 		go func() {
-			var c = 0
-			for def := range tasks {
-				task, result, err := def()
-				if *flDebug == false {
-					if m := c % 2; m == 0 {
-						c = 3
-						os.Stderr.WriteString("\rWorking \\")
-					} else {
-						c = 2
-						os.Stderr.WriteString("\rWorking /")
+			id := 0
+			for t := range tasks {
+				if execPlan != nil {
+					p, ok := planned[id]
+					if ok && (p.Source != t.source || p.Target != t.target) {
+						log.Fatalf("Task %d of the plan is %s %s, but %s %s was queued, the options do not match the plan", id, p.Source, p.Target, t.source, t.target)
 					}
-				}
-				if err != nil && *flDebug {
-					log.Printf("%v: %v", task, err.Error())
-				}
-				if err == nil {
-					if *flDebug == true && len(result) > 0 {
-						log.Printf("%v: %v %v: task completed successfully\n", task, result[0].Hostname, result[0].IP)
+					if !ok {
+						id++
+						continue
 					}
-					res <- result
+					queuedPlanTasks++
 				}
+				jobs <- job{t, id}
+				id++
 			}
-			tracker <- empty{}
+			close(jobs)
 		}()
-	}
 
-	// Ingest incoming results.
-	go func() {
-		for result := range res {
-			if len(result) < 1 {
-				continue
-			}
-			if *flFcrdns {
-				for _, r := range result {
-					ip, err := bsw.LookupName(r.Hostname, *flServerAddr)
-					if err == nil && len(ip) > 0 {
-						resMap[bsw.Result{Source: "fcrdns", IP: ip, Hostname: r.Hostname}] = true
-					} else {
-						cfqdn, err := bsw.LookupCname(r.Hostname, *flServerAddr)
-						if err == nil && len(cfqdn) > 0 {
-							ip, err = bsw.LookupName(cfqdn, *flServerAddr)
-							if err == nil && len(ip) > 0 {
-								resMap[bsw.Result{Source: "fcrdns", IP: ip, Hostname: r.Hostname}] = true
-							}
+		// Start up *flConcurrency amount of goroutines.
+		for i := 0; i < *flConcurrency; i++ {
+			go func() {
+				var c = 0
+				for t := range jobs {
+					failuresMu.Lock()
+					skip := aborted[t.source]
+					if skip {
+						failures["skipped"]++
+					}
+					failuresMu.Unlock()
+					if skip {
+						markPlanTask(t.id, errors.New("skipped"))
+						continue
+					}
+					time.Sleep(time.Duration(*flDelay) * time.Millisecond)
+					task, result, err := runTask(t.task, *flRetries, time.Duration(*flDelay)*time.Millisecond, throttle)
+					markPlanTask(t.id, err)
+					if err != nil {
+						failuresMu.Lock()
+						failures[failureBucket(err)]++
+						if bsw.Classify(err) == bsw.ErrAuth && !aborted[t.source] {
+							aborted[t.source] = true
+							log.Printf("%v: %v: skipping remaining -%s tasks", task, err.Error(), t.source)
 						}
+						failuresMu.Unlock()
 					}
-					ip, err = bsw.LookupName6(r.Hostname, *flServerAddr)
-					if err == nil && len(ip) > 0 {
-						resMap[bsw.Result{Source: "fcrdns", IP: ip, Hostname: r.Hostname}] = true
+					if events != nil {
+						events.task(task, err)
+					} else if *flDebug == false {
+						if m := c % 2; m == 0 {
+							c = 3
+							os.Stderr.WriteString("\rWorking \\")
+						} else {
+							c = 2
+							os.Stderr.WriteString("\rWorking /")
+						}
+					}
+					if err != nil && *flDebug {
+						log.Printf("%v: %v", task, err.Error())
+					}
+					if err == nil {
+						if *flDebug == true && len(result) > 0 {
+							log.Printf("%v: %v %v: task completed successfully\n", task, result[0].Hostname, result[0].IP)
+						}
+						res <- result
 					}
 				}
-			} else {
+				tracker <- empty{}
+			}()
+		}
+
+		// Ingest incoming results.
+		go func() {
+			for result := range res {
+				if len(result) < 1 {
+					continue
+				}
 				for _, r := range result {
 					if *flValidate {
 						if ok, err := regexp.Match(domainReg, []byte(r.Hostname)); err != nil || !ok {
 							continue
 						}
 					}
-					resMap[r] = true
+					if flFcrdns != "off" && r.FCRDNS == "" {
+						r.FCRDNS = bsw.Confirm(r, *flServerAddr)
+						if flFcrdns == "strict" && r.FCRDNS != bsw.FCRDNSConfirmed {
+							continue
+						}
+					}
+					addResult(r)
 				}
 			}
+			tracker <- empty{}
+		}()
+
+		// Close the tasks channel after all jobs have completed and for each
+		// goroutine in the pool receive an empty message from  tracker.
+		return tasks, func() {
+			close(tasks)
+			for i := 0; i < *flConcurrency; i++ {
+				<-tracker
+			}
+			close(res)
+			// Receive and empty message from the result gatherer.
+			<-tracker
 		}
-		tracker <- empty{}
-	}()
+	}
+
+	if *flPreferAuth && !*flDryRun {
+		for _, d := range domains {
+			servers := []string{}
+			hostnames, err := bsw.LookupNS(d, *flServerAddr)
+			if err != nil {
+				log.Printf("Error finding the name servers of %s: %s", d, err.Error())
+				continue
+			}
+			for _, h := range hostnames {
+				if ip, err := bsw.LookupName(h, *flServerAddr); err == nil && ip != "" {
+					servers = append(servers, ip)
+				}
+			}
+			if len(servers) > 0 {
+				bsw.PreferAuthoritative(d, servers)
+			}
+		}
+	}
+
+	log.Printf("Spreading tasks across %d goroutines", *flConcurrency)
+	tasks, wait := startTasks()
 
-	// Bing has two possible search paths. We need to find which one is valid.
+	// The legacy Bing API has two possible search paths. We need to find which one is valid.
 	var bingPath string
-	if *flBing != "" {
+	if *flBing != "" && *flBingLegacy {
 		p, err := bsw.FindBingSearchPath(*flBing)
 		if err != nil {
 			log.Fatal(err.Error())
@@ -403,115 +1999,768 @@ func main() {
 		bingPath = p
 	}
 
+	if *flReverseWhois != "" {
+		tasks <- task{"reverse-whois", *flReverseWhois, func() (string, bsw.Results, error) {
+			return bsw.ReverseWhois(*flReverseWhois, *flWhoisXML)
+		}}
+	}
+	if *flNSEC3Cracked != "" {
+		lines, err := readFileLines(*flNSEC3Cracked)
+		if err != nil {
+			log.Fatal("Error reading " + *flNSEC3Cracked + " " + err.Error())
+		}
+		tasks <- task{"nsec3-cracked", "", func() (string, bsw.Results, error) { return bsw.NSEC3Cracked(lines, *flServerAddr) }}
+	}
+	if *flRDNSFile != "" && len(ipAddrList) > 0 {
+		tasks <- task{"rdns-file", "", func() (string, bsw.Results, error) { return bsw.RDNS(*flRDNSFile, ipAddrList) }}
+	}
 	if *flShodan != "" && len(ipAddrList) > 0 {
-		tasks <- func() (string, bsw.Results, error) { return bsw.ShodanAPIReverse(ipAddrList, *flShodan) }
+		tasks <- task{"shodan", "", func() (string, bsw.Results, error) { return bsw.ShodanAPIReverse(ipAddrList, *flShodan) }}
 	}
 
 	// IP based functionality should be added to the pool here.
-	for _, h := range ipAddrList {
-		host := h
-		if *flReverse {
-			tasks <- func() (string, bsw.Results, error) { return bsw.Reverse(host, *flServerAddr) }
+
+	// queueIP sends every enabled IP based task for host to tasks.
+	queueIP := func(tasks chan<- task, host string) {
+		if *flReverse && !(*flSkipBogons && bsw.IsBogon(host)) {
+			tasks <- task{"reverse", host, func() (string, bsw.Results, error) { return bsw.Reverse(host, *flServerAddr, *flReverseConfirm) }}
 		}
-		if *flTLS {
-			tasks <- func() (string, bsw.Results, error) { return bsw.TLS(host, *flTimeout) }
+		// With both -tls and -headers a single TLS connection is shared by the two tasks.
+		if *flTLS && *flHeader {
+			tasks <- task{"tls", host, func() (string, bsw.Results, error) { return bsw.HeadersTLS(host, *flTimeout) }}
+		} else if *flTLS {
+			tasks <- task{"tls", host, func() (string, bsw.Results, error) { return bsw.TLS(host, *flTimeout) }}
+		}
+		for _, p := range httpPorts {
+			port := p
+			source := "headers"
+			if *flTLS {
+				source = "tls"
+			}
+			tasks <- task{source, net.JoinHostPort(host, strconv.Itoa(port)), func() (string, bsw.Results, error) {
+				return bsw.ProbeHTTP(host, port, *flTLS, *flHeader, *flTimeout)
+			}}
+		}
+		if *flSMTP {
+			tasks <- task{"smtp", host, func() (string, bsw.Results, error) { return bsw.SMTP(host, *flTimeout) }}
+		}
+		if *flNTP {
+			tasks <- task{"ntp", host, func() (string, bsw.Results, error) { return bsw.NTP(host, *flTimeout) }}
 		}
 		if *flViewDNSInfo {
-			tasks <- func() (string, bsw.Results, error) { return bsw.ViewDNSInfo(host) }
+			tasks <- task{"viewdns-html", host, func() (string, bsw.Results, error) { return bsw.ViewDNSInfo(host) }}
 		}
 		if *flViewDNSInfoAPI != "" {
-			tasks <- func() (string, bsw.Results, error) { return bsw.ViewDNSInfoAPI(host, *flViewDNSInfoAPI) }
+			tasks <- task{"viewdns", host, func() (string, bsw.Results, error) { return bsw.ViewDNSInfoAPI(host, *flViewDNSInfoAPI) }}
 		}
 		if *flRobtex {
-			tasks <- func() (string, bsw.Results, error) { return bsw.Robtex(host) }
+			tasks <- task{"robtex", host, func() (string, bsw.Results, error) { return bsw.Robtex(host) }}
+		}
+		if *flRobtexHTML {
+			tasks <- task{"robtex-html", host, func() (string, bsw.Results, error) { return bsw.RobtexHTML(host) }}
 		}
-		if *flLogonTube {
-			tasks <- func() (string, bsw.Results, error) { return bsw.LogonTubeAPI(host) }
+		if *flReverseIPAPI {
+			tasks <- task{"reverseip-api", host, func() (string, bsw.Results, error) { return bsw.ReverseIPAPI(host) }}
 		}
 		if *flBingHTML {
-			tasks <- func() (string, bsw.Results, error) { return bsw.BingIP(host) }
+			tasks <- task{"bing-html", host, func() (string, bsw.Results, error) { return bsw.BingIP(host) }}
+		}
+		if *flYahoo {
+			tasks <- task{"yahoo", host, func() (string, bsw.Results, error) { return bsw.YahooIP(host) }}
+		}
+		if *flHE {
+			tasks <- task{"he", host, func() (string, bsw.Results, error) { return bsw.HE(host) }}
 		}
 		if *flBing != "" && bingPath != "" {
-			tasks <- func() (string, bsw.Results, error) { return bsw.BingAPIIP(host, *flBing, bingPath) }
+			tasks <- task{"bing", host, func() (string, bsw.Results, error) { return bsw.BingAPIIP(host, *flBing, bingPath) }}
+		} else if *flBing != "" {
+			tasks <- task{"bing", host, func() (string, bsw.Results, error) { return bsw.BingV7IP(host, *flBing) }}
+		}
+		if *flHeader && !*flTLS {
+			tasks <- task{"headers", host, func() (string, bsw.Results, error) { return bsw.Headers(host, *flTimeout) }}
+		}
+		if *flSecurityTrails != "" {
+			tasks <- task{"securitytrails", host, func() (string, bsw.Results, error) { return bsw.SecurityTrailsIP(host, *flSecurityTrails) }}
+		}
+		if *flVirusTotal != "" {
+			tasks <- task{"virustotal", host, func() (string, bsw.Results, error) { return bsw.VirusTotalIP(host, *flVirusTotal) }}
+		}
+		if *flDNSDB != "" {
+			tasks <- task{"dnsdb", host, func() (string, bsw.Results, error) { return bsw.DNSDBIP(host, *flDNSDB, dnsdbWindow) }}
+		}
+		if *flCIRCL != "" {
+			tasks <- task{"circl", host, func() (string, bsw.Results, error) { return bsw.CIRCLIP(host, *flCIRCL) }}
+		}
+		if *flOTX {
+			tasks <- task{"otx", host, func() (string, bsw.Results, error) { return bsw.OTXIP(host) }}
+		}
+		if *flHackerTarget {
+			tasks <- task{"hackertarget", host, func() (string, bsw.Results, error) { return bsw.HackerTargetIP(host) }}
+		}
+		if *flThreatCrowd {
+			tasks <- task{"threatcrowd", host, func() (string, bsw.Results, error) { return bsw.ThreatCrowdIP(host) }}
+		}
+		if *flThreatMiner {
+			tasks <- task{"threatminer", host, func() (string, bsw.Results, error) { return bsw.ThreatMinerIP(host) }}
+		}
+		if *flBinaryEdge != "" {
+			tasks <- task{"binaryedge", host, func() (string, bsw.Results, error) { return bsw.BinaryEdgeIP(host, *flBinaryEdge) }}
+		}
+		if *flZoomEye != "" {
+			tasks <- task{"zoomeye", host, func() (string, bsw.Results, error) { return bsw.ZoomEyeIP(host, *flZoomEye) }}
 		}
-		if *flHeader {
-			tasks <- func() (string, bsw.Results, error) { return bsw.Headers(host, *flTimeout) }
+		if *flFOFA != "" {
+			tasks <- task{"fofa", host, func() (string, bsw.Results, error) { return bsw.FOFAIP(host, *flFOFA) }}
+		}
+		if *flOnyphe != "" {
+			tasks <- task{"onyphe", host, func() (string, bsw.Results, error) { return bsw.OnypheIP(host, *flOnyphe) }}
+		}
+		if flLeakIX.set {
+			tasks <- task{"leakix", host, func() (string, bsw.Results, error) { return bsw.LeakIXIP(host, flLeakIX.value) }}
+		}
+		if *flWhoisXML != "" {
+			tasks <- task{"whoisxml", host, func() (string, bsw.Results, error) { return bsw.WhoisXMLReverseIP(host, *flWhoisXML) }}
+		}
+		if *flIPInfo != "" {
+			tasks <- task{"ipinfo", host, func() (string, bsw.Results, error) { return bsw.IPInfo(host, *flIPInfo) }}
+		}
+		if *flNetlas != "" {
+			tasks <- task{"netlas", host, func() (string, bsw.Results, error) { return bsw.NetlasIP(host, *flNetlas) }}
+		}
+	}
+	if streamIPs {
+		skipped, _ := eachIP(ipLines, expansion, func(ip string) { queueIP(tasks, ip) })
+		logSkipped(skipped)
+	} else {
+		for _, ip := range ipAddrList {
+			queueIP(tasks, ip)
 		}
 	}
 
 	// Domain based functions will likely require separate blocks and should be added below.
 
-	// Subdomain dictionary guessing.
-	for _, d := range domains {
-		domain := d
-		if *flDictFile != "" {
-			nameList, err := readFileLines(*flDictFile)
+	// Lookalikes are generated once for each registrable domain.
+	typosquatted := make(map[string]bool)
+	// The NSEC3 chains collected for -nsec3-hashes, written after the scan.
+	var nsec3Mu sync.Mutex
+	nsec3Zones := []bsw.NSEC3Zone{}
+
+	// queueDomain sends every enabled domain based task for domain to tasks. Without active the
+	// tasks that guess names or query the domain's own servers are skipped, for domains that
+	// were not given as targets.
+	queueDomain := func(tasks chan<- task, domain string, active bool) {
+		// Subdomain dictionary guessing.
+		if *flDictFile != "" && active {
+			nameList, err := loadDictionary(*flDictFile)
 			if err != nil {
 				log.Fatal("Error reading " + *flDictFile + " " + err.Error())
 			}
-			// Get an IP for a possible wildcard domain and use it as a blacklist.
-			blacklist := bsw.GetWildCard(domain, *flServerAddr)
-			var blacklist6 string
-			if *flipv6 {
-				blacklist6 = bsw.GetWildCard6(domain, *flServerAddr)
+			// Get an IP for a possible wildcard domain and use it as a blacklist. The queries
+			// are skipped with -dry-run, which never runs the tasks.
+			var blacklist, blacklist6 string
+			if !*flDryRun {
+				blacklist = bsw.GetWildCard(domain, *flServerAddr)
+				if *flipv6 {
+					blacklist6 = bsw.GetWildCard6(domain, *flServerAddr)
+				}
 			}
 			for _, n := range nameList {
 				sub := n
-				tasks <- func() (string, bsw.Results, error) { return bsw.Dictionary(domain, sub, blacklist, *flServerAddr) }
+				tasks <- task{"dictionary", sub + "." + domain, func() (string, bsw.Results, error) { return bsw.Dictionary(domain, sub, blacklist, *flServerAddr) }}
 				if *flipv6 {
-					tasks <- func() (string, bsw.Results, error) { return bsw.Dictionary6(domain, sub, blacklist6, *flServerAddr) }
+					tasks <- task{"dictionary", sub + "." + domain, func() (string, bsw.Results, error) { return bsw.Dictionary6(domain, sub, blacklist6, *flServerAddr) }}
 				}
 			}
 		}
 
-		if *flSRV != false {
-			tasks <- func() (string, bsw.Results, error) { return bsw.SRV(domain, *flServerAddr) }
+		if *flSRV != false && active {
+			tasks <- task{"srv", domain, func() (string, bsw.Results, error) { return bsw.SRV(domain, *flServerAddr) }}
 		}
 		if *flYandex != "" {
-			tasks <- func() (string, bsw.Results, error) { return bsw.YandexAPI(domain, *flYandex, *flServerAddr) }
+			tasks <- task{"yandex", domain, func() (string, bsw.Results, error) { return bsw.YandexAPI(domain, *flYandex, *flServerAddr) }}
+		}
+		if *flReverseIPAPI {
+			tasks <- task{"reverseip-api", domain, func() (string, bsw.Results, error) { return bsw.ReverseIPAPI(domain) }}
+		}
+		if *flRobtex {
+			tasks <- task{"robtex", domain, func() (string, bsw.Results, error) { return bsw.RobtexDomain(domain) }}
+		}
+		if *flCrtSh {
+			tasks <- task{"crtsh", domain, func() (string, bsw.Results, error) { return bsw.CrtSh(domain, *flServerAddr) }}
+		}
+		if flCertSpotter.set {
+			tasks <- task{"certspotter", domain, func() (string, bsw.Results, error) {
+				return bsw.CertSpotter(domain, flCertSpotter.value, *flServerAddr)
+			}}
+		}
+		if *flFacebookCT != "" {
+			tasks <- task{"fb-ct", domain, func() (string, bsw.Results, error) { return bsw.FacebookCT(domain, *flFacebookCT, *flServerAddr) }}
+		}
+		if *flSecurityTrails != "" {
+			tasks <- task{"securitytrails", domain, func() (string, bsw.Results, error) {
+				return bsw.SecurityTrailsDomain(domain, *flSecurityTrails, *flServerAddr)
+			}}
+		}
+		if *flVirusTotal != "" {
+			tasks <- task{"virustotal", domain, func() (string, bsw.Results, error) {
+				return bsw.VirusTotalDomain(domain, *flVirusTotal, *flServerAddr)
+			}}
+		}
+		if *flDNSDB != "" {
+			tasks <- task{"dnsdb", domain, func() (string, bsw.Results, error) { return bsw.DNSDBDomain(domain, *flDNSDB, dnsdbWindow) }}
+		}
+		if *flCIRCL != "" {
+			tasks <- task{"circl", domain, func() (string, bsw.Results, error) { return bsw.CIRCLDomain(domain, *flCIRCL) }}
+		}
+		if *flOTX {
+			tasks <- task{"otx", domain, func() (string, bsw.Results, error) { return bsw.OTXDomain(domain) }}
+		}
+		if *flHackerTarget {
+			tasks <- task{"hackertarget", domain, func() (string, bsw.Results, error) { return bsw.HackerTargetDomain(domain) }}
+		}
+		if flBufferOver.set {
+			tasks <- task{"bufferover", domain, func() (string, bsw.Results, error) { return bsw.BufferOver(domain, flBufferOver.value) }}
 		}
-		if *flLogonTube {
-			tasks <- func() (string, bsw.Results, error) { return bsw.LogonTubeAPI(domain) }
+		if *flThreatCrowd {
+			tasks <- task{"threatcrowd", domain, func() (string, bsw.Results, error) { return bsw.ThreatCrowdDomain(domain, *flServerAddr) }}
+		}
+		if *flThreatMiner {
+			tasks <- task{"threatminer", domain, func() (string, bsw.Results, error) { return bsw.ThreatMinerDomain(domain, *flServerAddr) }}
+		}
+		if *flBinaryEdge != "" {
+			tasks <- task{"binaryedge", domain, func() (string, bsw.Results, error) {
+				return bsw.BinaryEdgeDomain(domain, *flBinaryEdge, *flServerAddr)
+			}}
+		}
+		if *flZoomEye != "" {
+			tasks <- task{"zoomeye", domain, func() (string, bsw.Results, error) { return bsw.ZoomEyeDomain(domain, *flZoomEye) }}
+		}
+		if *flFOFA != "" {
+			tasks <- task{"fofa", domain, func() (string, bsw.Results, error) { return bsw.FOFADomain(domain, *flFOFA) }}
+		}
+		if *flOnyphe != "" {
+			tasks <- task{"onyphe", domain, func() (string, bsw.Results, error) { return bsw.OnypheDomain(domain, *flOnyphe) }}
+		}
+		if *flNetlas != "" {
+			tasks <- task{"netlas", domain, func() (string, bsw.Results, error) { return bsw.NetlasDomain(domain, *flNetlas) }}
+		}
+		if flLeakIX.set {
+			tasks <- task{"leakix", domain, func() (string, bsw.Results, error) { return bsw.LeakIXDomain(domain, flLeakIX.value) }}
+		}
+		if *flWhoisXML != "" {
+			tasks <- task{"whoisxml", domain, func() (string, bsw.Results, error) {
+				return bsw.WhoisXMLSubdomains(domain, *flWhoisXML, *flServerAddr)
+			}}
+		}
+		if *flHunterIO != "" {
+			tasks <- task{"hunterio", domain, func() (string, bsw.Results, error) { return bsw.HunterIO(domain, *flHunterIO, *flServerAddr) }}
+		}
+		if *flWayback {
+			tasks <- task{"wayback", domain, func() (string, bsw.Results, error) { return bsw.Wayback(domain, *flResolve, *flServerAddr) }}
+		}
+		if *flAnubis {
+			tasks <- task{"anubis", domain, func() (string, bsw.Results, error) { return bsw.Anubis(domain, *flResolve, *flServerAddr) }}
+		}
+		if *flChaos != "" {
+			tasks <- task{"chaos", domain, func() (string, bsw.Results, error) { return bsw.Chaos(domain, *flChaos, *flServerAddr) }}
+		}
+		if *flGitHub != "" {
+			tasks <- task{"github", domain, func() (string, bsw.Results, error) { return bsw.GitHub(domain, *flGitHub, *flServerAddr) }}
 		}
 		if *flShodan != "" {
-			tasks <- func() (string, bsw.Results, error) { return bsw.ShodanAPIHostSearch(domain, *flShodan) }
+			tasks <- task{"shodan", domain, func() (string, bsw.Results, error) { return bsw.ShodanAPIHostSearch(domain, *flShodan) }}
 		}
 		if *flBing != "" && bingPath != "" {
-			tasks <- func() (string, bsw.Results, error) {
+			tasks <- task{"bing", domain, func() (string, bsw.Results, error) {
 				return bsw.BingAPIDomain(domain, *flBing, bingPath, *flServerAddr)
-			}
+			}}
+		} else if *flBing != "" {
+			tasks <- task{"bing", domain, func() (string, bsw.Results, error) { return bsw.BingV7Domain(domain, *flBing, *flServerAddr) }}
 		}
 		if *flBingHTML {
-			tasks <- func() (string, bsw.Results, error) { return bsw.BingDomain(domain, *flServerAddr) }
+			tasks <- task{"bing-html", domain, func() (string, bsw.Results, error) { return bsw.BingDomain(domain, *flServerAddr) }}
+		}
+		if *flBaidu {
+			tasks <- task{"baidu", domain, func() (string, bsw.Results, error) { return bsw.BaiduDomain(domain, *flServerAddr) }}
+		}
+		if *flYahoo {
+			tasks <- task{"yahoo", domain, func() (string, bsw.Results, error) { return bsw.YahooDomain(domain, *flServerAddr) }}
+		}
+		if *flHE {
+			tasks <- task{"he", domain, func() (string, bsw.Results, error) { return bsw.HEDomain(domain, *flServerAddr) }}
+		}
+		if *flNetcraft {
+			tasks <- task{"netcraft", domain, func() (string, bsw.Results, error) { return bsw.Netcraft(domain, *flServerAddr) }}
 		}
-		if *flAXFR {
-			tasks <- func() (string, bsw.Results, error) { return bsw.AXFR(domain, *flServerAddr) }
+		if *flDuckDuckGo {
+			tasks <- task{"duckduckgo", domain, func() (string, bsw.Results, error) { return bsw.DuckDuckGo(domain, *flServerAddr) }}
+		}
+		if *flAXFR && active {
+			tasks <- task{"axfr", domain, func() (string, bsw.Results, error) { return bsw.AXFR(domain, *flServerAddr) }}
+		}
+		if *flNSECWalk && active {
+			tasks <- task{"nsec-walk", domain, func() (string, bsw.Results, error) { return bsw.NSECWalk(domain, *flServerAddr) }}
+		}
+		if *flNSEC3Hashes != "" && active {
+			tasks <- task{"nsec3-hashes", domain, func() (string, bsw.Results, error) {
+				zone, err := bsw.NSEC3Collect(domain, *flServerAddr)
+				if len(zone.Chain) > 0 {
+					nsec3Mu.Lock()
+					nsec3Zones = append(nsec3Zones, zone)
+					nsec3Mu.Unlock()
+					if !zone.Complete {
+						log.Printf("Collected %d NSEC3 hashes for %s before reaching the limit", len(zone.Chain), domain)
+					}
+				}
+				return "nsec3", bsw.Results{}, err
+			}}
 		}
 		if *flNS {
-			tasks <- func() (string, bsw.Results, error) { return bsw.NS(domain, *flServerAddr) }
+			tasks <- task{"ns", domain, func() (string, bsw.Results, error) { return bsw.NS(domain, *flServerAddr) }}
 		}
 		if *flMX {
-			tasks <- func() (string, bsw.Results, error) { return bsw.MX(domain, *flServerAddr) }
+			tasks <- task{"mx", domain, func() (string, bsw.Results, error) { return bsw.MX(domain, *flServerAddr) }}
 		}
+		if *flCAA {
+			tasks <- task{"caa", domain, func() (string, bsw.Results, error) { return bsw.CAA(domain, *flServerAddr) }}
+		}
+		if *flSOA {
+			tasks <- task{"soa", domain, func() (string, bsw.Results, error) { return bsw.SOA(domain, *flServerAddr) }}
+		}
+		if *flTXT {
+			tasks <- task{"txt", domain, func() (string, bsw.Results, error) { return bsw.TXT(domain, *flServerAddr) }}
+		}
+		if *flDMARC {
+			tasks <- task{"dmarc", domain, func() (string, bsw.Results, error) { return bsw.DMARC(domain, *flServerAddr) }}
+		}
+		if flDKIM.set && active {
+			tasks <- task{"dkim", domain, func() (string, bsw.Results, error) { return bsw.DKIM(domain, dkimSelectors, *flServerAddr) }}
+		}
+		if *flTyposquat {
+			apex, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(domain))
+			if err == nil && !typosquatted[apex] {
+				typosquatted[apex] = true
+				lookalikes, _ := bsw.LookalikeDomains(apex)
+				for _, l := range lookalikes {
+					lookalike := l
+					tasks <- task{"typosquat", lookalike.Domain, func() (string, bsw.Results, error) { return bsw.Typosquat(apex, lookalike, *flServerAddr) }}
+				}
+			}
+		}
+	}
+	for _, d := range domains {
+		queueDomain(tasks, d, true)
+	}
+	if *flFDNS != "" && len(domains) > 0 {
+		tasks <- task{"fdns", "", func() (string, bsw.Results, error) { return bsw.FDNS(*flFDNS, domains) }}
 	}
 
-	// Close the tasks channel after all jobs have completed and for each
-	// goroutine in the pool receive an empty message from  tracker.
-	close(tasks)
-	for i := 0; i < *flConcurrency; i++ {
-		<-tracker
+	wait()
+
+	if *flDryRun {
+		dryRunPlan.Domains, dryRunPlan.IPs, dryRunPlan.Total = domains, ipAddrList, len(dryRunPlan.Tasks)
+		writePlan(os.Stdout, dryRunPlan, *flPlanJSON)
+		return
+	}
+	// Only the tasks in a plan are run, none that follow from their results.
+	followUp := execPlan == nil
+	if execPlan != nil && queuedPlanTasks < len(execPlan.Tasks) {
+		log.Printf("%d tasks in the plan were not queued, the options do not match the plan", len(execPlan.Tasks)-queuedPlanTasks)
+	}
+
+	// Networks in the SPF records of the domains are scanned as ips, leaving out those of
+	// included third party records.
+	if followUp && *flTXTExpand {
+		txt := bsw.Results{}
+		eachGathered(func(r bsw.Result) {
+			if r.Source == "txt" && r.Meta["netblock"] != "" {
+				txt = append(txt, r)
+			}
+		})
+		netblocks := spfNetblocks(txt, domains)
+		if len(netblocks) > 0 {
+			scanned := make(map[string]bool)
+			for _, ip := range ipAddrList {
+				scanned[ip] = true
+			}
+			tasks, wait = startTasks()
+			n := 0
+			skipped, _ := eachIP(netblocks, expansion, func(ip string) {
+				if !scanned[ip] {
+					scanned[ip] = true
+					n++
+					queueIP(tasks, ip)
+				}
+			})
+			log.Printf("Scanning %d addresses from %d SPF networks", n, len(netblocks))
+			logSkipped(skipped)
+			wait()
+		}
+	}
+
+	// Zone transfers and NS lookups (-delegations) can reveal delegated subzones. These are
+	// scanned with the same domain based tasks, with their queries sent to one of their
+	// authoritative servers, in further rounds until no new subzones are found. Domains of
+	// wildcard certificate names within the scope of a domain are scanned the same way.
+	scannedZones := make(map[string]bool)
+	probedZones := make(map[string]bool)
+	for _, d := range domains {
+		scannedZones[strings.ToLower(d)] = true
+	}
+	for followUp {
+		if *flDelegations {
+			candidates := []string{}
+			eachGathered(func(r bsw.Result) {
+				candidates = append(candidates, delegationCandidates(r.Hostname, domains, probedZones)...)
+			})
+			if len(candidates) > 0 {
+				tasks, wait = startTasks()
+				for _, c := range candidates {
+					zone := c
+					tasks <- task{"delegations", zone, func() (string, bsw.Results, error) { return bsw.Delegation(zone, *flServerAddr) }}
+				}
+				wait()
+			}
+		}
+		subzones := []string{}
+		authoritative := make(map[string]string)
+		wildcards := bsw.Results{}
+		whois := []string{}
+		eachGathered(func(r bsw.Result) {
+			if strings.HasPrefix(r.Hostname, "*.") {
+				wildcards = append(wildcards, r)
+			}
+			if r.Source == "reverse-whois" {
+				whois = append(whois, r.Hostname)
+			}
+			z := r.Meta["delegation"]
+			if z == "" {
+				return
+			}
+			if ip := net.ParseIP(r.IP); ip != nil && ip.To4() != nil && authoritative[z] == "" {
+				authoritative[z] = r.IP
+			}
+			if !scannedZones[z] {
+				scannedZones[z] = true
+				subzones = append(subzones, z)
+			}
+		})
+		certDomains := wildcardCertCandidates(wildcards, domains, scannedZones)
+		whoisDomains := []string{}
+		for _, d := range whois {
+			if !scannedZones[d] {
+				scannedZones[d] = true
+				whoisDomains = append(whoisDomains, d)
+			}
+		}
+		if len(subzones) == 0 && len(certDomains) == 0 && len(whoisDomains) == 0 {
+			break
+		}
+		if len(subzones) > 0 {
+			log.Printf("Scanning %d delegated subzones", len(subzones))
+		}
+		if len(certDomains) > 0 {
+			log.Printf("Scanning %d domains of wildcard certificates", len(certDomains))
+		}
+		if len(whoisDomains) > 0 {
+			log.Printf("Scanning %d domains found by reverse WHOIS", len(whoisDomains))
+		}
+		for _, z := range subzones {
+			if server := authoritative[z]; server != "" {
+				bsw.AddResolverRule(bsw.ResolverRule{Pattern: z, Server: server})
+			}
+		}
+		subzones = append(subzones, certDomains...)
+		domains = append(domains, subzones...)
+		domains = append(domains, whoisDomains...)
+		tasks, wait = startTasks()
+		for _, z := range subzones {
+			queueDomain(tasks, z, true)
+		}
+		// Domains found by reverse WHOIS may belong to anyone, so they are only probed on request.
+		for _, d := range whoisDomains {
+			queueDomain(tasks, d, *flWhoisActive)
+		}
+		wait()
+	}
+
+	// Pivot on the mail and name servers found for each domain to find other domains that
+	// share the same infrastructure.
+	if followUp && (*flReverseMX || *flReverseNS) {
+		tasks, wait = startTasks()
+		seen := make(map[string]bool)
+		eachGathered(func(r bsw.Result) {
+			server := r.Hostname
+			if seen[r.Source+server] {
+				return
+			}
+			seen[r.Source+server] = true
+			switch {
+			case r.Source == "mx" && *flReverseMX:
+				tasks <- task{"reverse-mx", server, func() (string, bsw.Results, error) {
+					return bsw.ViewDNSReverseMX(server, *flViewDNSInfoAPI, *flServerAddr)
+				}}
+			case r.Source == "ns" && *flReverseNS:
+				tasks <- task{"reverse-ns", server, func() (string, bsw.Results, error) {
+					return bsw.ViewDNSReverseNS(server, *flViewDNSInfoAPI, *flServerAddr)
+				}}
+			}
+		})
+		wait()
+	}
+
+	// TFTP is run after all other tasks, requesting configurations named after
+	// the hostnames that have been identified for each IP.
+	if followUp && *flTFTP {
+		hostnamesByIP := make(map[string][]string)
+		eachGathered(func(r bsw.Result) {
+			if r.IP != "" {
+				hostnamesByIP[r.IP] = append(hostnamesByIP[r.IP], r.Hostname)
+			}
+		})
+		tasks, wait = startTasks()
+		for i, hostnames := range hostnamesByIP {
+			ip := i
+			files := bsw.TFTPFilenames(hostnames)
+			tasks <- task{"tftp", ip, func() (string, bsw.Results, error) { return bsw.TFTP(ip, files, *flServerAddr, *flTimeout) }}
+		}
+		wait()
+	}
+
+	// Port verification is run last so that every identified hostname is checked.
+	if followUp && len(verifyPorts) > 0 {
+		pairs := bsw.Results{}
+		seen := make(map[bsw.ResultKey]bool)
+		eachGathered(func(r bsw.Result) {
+			p := bsw.Result{IP: r.IP, Hostname: r.Hostname}
+			if r.IP != "" && r.Hostname != "" && !seen[p.Key()] {
+				seen[p.Key()] = true
+				pairs = append(pairs, p)
+			}
+		})
+		tasks, wait = startTasks()
+		for _, p := range pairs {
+			pair := p
+			tasks <- task{"verify-ports", pair.IP, func() (string, bsw.Results, error) {
+				return bsw.VerifyPorts(pair.IP, pair.Hostname, verifyPorts, *flTimeout)
+			}}
+		}
+		wait()
+	}
+	if events == nil {
+		os.Stderr.WriteString("\r")
 	}
-	close(res)
-	// Receive and empty message from the result gatherer.
-	<-tracker
-	os.Stderr.WriteString("\r")
 	log.Println("All tasks completed")
+	if len(failures) > 0 {
+		buckets := []string{}
+		for b, n := range failures {
+			buckets = append(buckets, fmt.Sprintf("%d %s", n, b))
+		}
+		sort.Strings(buckets)
+		log.Printf("Task failures: %s", strings.Join(buckets, ", "))
+	}
+	if degraded := throttle.degraded(); len(degraded) > 0 {
+		log.Printf("Degraded sources: %s", strings.Join(degraded, ", "))
+	}
+	if *flDebug {
+		for _, line := range bsw.FormatSourceMetrics(bsw.SourceMetrics()) {
+			log.Printf("Source %s", line)
+		}
+	}
 
-	// Create a results slice from the unique set in resMap. Allows for sorting.
-	results := bsw.Results{}
-	for k := range resMap {
-		results = append(results, k)
+	// When results have been written to disk and nothing needs them all at once, they are
+	// written out as they are read back rather than held in memory.
+	if store.spilled() && !*flAnomalies && !*flParked && !*flSkipParked && !*flSummary && !*flGeo && *flTemplate == "" && uploadDest == nil && *flTheHive == "" && *flNSEC3Hashes == "" && seen == nil {
+		var out io.Writer = os.Stdout
+		if events != nil {
+			out = ioutil.Discard
+		}
+		wildcards := bsw.Results{}
+		store.each(func(r bsw.Result) error {
+			if strings.HasPrefix(r.Hostname, "*.") {
+				wildcards = append(wildcards, r)
+			}
+			return nil
+		})
+		wildcardDomains := bsw.WildcardCertDomains(wildcards)
+		err := outputStream(out, func(fn func(bsw.Result) error) error {
+			return store.each(func(r bsw.Result) error {
+				r = withASN(bsw.MarkWildcardCert(r, wildcardDomains), asnByIP)
+				if redact != nil {
+					r = redact.result(r)
+				}
+				return fn(r)
+			})
+		}, *flJSON, *flCsv, *flClean)
+		if err != nil {
+			log.Fatal("Error reading results from disk " + err.Error())
+		}
+		if events != nil {
+			close(progressDone)
+			events.summary(failures)
+		}
+		return
 	}
+
+	results := gathered()
 	sort.Sort(results)
-	output(results, *flJSON, *flCsv, *flClean)
+	results = bsw.MarkWildcardCerts(results)
+	if *flParked || *flSkipParked {
+		results = bsw.MarkParked(results)
+		unparked := bsw.Results{}
+		for _, r := range results {
+			if r.Meta["parked"] == "" {
+				unparked = append(unparked, r)
+			}
+		}
+		if *flSkipParked {
+			log.Printf("Left out %d parked results", len(results)-len(unparked))
+			results = unparked
+		} else {
+			log.Printf("Marked %d parked results", len(results)-len(unparked))
+		}
+	}
+	if *flAnomalies {
+		results = bsw.FlagAnomalies(results, domains)
+		n := 0
+		for _, r := range results {
+			if r.Meta["anomaly"] != "" {
+				n++
+			}
+		}
+		log.Printf("Flagged %d anomalous results", n)
+	}
+	for i, r := range results {
+		results[i] = withASN(r, asnByIP)
+	}
+	var summaries []domainSummary
+	if *flSummary {
+		summaries = summarize(results)
+	}
+	var indicators []indicator
+	if seen != nil {
+		indicators = buildIndicators(results, seen)
+	}
+	var clusters []geoCluster
+	if *flGeo {
+		geoByIP, err := bsw.IPAPIGeo(resultIPs(results))
+		if err != nil {
+			log.Printf("Error locating IPs with ip-api.com %s", err.Error())
+		}
+		clusters = clusterByGeo(results, geoByIP)
+	}
+	// Redaction is applied to everything written or sent after this point.
+	outDomains, outIPs := domains, ipAddrList
+	if redact != nil {
+		results = redact.results(results)
+		outDomains, outIPs = redact.strings(domains), redact.strings(ipAddrList)
+		for i := range summaries {
+			summaries[i].Domain = redact.hostname(summaries[i].Domain)
+		}
+		for i := range indicators {
+			indicators[i].Hostname, indicators[i].IP = redact.hostname(indicators[i].Hostname), redact.ip(indicators[i].IP)
+		}
+	}
+	// When uploading, output is written to stdout and captured for the upload. In -machine
+	// mode stdout is reserved for events.
+	var out io.Writer = os.Stdout
+	if events != nil {
+		out = ioutil.Discard
+	}
+	var captured bytes.Buffer
+	if uploadDest != nil {
+		out = io.MultiWriter(out, &captured)
+	}
+	if *flTemplate != "" {
+		outputTemplate(out, *flTemplate, scanInfo{
+			Version:  bsw.VERSION,
+			Started:  started,
+			Finished: time.Now(),
+			Domains:  outDomains,
+			IPs:      outIPs,
+			Results:  results,
+		})
+	} else {
+		output(out, results, *flJSON, *flCsv, *flClean)
+	}
+	if *flSummary {
+		if *flJSON || *flCsv || *flTemplate != "" || events != nil {
+			outputSummary(os.Stderr, summaries)
+		} else {
+			fmt.Fprintln(out)
+			outputSummary(out, summaries)
+		}
+	}
+	if *flGeo {
+		if *flJSON || *flCsv || *flTemplate != "" || events != nil {
+			outputGeoClusters(os.Stderr, clusters)
+		} else {
+			fmt.Fprintln(out)
+			outputGeoClusters(out, clusters)
+		}
+	}
+
+	if uploadDest != nil {
+		stamp := started.UTC().Format("20060102T150405Z")
+		ext := ".txt"
+		switch {
+		case *flTemplate != "":
+			ext = path.Ext(*flTemplate)
+		case *flJSON:
+			ext = ".json"
+		case *flCsv:
+			ext = ".csv"
+		}
+		raw, _ := json.Marshal(results)
+		for name, data := range map[string][]byte{
+			stamp + "-output" + ext: captured.Bytes(),
+			stamp + "-results.json": raw,
+		} {
+			if err := uploadDest.put(name, data); err != nil {
+				log.Fatal("Error uploading " + name + " " + err.Error())
+			}
+			log.Printf("Uploaded %s", uploadDest.url(name))
+		}
+	}
+
+	if *flNSEC3Hashes != "" {
+		if err := writeFile(*flNSEC3Hashes, func(w io.Writer) error {
+			for _, zone := range nsec3Zones {
+				write := zone.WriteHashcat
+				if *flNSEC3Format == "john" {
+					write = zone.WriteJohn
+				}
+				if err := write(w); err != nil {
+					return err
+				}
+			}
+			return nil
+		}); err != nil {
+			log.Fatal("Error writing NSEC3 hashes " + err.Error())
+		}
+		log.Printf("Wrote NSEC3 hashes of %d zones to %s", len(nsec3Zones), *flNSEC3Hashes)
+	}
+	if *flIndicators != "" {
+		if err := writeFile(*flIndicators, func(w io.Writer) error { return writeIndicatorsCSV(w, indicators) }); err != nil {
+			log.Fatal("Error writing indicators " + err.Error())
+		}
+		log.Printf("Wrote %d indicators to %s", len(indicators), *flIndicators)
+	}
+	if *flOpenIOC != "" {
+		if err := writeFile(*flOpenIOC, func(w io.Writer) error { return writeOpenIOC(w, indicators, outDomains, time.Now()) }); err != nil {
+			log.Fatal("Error writing OpenIOC " + err.Error())
+		}
+		log.Printf("Wrote OpenIOC to %s", *flOpenIOC)
+	}
+
+	if *flTheHive != "" {
+		if err := bsw.TheHiveAlert(*flTheHive, *flTheHiveKey, results); err != nil {
+			log.Fatal("Error raising TheHive alert " + err.Error())
+		}
+	}
+
+	if events != nil {
+		close(progressDone)
+		events.summary(failures)
+	}
 }
@@ -0,0 +1,22 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+func TestCheckQuotas(t *testing.T) {
+	q := bsw.Quota{Requests: 10, Period: time.Hour}
+	if err := checkQuotas(map[string]bsw.Quota{"shodan": q, "crtsh": q, "wayback": q}); err != nil {
+		t.Error("checkQuotas rejected sources that spend quota")
+		t.Log(err)
+	}
+	if err := checkQuotas(map[string]bsw.Quota{"dictionary": q}); err == nil {
+		t.Error("checkQuotas did not reject a source that does not spend quota")
+	}
+	if err := checkQuotas(map[string]bsw.Quota{"nope": q}); err == nil {
+		t.Error("checkQuotas did not reject an unknown source")
+	}
+}
@@ -0,0 +1,92 @@
+package bsw
+
+import (
+	"math"
+	"strings"
+)
+
+// Minimum number of hostnames within a domain before its naming is used to flag outliers.
+const anomalyMinSample = 10
+
+// Features of the leftmost label of a hostname that are compared to the other hostnames within
+// a domain.
+func labelFeatures(label string) map[string]float64 {
+	counts := make(map[rune]float64)
+	digits := 0.0
+	for _, c := range label {
+		counts[c]++
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	n := float64(len(label))
+	entropy := 0.0
+	for _, c := range counts {
+		p := c / n
+		entropy -= p * math.Log2(p)
+	}
+	return map[string]float64{"length": n, "digits": digits / n, "entropy": entropy}
+}
+
+// FlagAnomalies returns a copy of results where results that resolve to a known parking or
+// sinkhole network, or whose hostname does not fit the naming of the other hostnames found
+// within the same domain, have the reasons recorded in their "anomaly" metadata.
+func FlagAnomalies(results Results, domains []string) Results {
+	flagged := append(Results{}, results...)
+	reasons := make([][]string, len(flagged))
+	for i, r := range flagged {
+		if name := parkedNetwork(r.IP); name != "" {
+			reasons[i] = append(reasons[i], "sinkhole:"+name)
+		}
+	}
+
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimRight(d, "."))
+		members := []int{}
+		features := []map[string]float64{}
+		seen := make(map[string]bool)
+		for i, r := range flagged {
+			name := strings.ToLower(r.Hostname)
+			if !strings.HasSuffix(name, "."+d) {
+				continue
+			}
+			label := strings.Split(strings.TrimSuffix(name, "."+d), ".")[0]
+			if label == "" {
+				continue
+			}
+			members = append(members, i)
+			f := labelFeatures(label)
+			features = append(features, f)
+			seen[name] = true
+		}
+		if len(seen) < anomalyMinSample {
+			continue
+		}
+		for _, key := range []string{"length", "digits", "entropy"} {
+			var sum, sumSq float64
+			for _, f := range features {
+				sum += f[key]
+				sumSq += f[key] * f[key]
+			}
+			n := float64(len(features))
+			mean := sum / n
+			stddev := math.Sqrt(sumSq/n - mean*mean)
+			if stddev == 0 {
+				continue
+			}
+			for j, f := range features {
+				if math.Abs(f[key]-mean)/stddev > 3 {
+					reasons[members[j]] = append(reasons[members[j]], "naming:"+key)
+				}
+			}
+		}
+	}
+
+	for i, rs := range reasons {
+		if len(rs) == 0 {
+			continue
+		}
+		flagged[i].Meta = mergeMeta(map[string]string{"anomaly": strings.Join(rs, ",")}, flagged[i].Meta)
+	}
+	return flagged
+}
@@ -0,0 +1,31 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestFlagAnomalies(t *testing.T) {
+	results := Results{}
+	for _, name := range []string{"www", "mail", "vpn", "dev", "ftp", "smtp", "test", "portal", "shop", "blog", "api", "ns1", "ns2", "cdn", "docs", "help", "wiki", "git", "jira", "owa"} {
+		results = append(results, Result{Source: "Dictionary IPv4", IP: "192.0.2.1", Hostname: name + ".example.com"})
+	}
+	results = append(results,
+		Result{Source: "crt.sh", IP: "192.0.2.2", Hostname: "x7q9z2k4v8w1m3n6p0r5t.example.com"},
+		Result{Source: "bing", IP: "91.195.240.10", Hostname: "parked.example.com"},
+	)
+	flagged := FlagAnomalies(results, []string{"example.com"})
+	if flagged[0].Meta["anomaly"] != "" {
+		t.Error("FlagAnomalies flagged a typical hostname")
+		t.Log(flagged[0])
+	}
+	if flagged[len(flagged)-2].Meta["anomaly"] == "" {
+		t.Error("FlagAnomalies did not flag an outlying hostname")
+	}
+	if flagged[len(flagged)-1].Meta["anomaly"] != "sinkhole:sedo-parking" {
+		t.Error("FlagAnomalies did not flag a parking ip")
+		t.Log(flagged[len(flagged)-1])
+	}
+	if results[len(results)-1].Meta != nil {
+		t.Error("FlagAnomalies modified its input")
+	}
+}
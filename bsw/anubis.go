@@ -0,0 +1,44 @@
+package bsw
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const anubisURL = "https://jldc.me/anubis/subdomains/"
+
+var anubisSource = newHTTPSource("anubis", 0)
+
+// Anubis uses the Anubis subdomain database at jldc.me to find subdomains of a domain. When
+// resolve is true only subdomains that resolve are returned, otherwise they are returned
+// without an IP.
+func Anubis(domain string, resolve bool, serverAddr string) (string, Results, error) {
+	task := "anubis"
+	results := Results{}
+	body, err := anubisSource.get(anubisURL + domain)
+	if err != nil {
+		return task, results, err
+	}
+	names := []string{}
+	if err := json.Unmarshal(body, &names); err != nil {
+		return task, results, err
+	}
+	domainSet := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "*."))
+		if domainSet[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+			continue
+		}
+		domainSet[name] = true
+		if !resolve {
+			results = append(results, Result{Source: task, Hostname: name})
+			continue
+		}
+		ip, answer, err := lookupNameOrCname(name, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, nil
+}
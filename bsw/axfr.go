@@ -1,12 +1,14 @@
 package bsw
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/miekg/dns"
 )
 
-// AXFR attempts a zone transfer for the domain.
+// AXFR attempts a zone transfer for the domain. Delegations to subzones are recorded in the
+// "delegation" metadata of the result for each of their name servers.
 func AXFR(domain, serverAddr string) (string, Results, error) {
 	task := "axfr"
 	results := Results{}
@@ -25,49 +27,82 @@ func AXFR(domain, serverAddr string) (string, Results, error) {
 			return task, results, err
 		}
 		for ex := range in {
-			for _, a := range ex.RR {
-				var ip, hostname string
-				switch v := a.(type) {
-				case *dns.A:
-					ip = v.A.String()
-					hostname = v.Hdr.Name
-				case *dns.AAAA:
-					ip = v.AAAA.String()
-					hostname = v.Hdr.Name
-				case *dns.PTR:
-					ip = v.Hdr.Name
-					hostname = v.Ptr
-				case *dns.NS:
-					cip, err := LookupName(v.Ns, serverAddr)
-					if err != nil || cip == "" {
-						continue
-					}
-					ip = cip
-					hostname = v.Ns
-				case *dns.CNAME:
-					cip, err := LookupName(v.Target, serverAddr)
-					if err != nil || cip == "" {
-						continue
-					}
-					hostname = v.Hdr.Name
-					ip = cip
-				case *dns.SRV:
-					cip, err := LookupName(v.Target, serverAddr)
-					if err != nil || ip == "" {
-						continue
-					}
-					ip = cip
-					hostname = v.Target
-				default:
-					continue
-				}
-				results = append(results, Result{
-					Source:   task,
-					IP:       ip,
-					Hostname: strings.TrimRight(hostname, "."),
-				})
-			}
+			results = append(results, axfrResults(task, domain, s, ex.RR, serverAddr)...)
 		}
 	}
 	return task, results, nil
 }
+
+// Converts the records of a zone transfer from server into results. Names that records point
+// to are resolved, and the record type along with any data that is not an IP or hostname is kept
+// in metadata. Records that do not resolve are skipped, except for TXT records and delegations.
+func axfrResults(task, zone, server string, rrs []dns.RR, serverAddr string) Results {
+	results := Results{}
+	resolve := func(name string) string {
+		ip, _, err := lookupNameOrCname(name, serverAddr)
+		if err != nil {
+			return ""
+		}
+		return ip
+	}
+	for _, a := range rrs {
+		owner := strings.TrimRight(a.Header().Name, ".")
+		r := Result{
+			Source: task,
+			Meta:   map[string]string{"resolver": server, "type": dns.TypeToString[a.Header().Rrtype]},
+		}
+		switch v := a.(type) {
+		case *dns.A:
+			r.IP = v.A.String()
+			r.Hostname = owner
+		case *dns.AAAA:
+			r.IP = v.AAAA.String()
+			r.Hostname = owner
+		case *dns.PTR:
+			r.IP = v.Hdr.Name
+			r.Hostname = v.Ptr
+		case *dns.NS:
+			r.Hostname = v.Ns
+			r.IP = resolve(v.Ns)
+			if !strings.EqualFold(dns.Fqdn(owner), dns.Fqdn(zone)) {
+				r.Meta["delegation"] = strings.ToLower(owner)
+			} else if r.IP == "" {
+				continue
+			}
+		case *dns.CNAME:
+			r.Hostname = owner
+			r.IP = resolve(v.Target)
+			r.Meta["cname"] = strings.TrimRight(v.Target, ".")
+			if r.IP == "" {
+				continue
+			}
+		case *dns.MX:
+			r.Hostname = v.Mx
+			r.IP = resolve(v.Mx)
+			r.Meta["mx_priority"] = strconv.Itoa(int(v.Preference))
+			if r.IP == "" {
+				continue
+			}
+		case *dns.SRV:
+			r.Hostname = v.Target
+			r.IP = resolve(v.Target)
+			r.Port = int(v.Port)
+			r.Meta["srv"] = owner
+			labels := dns.SplitDomainName(owner)
+			if len(labels) > 1 && strings.HasPrefix(labels[1], "_") {
+				r.Protocol = strings.ToLower(labels[1][1:])
+			}
+			if r.IP == "" {
+				continue
+			}
+		case *dns.TXT:
+			r.Hostname = owner
+			r.Meta["txt"] = strings.Join(v.Txt, "")
+		default:
+			continue
+		}
+		r.Hostname = strings.TrimRight(r.Hostname, ".")
+		results = append(results, r)
+	}
+	return results
+}
@@ -2,6 +2,8 @@ package bsw
 
 import (
 	"testing"
+
+	"github.com/miekg/dns"
 )
 
 func TestAXFR(t *testing.T) {
@@ -14,3 +16,35 @@ func TestAXFR(t *testing.T) {
 		t.Error("expected more results from AXFR")
 	}
 }
+
+func TestAXFRResults(t *testing.T) {
+	rrs := []dns.RR{}
+	for _, s := range []string{
+		"example.com. 300 IN A 192.0.2.1",
+		"corp.example.com. 300 IN NS ns1.corp.example.com.",
+		"example.com. 300 IN TXT \"v=spf1 -all\"",
+		"_sip._tcp.example.com. 300 IN SRV 10 5 5060 192.0.2.2.nip.invalid.",
+	} {
+		rr, err := dns.NewRR(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rrs = append(rrs, rr)
+	}
+	results := axfrResults("axfr", "example.com", "ns1.example.com.", rrs, "127.0.0.1")
+	if len(results) != 3 {
+		t.Fatal("axfrResults returned incorrect number of results")
+	}
+	if results[0].IP != "192.0.2.1" || results[0].Meta["type"] != "A" {
+		t.Error("axfrResults returned incorrect A result")
+		t.Log(results[0])
+	}
+	if results[1].Meta["delegation"] != "corp.example.com" {
+		t.Error("axfrResults did not record delegation")
+		t.Log(results[1])
+	}
+	if results[2].Meta["txt"] != "v=spf1 -all" {
+		t.Error("axfrResults did not record TXT data")
+		t.Log(results[2])
+	}
+}
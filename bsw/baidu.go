@@ -0,0 +1,47 @@
+package bsw
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var baiduSource = newHTTPSource("baidu", 0)
+
+// Returns the hostnames within domain shown on a Baidu results page. Result links go through
+// a redirect, so the destination is read from each result's mu attribute and displayed URL.
+func baiduHostnames(doc *goquery.Document, domain string) []string {
+	names := []string{}
+	for _, v := range extract("baidu", doc) {
+		name := extractedHostname(v)
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// BaiduDomain uses baidu's 'site:' search operator and scrapes the HTML to find ips and
+// hostnames for a domain. Baidu has no 'ip:' operator, so only domains are searched.
+func BaiduDomain(domain, server string) (string, Results, error) {
+	task := "baidu"
+	results := Results{}
+	doc, err := baiduSource.scrapeURL("https://www.baidu.com/s?wd=" + url.QueryEscape("site:"+domain) + "&rn=50")
+	if err != nil {
+		return task, results, err
+	}
+	seen := make(map[string]bool)
+	for _, name := range baiduHostnames(doc, domain) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		ip, answer, err := lookupNameOrCname(name, server)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, nil
+}
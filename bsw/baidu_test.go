@@ -0,0 +1,23 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestBaiduHostnamesOffline(t *testing.T) {
+	page := `<div class="result c-container" mu="https://www.example.com/about">
+<a class="c-showurl" href="http://www.baidu.com/link?url=abc">mail.example.com/login...</a>
+</div>
+<div class="result c-container" mu="https://example.org/"></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := baiduHostnames(doc, "example.com")
+	if len(names) != 2 || names[0] != "www.example.com" || names[1] != "mail.example.com" {
+		t.Errorf("unexpected hostnames %v", names)
+	}
+}
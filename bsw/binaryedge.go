@@ -0,0 +1,82 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const binaryEdgeURL = "https://api.binaryedge.io/v2/query/domains"
+
+var binaryEdgeSource = newHTTPSource("binaryedge", 0)
+
+type binaryEdgeMessage struct {
+	Page     int               `json:"page"`
+	PageSize int               `json:"pagesize"`
+	Total    int               `json:"total"`
+	Events   []json.RawMessage `json:"events"`
+}
+
+// Requests every page of a BinaryEdge domains endpoint, calling fn with the events of each.
+func binaryEdgePages(path, key string, fn func(events []json.RawMessage)) error {
+	for page := 1; ; page++ {
+		req, err := http.NewRequest("GET", binaryEdgeURL+path+"?page="+strconv.Itoa(page), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("X-Key", key)
+		body, err := binaryEdgeSource.fetch(http.DefaultClient, req, false)
+		if err != nil {
+			return err
+		}
+		m := &binaryEdgeMessage{}
+		if err := json.Unmarshal(body, m); err != nil {
+			return err
+		}
+		fn(m.Events)
+		if len(m.Events) == 0 || m.PageSize == 0 || page*m.PageSize >= m.Total {
+			return nil
+		}
+	}
+}
+
+// BinaryEdgeDomain uses BinaryEdge's '/query/domains/subdomain/' API endpoint to find subdomains of
+// a domain, returning those that resolve.
+func BinaryEdgeDomain(domain, key, serverAddr string) (string, Results, error) {
+	task := "binaryedge"
+	results := Results{}
+	err := binaryEdgePages("/subdomain/"+domain, key, func(events []json.RawMessage) {
+		for _, e := range events {
+			var name string
+			if err := json.Unmarshal(e, &name); err != nil {
+				continue
+			}
+			name = strings.ToLower(name)
+			ip, answer, err := lookupNameOrCname(name, serverAddr)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+		}
+	})
+	return task, results, err
+}
+
+// BinaryEdgeIP uses BinaryEdge's '/query/domains/ip/' API endpoint to find hostnames for an IP.
+func BinaryEdgeIP(ip, key string) (string, Results, error) {
+	task := "binaryedge"
+	results := Results{}
+	err := binaryEdgePages("/ip/"+ip, key, func(events []json.RawMessage) {
+		for _, e := range events {
+			var d struct {
+				Domain string `json:"domain"`
+			}
+			if err := json.Unmarshal(e, &d); err != nil || d.Domain == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(d.Domain)})
+		}
+	})
+	return task, results, err
+}
@@ -2,12 +2,9 @@ package bsw
 
 import (
 	"encoding/json"
-	"errors"
-	"io/ioutil"
+	"fmt"
 	"net/http"
 	"net/url"
-
-	"github.com/PuerkitoBio/goquery"
 )
 
 type bingMessage struct {
@@ -34,6 +31,12 @@ type bingMetadata struct {
 
 const azureURL = "https://api.datamarket.azure.com"
 
+// The Bing search API, legacy or v7, and the scraper of Bing's results pages.
+var (
+	bingSource     = newHTTPSource("bing", 0)
+	bingHTMLSource = newHTTPSource("bing-html", 0)
+)
+
 // FindBingSearchPath attempts an authenticated search request to two different Bing API paths. If and when a
 // search is successfull, that path will be returned. If no path is valid this function
 // returns an error.
@@ -56,7 +59,7 @@ func FindBingSearchPath(key string) (string, error) {
 			return path, nil
 		}
 	}
-	return "", errors.New("invalid Bing API key")
+	return "", fmt.Errorf("invalid Bing API key: %w", ErrAuth)
 }
 
 // BingAPIIP uses the bing search API and 'ip' search operator to find alternate hostnames for
@@ -64,18 +67,12 @@ func FindBingSearchPath(key string) (string, error) {
 func BingAPIIP(ip, key, path string) (string, Results, error) {
 	task := "bing API"
 	results := Results{}
-	client := &http.Client{}
 	req, err := http.NewRequest("GET", azureURL+path+"?Query=%27ip:"+ip+"%27&$top=50&Adult=%27off%27&$format=json", nil)
 	if err != nil {
 		return task, results, err
 	}
 	req.SetBasicAuth(key, key)
-	resp, err := client.Do(req)
-	if err != nil {
-		return task, results, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := bingSource.fetch(http.DefaultClient, req, false)
 	if err != nil {
 		return task, results, err
 	}
@@ -96,18 +93,12 @@ func BingAPIIP(ip, key, path string) (string, Results, error) {
 func BingAPIDomain(domain, key, path, server string) (string, Results, error) {
 	task := "bing API"
 	results := Results{}
-	client := &http.Client{}
 	req, err := http.NewRequest("GET", azureURL+path+"?Query=%27domain:"+domain+"%27&$top=50&Adult=%27off%27&$format=json", nil)
 	if err != nil {
 		return task, results, err
 	}
 	req.SetBasicAuth(key, key)
-	resp, err := client.Do(req)
-	if err != nil {
-		return task, results, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := bingSource.fetch(http.DefaultClient, req, false)
 	if err != nil {
 		return task, results, err
 	}
@@ -120,18 +111,11 @@ func BingAPIDomain(domain, key, path, server string) (string, Results, error) {
 		if err != nil || u.Host == "" {
 			continue
 		}
-		ip, err := LookupName(u.Host, server)
+		ip, answer, err := lookupNameOrCname(u.Host, server)
 		if err != nil || ip == "" {
-			cfqdn, err := LookupCname(u.Host, server)
-			if err != nil || cfqdn == "" {
-				continue
-			}
-			ip, err = LookupName(cfqdn, server)
-			if err != nil || ip == "" {
-				continue
-			}
+			continue
 		}
-		results = append(results, Result{Source: task, IP: ip, Hostname: u.Host})
+		results = append(results, Result{Source: task, IP: ip, Hostname: u.Host, Meta: answer})
 	}
 	return task, results, nil
 }
@@ -140,25 +124,21 @@ func BingAPIDomain(domain, key, path, server string) (string, Results, error) {
 func BingIP(ip string) (string, Results, error) {
 	task := "bing"
 	results := Results{}
-	resp, err := http.Get("http://www.bing.com/search?q=ip:" + ip)
-	if err != nil {
-		return task, results, err
-	}
-	doc, err := goquery.NewDocumentFromResponse(resp)
+	doc, err := bingHTMLSource.scrapeURL("http://www.bing.com/search?q=ip:" + ip)
 	if err != nil {
 		return task, results, err
 	}
-	doc.Selection.Find("cite").Each(func(_ int, s *goquery.Selection) {
-		u, err := url.Parse(s.Text())
-		if err != nil || u.Host == "" {
-			return
+	for _, v := range extract("bing-html", doc) {
+		host := extractedHostname(v)
+		if host == "" {
+			continue
 		}
 		results = append(results, Result{
 			Source:   task,
 			IP:       ip,
-			Hostname: u.Host,
+			Hostname: host,
 		})
-	})
+	}
 	return task, results, err
 }
 
@@ -166,32 +146,20 @@ func BingIP(ip string) (string, Results, error) {
 func BingDomain(domain, server string) (string, Results, error) {
 	task := "bing"
 	results := Results{}
-	resp, err := http.Get("http://www.bing.com/search?q=domain:" + domain)
-	if err != nil {
-		return task, results, err
-	}
-	doc, err := goquery.NewDocumentFromResponse(resp)
+	doc, err := bingHTMLSource.scrapeURL("http://www.bing.com/search?q=domain:" + domain)
 	if err != nil {
 		return task, results, err
 	}
-	doc.Selection.Find("cite").Each(func(_ int, s *goquery.Selection) {
-		u, err := url.Parse(s.Text())
-		if err != nil || u.Host == "" {
-			return
+	for _, v := range extract("bing-html", doc) {
+		host := extractedHostname(v)
+		if host == "" {
+			continue
 		}
-		ip, err := LookupName(u.Host, server)
+		ip, answer, err := lookupNameOrCname(host, server)
 		if err != nil || ip == "" {
-			cfqdn, err := LookupCname(u.Host, server)
-			if err != nil || cfqdn == "" {
-				return
-			}
-			ip, err = LookupName(cfqdn, server)
-			if err != nil || ip == "" {
-				return
-			}
-
+			continue
 		}
-		results = append(results, Result{Source: task, IP: ip, Hostname: u.Host})
-	})
+		results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: answer})
+	}
 	return task, results, err
 }
@@ -1,6 +1,7 @@
 package bsw
 
 import (
+	"encoding/json"
 	"strings"
 	"testing"
 )
@@ -38,3 +39,16 @@ func TestBingIP(t *testing.T) {
 		t.Error("Bing did not find the correct domain")
 	}
 }
+
+func TestBingV7Hosts(t *testing.T) {
+	m := &bingV7Message{}
+	body := `{"webPages":{"totalEstimatedMatches":3,"value":[{"url":"https://www.example.com/a"},{"url":"http://mail.example.com:8080/"},{"url":"not a url"}]}}`
+	if err := json.Unmarshal([]byte(body), m); err != nil {
+		t.Fatal(err)
+	}
+	hosts := bingV7Hosts(m)
+	if len(hosts) != 2 || hosts[0] != "www.example.com" || hosts[1] != "mail.example.com" {
+		t.Error("bingV7Hosts returned incorrect hosts")
+		t.Log(hosts)
+	}
+}
@@ -0,0 +1,104 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const bingV7URL = "https://api.bing.microsoft.com/v7.0/search"
+
+const (
+	bingV7PageSize = 50
+	bingV7MaxPages = 4
+)
+
+type bingV7Message struct {
+	WebPages struct {
+		TotalEstimatedMatches int `json:"totalEstimatedMatches"`
+		Value                 []struct {
+			URL string `json:"url"`
+		} `json:"value"`
+	} `json:"webPages"`
+}
+
+// Requests a page of Bing Web Search API v7 results for query.
+func bingV7Page(query, key string, offset int) (*bingV7Message, error) {
+	v := url.Values{}
+	v.Set("q", query)
+	v.Set("count", strconv.Itoa(bingV7PageSize))
+	v.Set("offset", strconv.Itoa(offset))
+	v.Set("responseFilter", "Webpages")
+	v.Set("safeSearch", "Off")
+	req, err := http.NewRequest("GET", bingV7URL+"?"+v.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", key)
+	body, err := bingSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return nil, err
+	}
+	m := &bingV7Message{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Returns the hosts of the URLs in m.
+func bingV7Hosts(m *bingV7Message) []string {
+	hosts := []string{}
+	for _, page := range m.WebPages.Value {
+		if u, err := url.Parse(page.URL); err == nil && u.Hostname() != "" {
+			hosts = append(hosts, u.Hostname())
+		}
+	}
+	return hosts
+}
+
+// Runs a Bing Web Search API v7 search for query, calling fn with the host of each result.
+// At most bingV7MaxPages are requested.
+func bingV7Search(query, key string, fn func(host string)) error {
+	for page := 0; page < bingV7MaxPages; page++ {
+		offset := page * bingV7PageSize
+		m, err := bingV7Page(query, key, offset)
+		if err != nil {
+			return err
+		}
+		for _, host := range bingV7Hosts(m) {
+			fn(host)
+		}
+		if len(m.WebPages.Value) < bingV7PageSize || offset+bingV7PageSize >= m.WebPages.TotalEstimatedMatches {
+			break
+		}
+	}
+	return nil
+}
+
+// BingV7IP uses the Bing Web Search API v7 and 'ip:' search operator to find alternate
+// hostnames for a single IP.
+func BingV7IP(ip, key string) (string, Results, error) {
+	task := "bing API"
+	results := Results{}
+	err := bingV7Search("ip:"+ip, key, func(host string) {
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	})
+	return task, results, err
+}
+
+// BingV7Domain uses the Bing Web Search API v7 and 'domain:' search operator to find
+// hostnames for a single domain, resolving each to an IP.
+func BingV7Domain(domain, key, server string) (string, Results, error) {
+	task := "bing API"
+	results := Results{}
+	err := bingV7Search("domain:"+domain, key, func(host string) {
+		ip, answer, err := lookupNameOrCname(host, server)
+		if err != nil || ip == "" {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: answer})
+	})
+	return task, results, err
+}
@@ -0,0 +1,62 @@
+package bsw
+
+import (
+	"net"
+)
+
+// Networks that should not appear on the public internet: private, shared, loopback,
+// link-local, documentation, benchmarking, multicast, and reserved ranges.
+var bogonCIDRs = []string{
+	"0.0.0.0/8", "10.0.0.0/8", "100.64.0.0/10", "127.0.0.0/8", "169.254.0.0/16",
+	"172.16.0.0/12", "192.0.0.0/24", "192.0.2.0/24", "192.168.0.0/16", "198.18.0.0/15",
+	"198.51.100.0/24", "203.0.113.0/24", "224.0.0.0/4", "240.0.0.0/4",
+	"::/128", "::1/128", "100::/64", "2001:db8::/32", "fc00::/7", "fe80::/10",
+	"ff00::/8",
+}
+
+// Networks that can not be assigned to a host: "this" network, loopback, multicast, and the
+// reserved range that includes the limited broadcast address.
+var reservedCIDRs = []string{
+	"0.0.0.0/8", "127.0.0.0/8", "224.0.0.0/4", "240.0.0.0/4", "::/128", "::1/128", "ff00::/8",
+}
+
+var (
+	bogons   = parseCIDRs(bogonCIDRs)
+	reserved = parseCIDRs(reservedCIDRs)
+)
+
+func parseCIDRs(cidrs []string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, c := range cidrs {
+		_, ipnet, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, ipnet)
+	}
+	return nets
+}
+
+func containsIP(nets []*net.IPNet, ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBogon returns whether ip is within a private or otherwise bogon network.
+func IsBogon(ip string) bool {
+	return containsIP(bogons, ip)
+}
+
+// IsReserved returns whether ip is a reserved, loopback, multicast, or broadcast address that
+// can not belong to a host.
+func IsReserved(ip string) bool {
+	return containsIP(reserved, ip)
+}
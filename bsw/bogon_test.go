@@ -0,0 +1,31 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestIsBogon(t *testing.T) {
+	for _, ip := range []string{"10.1.2.3", "172.31.255.255", "192.168.0.1", "100.64.0.1", "fd00::1", "fe80::1"} {
+		if !IsBogon(ip) {
+			t.Error(ip + " is a bogon")
+		}
+	}
+	for _, ip := range []string{"8.8.8.8", "172.32.0.1", "2606:4700::1111", "not an ip"} {
+		if IsBogon(ip) {
+			t.Error(ip + " is not a bogon")
+		}
+	}
+}
+
+func TestIsReserved(t *testing.T) {
+	for _, ip := range []string{"0.0.0.1", "224.0.0.1", "255.255.255.255", "ff02::1"} {
+		if !IsReserved(ip) {
+			t.Error(ip + " is reserved")
+		}
+	}
+	for _, ip := range []string{"10.0.0.255", "8.8.8.8", "2001:db8::1"} {
+		if IsReserved(ip) {
+			t.Error(ip + " is not reserved")
+		}
+	}
+}
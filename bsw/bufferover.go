@@ -0,0 +1,69 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// bufferover.run serves its forward DNS and TLS certificate datasets from separate hosts.
+var bufferOverURLs = []string{"https://dns.bufferover.run/dns", "https://tls.bufferover.run/dns"}
+
+var bufferOverSource = newHTTPSource("bufferover", 0)
+
+type bufferOverMessage struct {
+	FDNSA   []string `json:"FDNS_A"`
+	RDNS    []string `json:"RDNS"`
+	Results []string `json:"Results"`
+}
+
+// Parses a comma separated bufferover.run record into an ip and each hostname in it that is
+// the domain or a subdomain of it.
+func bufferOverRecord(record, domain string) (string, []string) {
+	fields := strings.Split(record, ",")
+	if len(fields) < 2 || net.ParseIP(fields[0]) == nil {
+		return "", nil
+	}
+	names := []string{}
+	for _, f := range fields[1:] {
+		name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(f), "*."))
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			names = append(names, name)
+		}
+	}
+	return fields[0], names
+}
+
+// BufferOver queries the bufferover.run DNS and TLS datasets for a domain, returning the ip and
+// hostname tuples for the domain and its subdomains. The key is optional.
+func BufferOver(domain, key string) (string, Results, error) {
+	task := "bufferover"
+	results := Results{}
+	for _, u := range bufferOverURLs {
+		req, err := http.NewRequest("GET", u+"?q=."+domain, nil)
+		if err != nil {
+			return task, results, err
+		}
+		if key != "" {
+			req.Header.Set("x-api-key", key)
+		}
+		body, err := bufferOverSource.fetch(http.DefaultClient, req, false)
+		if err != nil {
+			return task, results, err
+		}
+		m := &bufferOverMessage{}
+		if err := json.Unmarshal(body, m); err != nil {
+			return task, results, err
+		}
+		for _, records := range [][]string{m.FDNSA, m.RDNS, m.Results} {
+			for _, record := range records {
+				ip, names := bufferOverRecord(record, domain)
+				for _, name := range names {
+					results = append(results, Result{Source: task, IP: ip, Hostname: name})
+				}
+			}
+		}
+	}
+	return task, results, nil
+}
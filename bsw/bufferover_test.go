@@ -0,0 +1,19 @@
+package bsw
+
+import "testing"
+
+func TestBufferOverRecord(t *testing.T) {
+	ip, names := bufferOverRecord("192.0.2.10,WWW.example.com", "example.com")
+	if ip != "192.0.2.10" || len(names) != 1 || names[0] != "www.example.com" {
+		t.Error("bufferOverRecord returned incorrect ip or hostname")
+		t.Log(ip, names)
+	}
+	ip, names = bufferOverRecord("192.0.2.11,5e8f0c,*.example.com,mail.example.com,example.net", "example.com")
+	if ip != "192.0.2.11" || len(names) != 2 {
+		t.Error("bufferOverRecord returned incorrect hostnames for a TLS record")
+		t.Log(ip, names)
+	}
+	if ip, _ := bufferOverRecord("not-an-ip,www.example.com", "example.com"); ip != "" {
+		t.Error("bufferOverRecord returned an ip for an invalid record")
+	}
+}
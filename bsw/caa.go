@@ -0,0 +1,108 @@
+package bsw
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Returns the CAA records for a domain.
+func lookupCAARecords(domain, serverAddr string) ([]*dns.CAA, error) {
+	records := []*dns.CAA{}
+	m := &dns.Msg{}
+	m.SetQuestion(dns.Fqdn(domain), dns.TypeCAA)
+	in, _, err := exchange(m, serverAddr)
+	if err != nil {
+		return records, err
+	}
+	if len(in.Answer) < 1 {
+		return records, errNoAnswer
+	}
+	for _, a := range in.Answer {
+		if caa, ok := a.(*dns.CAA); ok {
+			records = append(records, caa)
+		}
+	}
+	return records, nil
+}
+
+// Returns the hostname of a CAA iodef URL, either mailto: or http(s):.
+func iodefHostname(value string) string {
+	u, err := url.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return ""
+	}
+	if strings.EqualFold(u.Scheme, "mailto") {
+		i := strings.LastIndex(u.Opaque, "@")
+		if i < 0 {
+			return ""
+		}
+		return strings.ToLower(strings.TrimRight(u.Opaque[i+1:], "."))
+	}
+	return strings.ToLower(strings.TrimRight(u.Hostname(), "."))
+}
+
+// CAA looks up the CAA records of a domain. The certificate authorities allowed to issue for
+// the domain, and any account URIs they are restricted to, are returned with the domain as
+// hostname and no ip, in "caa_issue", "caa_issuewild", and "caa_accounturi" metadata. The
+// hosts of iodef contact URLs are returned with every ip they resolve to.
+func CAA(domain, serverAddr string) (string, Results, error) {
+	task := "caa"
+	results := Results{}
+	records, err := lookupCAARecords(domain, serverAddr)
+	if err != nil {
+		return task, results, err
+	}
+	values := make(map[string]map[string]bool)
+	iodef := []string{}
+	for _, caa := range records {
+		tag := strings.ToLower(caa.Tag)
+		switch tag {
+		case "issue", "issuewild":
+			params := strings.Split(caa.Value, ";")
+			if ca := strings.TrimSpace(params[0]); ca != "" {
+				if values[tag] == nil {
+					values[tag] = make(map[string]bool)
+				}
+				values[tag][ca] = true
+			}
+			for _, p := range params[1:] {
+				kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+				if len(kv) == 2 && strings.EqualFold(kv[0], "accounturi") {
+					if values["accounturi"] == nil {
+						values["accounturi"] = make(map[string]bool)
+					}
+					values["accounturi"][kv[1]] = true
+				}
+			}
+		case "iodef":
+			if host := iodefHostname(caa.Value); host != "" {
+				iodef = append(iodef, host)
+			}
+		}
+	}
+	if len(values) > 0 {
+		meta := make(map[string]string)
+		for tag, set := range values {
+			list := []string{}
+			for v := range set {
+				list = append(list, v)
+			}
+			sort.Strings(list)
+			meta["caa_"+tag] = strings.Join(list, ",")
+		}
+		results = append(results, Result{Source: task, Hostname: strings.TrimRight(domain, "."), Meta: meta})
+	}
+	for _, host := range iodef {
+		ips, answer, err := lookupAddrs(host, serverAddr)
+		if err != nil {
+			continue
+		}
+		for _, ip := range ips {
+			results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: mergeMeta(answer, map[string]string{"caa": "iodef"})})
+		}
+	}
+	return task, results, nil
+}
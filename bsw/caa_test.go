@@ -0,0 +1,17 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestIodefHostnameOffline(t *testing.T) {
+	for value, want := range map[string]string{
+		"mailto:security@Example.com":      "example.com",
+		"https://iodef.example.net/report": "iodef.example.net",
+		"mailto:nobody":                    "",
+	} {
+		if got := iodefHostname(value); got != want {
+			t.Errorf("iodefHostname(%q) is %q, expected %q", value, got, want)
+		}
+	}
+}
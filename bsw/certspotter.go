@@ -0,0 +1,69 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const certSpotterURL = "https://api.certspotter.com/v1/issuances"
+
+var certSpotterSource = newHTTPSource("certspotter", 0)
+
+type certSpotterIssuance struct {
+	ID       string   `json:"id"`
+	DNSNames []string `json:"dns_names"`
+}
+
+// CertSpotter uses SSLMate's Cert Spotter issuances API to find certificates issued for a domain
+// and its subdomains, returning the DNS names that resolve. The key is optional, without one the
+// API allows a small number of requests an hour.
+func CertSpotter(domain, key, serverAddr string) (string, Results, error) {
+	task := "certspotter"
+	results := Results{}
+	seen := make(map[string]bool)
+	after := ""
+	for {
+		v := url.Values{}
+		v.Set("domain", domain)
+		v.Set("include_subdomains", "true")
+		v.Set("expand", "dns_names")
+		if after != "" {
+			v.Set("after", after)
+		}
+		req, err := http.NewRequest("GET", certSpotterURL+"?"+v.Encode(), nil)
+		if err != nil {
+			return task, results, err
+		}
+		if key != "" {
+			req.Header.Set("Authorization", "Bearer "+key)
+		}
+		body, err := certSpotterSource.fetch(http.DefaultClient, req, false)
+		if err != nil {
+			return task, results, err
+		}
+		issuances := []certSpotterIssuance{}
+		if err := json.Unmarshal(body, &issuances); err != nil {
+			return task, results, err
+		}
+		if len(issuances) == 0 {
+			return task, results, nil
+		}
+		for _, i := range issuances {
+			for _, name := range i.DNSNames {
+				name = strings.ToLower(strings.TrimPrefix(name, "*."))
+				if seen[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+					continue
+				}
+				seen[name] = true
+				ip, answer, err := lookupNameOrCname(name, serverAddr)
+				if err != nil || ip == "" {
+					continue
+				}
+				results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+			}
+		}
+		after = issuances[len(issuances)-1].ID
+	}
+}
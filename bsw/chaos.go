@@ -0,0 +1,55 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const chaosURL = "https://dns.projectdiscovery.io/dns/"
+
+var chaosSource = newHTTPSource("chaos", 0)
+
+type chaosMessage struct {
+	Domain     string   `json:"domain"`
+	Subdomains []string `json:"subdomains"`
+}
+
+// Chaos uses ProjectDiscovery's Chaos API to find subdomains of a domain, returning those that
+// resolve.
+func Chaos(domain, key, serverAddr string) (string, Results, error) {
+	task := "chaos"
+	results := Results{}
+	req, err := http.NewRequest("GET", chaosURL+domain+"/subdomains", nil)
+	if err != nil {
+		return task, results, err
+	}
+	req.Header.Set("Authorization", key)
+	body, err := chaosSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return task, results, err
+	}
+	m := &chaosMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return task, results, err
+	}
+	domainSet := make(map[string]bool)
+	for _, sub := range m.Subdomains {
+		// Subdomains are returned without the domain, the domain itself as an empty string.
+		sub = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(sub), "*."))
+		name := domain
+		if sub != "" {
+			name = sub + "." + domain
+		}
+		if domainSet[name] {
+			continue
+		}
+		domainSet[name] = true
+		ip, answer, err := lookupNameOrCname(name, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, nil
+}
@@ -0,0 +1,78 @@
+package bsw
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const circlURL = "https://www.circl.lu/pdns/query/"
+
+var circlSource = newHTTPSource("circl", 0)
+
+type circlRecord struct {
+	RRName string `json:"rrname"`
+	RRType string `json:"rrtype"`
+	RData  string `json:"rdata"`
+}
+
+// Queries CIRCL passive DNS using credentials in the form "user:pass" and calls fn for each
+// A and AAAA record in the newline delimited JSON response.
+func circlQuery(query, credentials string, fn func(r circlRecord)) error {
+	auth := strings.SplitN(credentials, ":", 2)
+	if len(auth) != 2 {
+		return errors.New("CIRCL credentials must be in the form user:pass")
+	}
+	req, err := http.NewRequest("GET", circlURL+query, nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(auth[0], auth[1])
+	return circlSource.call(func() error {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := responseError(resp); err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			r := circlRecord{}
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				continue
+			}
+			if r.RRType != "A" && r.RRType != "AAAA" {
+				continue
+			}
+			fn(r)
+		}
+		return scanner.Err()
+	})
+}
+
+// CIRCLDomain uses CIRCL's passive DNS REST API to find A and AAAA records observed for a domain.
+func CIRCLDomain(domain, credentials string) (string, Results, error) {
+	task := "circl"
+	results := Results{}
+	err := circlQuery(domain, credentials, func(r circlRecord) {
+		results = append(results, Result{Source: task, IP: r.RData, Hostname: strings.ToLower(strings.TrimSuffix(r.RRName, "."))})
+	})
+	return task, results, err
+}
+
+// CIRCLIP uses CIRCL's passive DNS REST API to find hostnames that have resolved to an IP.
+func CIRCLIP(ip, credentials string) (string, Results, error) {
+	task := "circl"
+	results := Results{}
+	err := circlQuery(ip, credentials, func(r circlRecord) {
+		if r.RData != ip {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(strings.TrimSuffix(r.RRName, "."))})
+	})
+	return task, results, err
+}
@@ -0,0 +1,45 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+var crtshSource = newHTTPSource("crtsh", 0)
+
+type crtshEntry struct {
+	CommonName string `json:"common_name"`
+	NameValue  string `json:"name_value"`
+}
+
+// CrtSh searches crt.sh's certificate transparency logs for certificates issued to a domain and
+// its subdomains, returning the IP of every CommonName and SubjectAlt name that resolves.
+func CrtSh(domain, serverAddr string) (string, Results, error) {
+	task := "crt.sh"
+	results := Results{}
+	body, err := crtshSource.get("https://crt.sh/?q=" + url.QueryEscape("%."+domain) + "&output=json")
+	if err != nil {
+		return task, results, err
+	}
+	entries := []crtshEntry{}
+	if err := json.Unmarshal(body, &entries); err != nil {
+		return task, results, err
+	}
+	domainSet := make(map[string]bool)
+	for _, e := range entries {
+		for _, name := range append(strings.Split(e.NameValue, "\n"), e.CommonName) {
+			name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "*."))
+			if domainSet[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+				continue
+			}
+			domainSet[name] = true
+			ip, answer, err := lookupNameOrCname(name, serverAddr)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+		}
+	}
+	return task, results, nil
+}
@@ -0,0 +1,26 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCrtSh(t *testing.T) {
+	tsk, results, err := CrtSh("stacktitan.com", "8.8.8.8")
+	if err != nil {
+		t.Error("CrtSh returned an error")
+		t.Log(err)
+	}
+	if tsk != "crt.sh" {
+		t.Error("task from CrtSh was not crt.sh")
+	}
+	found := false
+	for _, r := range results {
+		if strings.HasSuffix(r.Hostname, "stacktitan.com") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("CrtSh did not find the correct domain")
+	}
+}
@@ -6,10 +6,18 @@ import (
 
 const wildcardsub = "youmustcontstuctmoreplyons."
 
+// Returns the name queried to check domain for a wildcard, prefixed with the scan label.
+func wildcardName(domain string) string {
+	if scanLabel != "" {
+		return scanLabel + "-" + wildcardsub + domain
+	}
+	return wildcardsub + domain
+}
+
 // GetWildCard searches for a possible wild card host by attempting to
 // get an A record for wildcardsub + domain.
 func GetWildCard(domain, serverAddr string) string {
-	fqdn := wildcardsub + domain
+	fqdn := wildcardName(domain)
 	ip, _ := LookupName(fqdn, serverAddr)
 	return ip
 }
@@ -17,7 +25,7 @@ func GetWildCard(domain, serverAddr string) string {
 // GetWildCard6 searches for a possible wild card host by attempting to
 // get an AAAA record wildcardsub + domain.
 func GetWildCard6(domain, serverAddr string) string {
-	fqdn := wildcardsub + domain
+	fqdn := wildcardName(domain)
 	ip, _ := LookupName6(fqdn, serverAddr)
 	return ip
 }
@@ -27,26 +35,26 @@ func Dictionary(domain, subname, blacklist, serverAddr string) (string, Results,
 	task := "Dictionary IPv4"
 	results := Results{}
 	fqdn := subname + "." + domain
-	ip, err := LookupName(fqdn, serverAddr)
+	ip, answer, err := lookupName(fqdn, serverAddr)
 	if err != nil {
-		cfqdn, err := LookupCname(fqdn, serverAddr)
+		cfqdn, cnameAnswer, err := lookupCname(fqdn, serverAddr)
 		if err != nil {
 			return task, results, err
 		}
-		ip, err = LookupName(cfqdn, serverAddr)
+		ip, answer, err = lookupName(cfqdn, serverAddr)
 		if err != nil {
 			return task, results, err
 		}
 		if ip == blacklist {
-			return task, results, fmt.Errorf("%v: returned IP in blackslist", ip)
+			return task, results, fmt.Errorf("%v: returned IP in blacklist: %w", ip, ErrNotFound)
 		}
-		results = append(results, Result{Source: "Dictionary-CNAME", IP: ip, Hostname: fqdn}, Result{Source: "Dictionary-CNAME", IP: ip, Hostname: cfqdn})
+		results = append(results, Result{Source: "Dictionary-CNAME", IP: ip, Hostname: fqdn, Meta: cnameAnswer}, Result{Source: "Dictionary-CNAME", IP: ip, Hostname: cfqdn, Meta: answer})
 		return task, results, nil
 	}
 	if ip == blacklist {
-		return task, results, fmt.Errorf("%v: returned IP in blacklist", ip)
+		return task, results, fmt.Errorf("%v: returned IP in blacklist: %w", ip, ErrNotFound)
 	}
-	results = append(results, Result{Source: task, IP: ip, Hostname: fqdn})
+	results = append(results, Result{Source: task, IP: ip, Hostname: fqdn, Meta: answer})
 	return task, results, nil
 }
 
@@ -55,13 +63,13 @@ func Dictionary6(domain, subname, blacklist, serverAddr string) (string, Results
 	task := "Dictionary IPv6"
 	results := Results{}
 	fqdn := subname + "." + domain
-	ip, err := LookupName6(fqdn, serverAddr)
+	ip, answer, err := lookupName6(fqdn, serverAddr)
 	if err != nil {
 		return task, results, err
 	}
 	if ip == blacklist {
-		return task, results, fmt.Errorf("%v: returned IP in blacklist", ip)
+		return task, results, fmt.Errorf("%v: returned IP in blacklist: %w", ip, ErrNotFound)
 	}
-	results = append(results, Result{Source: task, IP: ip, Hostname: fqdn})
+	results = append(results, Result{Source: task, IP: ip, Hostname: fqdn, Meta: answer})
 	return task, results, nil
 }
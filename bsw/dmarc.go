@@ -0,0 +1,115 @@
+package bsw
+
+import (
+	"strings"
+)
+
+// Parses the tags of a DMARC record. Returns nil if record is not a DMARC record.
+func parseDMARC(record string) map[string]string {
+	tags := make(map[string]string)
+	for _, part := range strings.Split(record, ";") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		tags[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.TrimSpace(kv[1])
+	}
+	if !strings.EqualFold(tags["v"], "DMARC1") {
+		return nil
+	}
+	return tags
+}
+
+// Returns the domains of the mailto URIs in a DMARC rua or ruf tag, dropping any size limit.
+func dmarcReportDomains(uris string) []string {
+	domains := []string{}
+	for _, uri := range strings.Split(uris, ",") {
+		uri = strings.TrimSpace(uri)
+		if !strings.HasPrefix(strings.ToLower(uri), "mailto:") {
+			continue
+		}
+		if i := strings.Index(uri, "!"); i >= 0 {
+			uri = uri[:i]
+		}
+		i := strings.LastIndex(uri, "@")
+		if i < 0 {
+			continue
+		}
+		if d := strings.ToLower(strings.TrimRight(uri[i+1:], ".")); d != "" {
+			domains = append(domains, d)
+		}
+	}
+	return domains
+}
+
+// DMARC looks up the DMARC record of a domain and returns the domains that aggregate (rua) and
+// forensic (ruf) reports are sent to, with their ip when they resolve. The report type and the
+// domain's policy are recorded in "dmarc" and "dmarc_policy" metadata.
+func DMARC(domain, serverAddr string) (string, Results, error) {
+	task := "dmarc"
+	results := Results{}
+	records, err := LookupTXT("_dmarc."+domain, serverAddr)
+	if err != nil {
+		return task, results, err
+	}
+	seen := make(map[string]bool)
+	for _, record := range records {
+		tags := parseDMARC(record)
+		if tags == nil {
+			continue
+		}
+		for _, tag := range []string{"rua", "ruf"} {
+			for _, host := range dmarcReportDomains(tags[tag]) {
+				if seen[tag+host] {
+					continue
+				}
+				seen[tag+host] = true
+				meta := map[string]string{"dmarc": tag, "dmarc_policy": tags["p"]}
+				ip, answer, err := lookupNameOrCname(host, serverAddr)
+				if err == nil {
+					meta = mergeMeta(meta, answer)
+				}
+				results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: meta})
+			}
+		}
+	}
+	return task, results, nil
+}
+
+// DKIM looks up the DKIM key record of each selector for a domain, returning a result for
+// each selector that has one. Selectors default to the built in list of common selectors.
+// The selector is recorded in "dkim_selector" metadata, and the target of a CNAME, which
+// often names the mail provider, in "cname".
+func DKIM(domain string, selectors []string, serverAddr string) (string, Results, error) {
+	task := "dkim"
+	results := Results{}
+	if selectors == nil {
+		var err error
+		if selectors, err = readWordlist("dkim"); err != nil {
+			return task, results, err
+		}
+	}
+	for _, selector := range selectors {
+		fqdn := selector + "._domainkey." + domain
+		records, answer, err := lookupTXT(fqdn, serverAddr)
+		if err != nil {
+			continue
+		}
+		found := false
+		for _, record := range records {
+			if strings.Contains(record, "p=") || strings.Contains(strings.ToLower(record), "v=dkim1") {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		meta := map[string]string{"dkim_selector": selector}
+		if target, err := LookupCname(fqdn, serverAddr); err == nil && target != "" {
+			meta["cname"] = target
+		}
+		results = append(results, Result{Source: task, Hostname: fqdn, Meta: mergeMeta(meta, answer)})
+	}
+	return task, results, nil
+}
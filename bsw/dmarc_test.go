@@ -0,0 +1,44 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestParseDMARCOffline(t *testing.T) {
+	tags := parseDMARC("v=DMARC1; p=reject; rua=mailto:dmarc@Reports.example.net,mailto:agg@example.com!10m; ruf=mailto:forensic@example.org")
+	if tags == nil || tags["p"] != "reject" {
+		t.Fatalf("unexpected tags %v", tags)
+	}
+	rua := dmarcReportDomains(tags["rua"])
+	if len(rua) != 2 || rua[0] != "reports.example.net" || rua[1] != "example.com" {
+		t.Errorf("unexpected rua domains %v", rua)
+	}
+	if ruf := dmarcReportDomains(tags["ruf"]); len(ruf) != 1 || ruf[0] != "example.org" {
+		t.Errorf("unexpected ruf domains %v", ruf)
+	}
+	if parseDMARC("v=spf1 -all") != nil {
+		t.Error("non DMARC record was parsed")
+	}
+}
+
+func TestDMARC(t *testing.T) {
+	_, results, err := DMARC("google.com", "8.8.8.8")
+	if err != nil {
+		t.Error("error returned from DMARC")
+		t.Log(err)
+	}
+	if len(results) < 1 {
+		t.Error("no results from DMARC")
+	}
+}
+
+func TestDKIM(t *testing.T) {
+	_, results, err := DKIM("google.com", []string{"20161025"}, "8.8.8.8")
+	if err != nil {
+		t.Error("error returned from DKIM")
+		t.Log(err)
+	}
+	if len(results) != 1 || results[0].Meta["dkim_selector"] != "20161025" {
+		t.Error("DKIM selector was not confirmed")
+	}
+}
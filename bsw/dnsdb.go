@@ -0,0 +1,118 @@
+package bsw
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const dnsdbURL = "https://api.dnsdb.info/lookup"
+
+var dnsdbSource = newHTTPSource("dnsdb", 0)
+
+// DNSDBWindow restricts DNSDB lookups to records observed within a time window. Both values are
+// unix timestamps and are ignored when zero.
+type DNSDBWindow struct {
+	After  int64
+	Before int64
+}
+
+type dnsdbRecord struct {
+	RRName string          `json:"rrname"`
+	RRType string          `json:"rrtype"`
+	RData  json.RawMessage `json:"rdata"`
+}
+
+// Returns rdata as a list, rrset lookups return an array and rdata lookups a single string.
+func (r dnsdbRecord) values() []string {
+	var list []string
+	if err := json.Unmarshal(r.RData, &list); err == nil {
+		return list
+	}
+	var s string
+	if err := json.Unmarshal(r.RData, &s); err == nil {
+		return []string{s}
+	}
+	return nil
+}
+
+// Performs a DNSDB lookup and calls fn for each record in the newline delimited JSON response.
+func dnsdbLookup(path, key string, window DNSDBWindow, fn func(r dnsdbRecord)) error {
+	params := url.Values{}
+	if window.After != 0 {
+		params.Set("time_last_after", strconv.FormatInt(window.After, 10))
+	}
+	if window.Before != 0 {
+		params.Set("time_first_before", strconv.FormatInt(window.Before, 10))
+	}
+	u := dnsdbURL + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-API-Key", key)
+	req.Header.Set("Accept", "application/json")
+	return dnsdbSource.call(func() error {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := responseError(resp); err != nil {
+			return err
+		}
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			r := dnsdbRecord{}
+			if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+				continue
+			}
+			fn(r)
+		}
+		return scanner.Err()
+	})
+}
+
+// DNSDBDomain uses Farsight DNSDB's rrset lookup to find A and AAAA records observed for a domain
+// and its subdomains.
+func DNSDBDomain(domain, key string, window DNSDBWindow) (string, Results, error) {
+	task := "dnsdb"
+	results := Results{}
+	for _, name := range []string{domain, "*." + domain} {
+		for _, rrtype := range []string{"A", "AAAA"} {
+			err := dnsdbLookup("/rrset/name/"+name+"/"+rrtype, key, window, func(r dnsdbRecord) {
+				hostname := strings.ToLower(strings.TrimSuffix(r.RRName, "."))
+				for _, ip := range r.values() {
+					results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+				}
+			})
+			if err != nil && Classify(err) != ErrNotFound {
+				return task, results, err
+			}
+		}
+	}
+	return task, results, nil
+}
+
+// DNSDBIP uses Farsight DNSDB's rdata lookup to find hostnames that have resolved to an IP.
+func DNSDBIP(ip, key string, window DNSDBWindow) (string, Results, error) {
+	task := "dnsdb"
+	results := Results{}
+	err := dnsdbLookup("/rdata/ip/"+ip, key, window, func(r dnsdbRecord) {
+		if r.RRType != "A" && r.RRType != "AAAA" {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(strings.TrimSuffix(r.RRName, "."))})
+	})
+	if err != nil && Classify(err) != ErrNotFound {
+		return task, results, err
+	}
+	return task, results, nil
+}
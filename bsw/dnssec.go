@@ -0,0 +1,226 @@
+package bsw
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+// DNSSEC validation statuses recorded in result metadata.
+const (
+	DNSSECSecure   = "secure"
+	DNSSECInsecure = "insecure"
+	DNSSECBogus    = "bogus"
+)
+
+// DS records for the root zone key signing keys, used as trust anchors.
+var rootAnchors = []*dns.DS{
+	{KeyTag: 20326, Algorithm: dns.RSASHA256, DigestType: dns.SHA256, Digest: "E06D44B80B8F1D39A95C0B0D7C65D08458E880409BBC683457104237C7F8EC8D"},
+	{KeyTag: 38696, Algorithm: dns.RSASHA256, DigestType: dns.SHA256, Digest: "683D2D0ACB8C9B712A1948B27F741219298D0A450D612C483AF444A4C0FB2B16"},
+}
+
+var dnssecEnabled bool
+
+// SetDNSSEC enables validation of answers from signed zones. The chain of trust is followed from
+// the signing zone to the root using the same resolver that answered, and the result is recorded
+// as "secure", "insecure" (no chain of trust exists), or "bogus" (a signature failed to verify).
+func SetDNSSEC(enabled bool) {
+	dnssecEnabled = enabled
+}
+
+// A validated zone and its keys.
+type dnssecZone struct {
+	status string
+	keys   []*dns.DNSKEY
+}
+
+var dnssecZones = struct {
+	sync.Mutex
+	zones map[string]*dnssecZone
+}{zones: make(map[string]*dnssecZone)}
+
+// Identifies an RRset by owner name and type.
+type rrsetKey struct {
+	name   string
+	rrtype uint16
+}
+
+// Returns the validation status of the answer section of in.
+func validateAnswer(in *dns.Msg, server string) string {
+	sigs := []*dns.RRSIG{}
+	rrsets := make(map[rrsetKey][]dns.RR)
+	for _, rr := range in.Answer {
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			sigs = append(sigs, sig)
+			continue
+		}
+		key := rrsetKey{strings.ToLower(rr.Header().Name), rr.Header().Rrtype}
+		rrsets[key] = append(rrsets[key], rr)
+	}
+	if len(sigs) == 0 {
+		return unsignedStatus(in.Question[0].Name, server)
+	}
+	status := DNSSECSecure
+	for key, rrset := range rrsets {
+		covering := []*dns.RRSIG{}
+		for _, sig := range sigs {
+			if sig.TypeCovered == key.rrtype && strings.EqualFold(sig.Hdr.Name, key.name) {
+				covering = append(covering, sig)
+			}
+		}
+		if len(covering) == 0 {
+			return DNSSECBogus
+		}
+		zone := dnssecZoneFor(covering[0].SignerName, server)
+		if zone.status != DNSSECSecure {
+			status = zone.status
+			continue
+		}
+		if !verifyRRset(rrset, covering, zone.keys) {
+			return DNSSECBogus
+		}
+	}
+	return status
+}
+
+// An unsigned answer is bogus when the zone it belongs to is secure, as the signatures have
+// been stripped.
+func unsignedStatus(name, server string) string {
+	m := &dns.Msg{}
+	m.SetQuestion(dns.Fqdn(name), dns.TypeSOA)
+	in, err := send(m, server)
+	if err != nil {
+		return DNSSECInsecure
+	}
+	for _, rr := range append(in.Answer, in.Ns...) {
+		if soa, ok := rr.(*dns.SOA); ok {
+			if dnssecZoneFor(soa.Hdr.Name, server).status == DNSSECSecure {
+				return DNSSECBogus
+			}
+			return DNSSECInsecure
+		}
+	}
+	return DNSSECInsecure
+}
+
+// Returns the validated keys for zone, following DS records to the root trust anchors.
+func dnssecZoneFor(zone, server string) *dnssecZone {
+	zone = strings.ToLower(dns.Fqdn(zone))
+	dnssecZones.Lock()
+	z, ok := dnssecZones.zones[zone]
+	dnssecZones.Unlock()
+	if ok {
+		return z
+	}
+	z = validateZone(zone, server)
+	dnssecZones.Lock()
+	dnssecZones.zones[zone] = z
+	dnssecZones.Unlock()
+	return z
+}
+
+func validateZone(zone, server string) *dnssecZone {
+	z := &dnssecZone{status: DNSSECInsecure}
+	keys, keySigs, err := dnssecQuery(zone, dns.TypeDNSKEY, server)
+	if err != nil {
+		return z
+	}
+	for _, rr := range keys {
+		if k, ok := rr.(*dns.DNSKEY); ok {
+			z.keys = append(z.keys, k)
+		}
+	}
+	if len(z.keys) == 0 {
+		return z
+	}
+
+	var anchors []*dns.DS
+	if zone == "." {
+		anchors = rootAnchors
+	} else {
+		ds, dsSigs, err := dnssecQuery(zone, dns.TypeDS, server)
+		if err != nil || len(ds) == 0 || len(dsSigs) == 0 {
+			return z
+		}
+		parent := dnssecZoneFor(dsSigs[0].SignerName, server)
+		if parent.status != DNSSECSecure {
+			z.status = parent.status
+			return z
+		}
+		if !verifyRRset(ds, dsSigs, parent.keys) {
+			z.status = DNSSECBogus
+			return z
+		}
+		for _, rr := range ds {
+			if d, ok := rr.(*dns.DS); ok {
+				anchors = append(anchors, d)
+			}
+		}
+	}
+
+	// The DNSKEY set must be signed by a key matching one of the anchors.
+	trusted := []*dns.DNSKEY{}
+	for _, k := range z.keys {
+		for _, a := range anchors {
+			if d := k.ToDS(a.DigestType); d != nil && d.KeyTag == a.KeyTag && strings.EqualFold(d.Digest, a.Digest) {
+				trusted = append(trusted, k)
+			}
+		}
+	}
+	if len(trusted) == 0 || !verifyRRset(keys, keySigs, trusted) {
+		z.status = DNSSECBogus
+		return z
+	}
+	z.status = DNSSECSecure
+	return z
+}
+
+// Queries server for the records of type qtype at name with the DO bit set, returning the
+// records and the signatures covering them.
+func dnssecQuery(name string, qtype uint16, server string) ([]dns.RR, []*dns.RRSIG, error) {
+	m := &dns.Msg{}
+	m.SetQuestion(name, qtype)
+	m.SetEdns0(4096, true)
+	in, err := send(m, server)
+	if err != nil {
+		return nil, nil, err
+	}
+	rrs := []dns.RR{}
+	sigs := []*dns.RRSIG{}
+	for _, rr := range in.Answer {
+		if !strings.EqualFold(rr.Header().Name, name) {
+			continue
+		}
+		if sig, ok := rr.(*dns.RRSIG); ok {
+			if sig.TypeCovered == qtype {
+				sigs = append(sigs, sig)
+			}
+			continue
+		}
+		if rr.Header().Rrtype == qtype {
+			rrs = append(rrs, rr)
+		}
+	}
+	return rrs, sigs, nil
+}
+
+// Returns true if any of sigs is a currently valid signature over rrset by one of keys.
+func verifyRRset(rrset []dns.RR, sigs []*dns.RRSIG, keys []*dns.DNSKEY) bool {
+	now := time.Now()
+	for _, sig := range sigs {
+		if !sig.ValidityPeriod(now) {
+			continue
+		}
+		for _, k := range keys {
+			if k.KeyTag() != sig.KeyTag || k.Algorithm != sig.Algorithm {
+				continue
+			}
+			if err := sig.Verify(k, rrset); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
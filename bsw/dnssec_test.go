@@ -0,0 +1,42 @@
+package bsw
+
+import (
+	"crypto"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+)
+
+func TestVerifyRRset(t *testing.T) {
+	key := &dns.DNSKEY{
+		Hdr:       dns.RR_Header{Name: "example.com.", Rrtype: dns.TypeDNSKEY, Class: dns.ClassINET, Ttl: 3600},
+		Flags:     257,
+		Protocol:  3,
+		Algorithm: dns.ECDSAP256SHA256,
+	}
+	priv, err := key.Generate(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := &dns.A{Hdr: dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 300}, A: net.ParseIP("192.0.2.1")}
+	sig := &dns.RRSIG{
+		Hdr:        dns.RR_Header{Name: "www.example.com.", Rrtype: dns.TypeRRSIG, Class: dns.ClassINET, Ttl: 300},
+		KeyTag:     key.KeyTag(),
+		SignerName: "example.com.",
+		Algorithm:  key.Algorithm,
+		Inception:  uint32(time.Now().Add(-time.Hour).Unix()),
+		Expiration: uint32(time.Now().Add(time.Hour).Unix()),
+	}
+	if err := sig.Sign(priv.(crypto.Signer), []dns.RR{a}); err != nil {
+		t.Fatal(err)
+	}
+	if !verifyRRset([]dns.RR{a}, []*dns.RRSIG{sig}, []*dns.DNSKEY{key}) {
+		t.Error("verifyRRset did not verify a valid signature")
+	}
+	spoofed := &dns.A{Hdr: a.Hdr, A: net.ParseIP("198.51.100.1")}
+	if verifyRRset([]dns.RR{spoofed}, []*dns.RRSIG{sig}, []*dns.DNSKEY{key}) {
+		t.Error("verifyRRset verified a signature over different data")
+	}
+}
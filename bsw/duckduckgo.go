@@ -0,0 +1,77 @@
+package bsw
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const duckDuckGoURL = "https://html.duckduckgo.com/html/"
+
+// DuckDuckGo serves a CAPTCHA to clients that search quickly, so requests are spaced out.
+var duckDuckGoSource = newHTTPSource("duckduckgo", 3*time.Second)
+
+const (
+	duckDuckGoPageSize = 30
+	duckDuckGoMaxPages = 5
+)
+
+// Returns the hostnames within domain linked from a DuckDuckGo HTML results page. Result links
+// go through a redirect with the destination in the uddg parameter.
+func duckDuckGoHostnames(doc *goquery.Document, domain string) []string {
+	names := []string{}
+	for _, v := range extract("duckduckgo", doc) {
+		name := extractedHostname(v)
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// DuckDuckGo uses DuckDuckGo's 'site:' search operator and scrapes the HTML results to find
+// hostnames for a domain, resolving each to an IP. This does not use an API.
+func DuckDuckGo(domain, server string) (string, Results, error) {
+	task := "duckduckgo"
+	results := Results{}
+	seen := map[string]bool{}
+	for page := 0; page < duckDuckGoMaxPages; page++ {
+		v := url.Values{}
+		v.Set("q", "site:"+domain)
+		if page > 0 {
+			v.Set("s", strconv.Itoa(page*duckDuckGoPageSize))
+			v.Set("dc", strconv.Itoa(page*duckDuckGoPageSize+1))
+		}
+		req, err := http.NewRequest("GET", duckDuckGoURL+"?"+v.Encode(), nil)
+		if err != nil {
+			return task, results, err
+		}
+		req.Header.Set("User-Agent", browserUserAgent)
+		req.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		doc, err := duckDuckGoSource.scrape(http.DefaultClient, req)
+		if err != nil {
+			return task, results, err
+		}
+		found := 0
+		for _, name := range duckDuckGoHostnames(doc, domain) {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			found++
+			ip, answer, err := lookupNameOrCname(name, server)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+		}
+		if doc.Selection.Find("a.result__a").Length() < duckDuckGoPageSize/2 || found == 0 {
+			break
+		}
+	}
+	return task, results, nil
+}
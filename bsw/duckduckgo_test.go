@@ -0,0 +1,27 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDuckDuckGoHostnamesOffline(t *testing.T) {
+	page := `<div class="result">
+<a class="result__a" href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fwww.example.com%2Fabout&amp;rut=x">About</a>
+<a class="result__url" href="#">mail.example.com/login</a>
+</div>
+<div class="result">
+<a class="result__a" href="https://example.org/">Other</a>
+<a class="result__url" href="#">notexample.com</a>
+</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := duckDuckGoHostnames(doc, "example.com")
+	if len(names) != 2 || names[0] != "www.example.com" || names[1] != "mail.example.com" {
+		t.Errorf("unexpected hostnames %v", names)
+	}
+}
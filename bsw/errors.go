@@ -0,0 +1,108 @@
+package bsw
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Categories of errors returned by tasks. Errors returned by tasks wrap one of these when the
+// cause is known, use errors.Is or Classify to check for them.
+var (
+	// ErrRateLimited is returned when a source has throttled requests. The request may succeed later.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrAuth is returned when a source rejected the provided credentials. No request will succeed.
+	ErrAuth = errors.New("authentication failed")
+	// ErrNotFound is returned when a source or DNS server has no records for the request.
+	ErrNotFound = errors.New("not found")
+	// ErrTimeout is returned when a request timed out. The request may succeed if retried.
+	ErrTimeout = errors.New("timeout")
+	// ErrCircuitOpen is returned without a request when a source has failed repeatedly.
+	ErrCircuitOpen = errors.New("circuit open")
+)
+
+// Classify returns the category of err, one of ErrRateLimited, ErrAuth, ErrNotFound,
+// ErrTimeout, or ErrCircuitOpen. Network timeouts are classified as ErrTimeout. If err does not fall into a
+// category, nil is returned.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, c := range []error{ErrRateLimited, ErrAuth, ErrNotFound, ErrTimeout, ErrCircuitOpen} {
+		if errors.Is(err, c) {
+			return c
+		}
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return ErrTimeout
+	}
+	return nil
+}
+
+// RateLimitError is returned when a source throttled a request. It wraps ErrRateLimited.
+// RetryAfter is how long the source asked to wait before retrying, zero if it did not say.
+// Blocked is set when a scraper was served a CAPTCHA or block page.
+type RateLimitError struct {
+	Status     string
+	RetryAfter time.Duration
+	Blocked    bool
+}
+
+func (e *RateLimitError) Error() string {
+	if e.Blocked {
+		return e.Status + ": CAPTCHA or block page: " + ErrRateLimited.Error()
+	}
+	return e.Status + ": " + ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error { return ErrRateLimited }
+
+// RetryAfter returns how long a source asked to wait before retrying after err, or zero.
+func RetryAfter(err error) time.Duration {
+	var e *RateLimitError
+	if errors.As(err, &e) {
+		return e.RetryAfter
+	}
+	return 0
+}
+
+// Parses a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(h); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil && time.Until(t) > 0 {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// Returns an error wrapping the matching category if resp does not have a 2xx status code.
+// A 403 or 503 with a Retry-After header, or a 403 when no requests remain in the quota, is
+// treated as rate limiting rather than a rejected key or failure.
+func responseError(resp *http.Response) error {
+	retryAfter := resp.Header.Get("Retry-After")
+	throttled := retryAfter != "" || resp.Header.Get("X-RateLimit-Remaining") == "0"
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests,
+		resp.StatusCode == http.StatusForbidden && throttled,
+		resp.StatusCode == http.StatusServiceUnavailable && retryAfter != "":
+		return &RateLimitError{Status: resp.Status, RetryAfter: parseRetryAfter(retryAfter)}
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%s: %w", resp.Status, ErrAuth)
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", resp.Status, ErrNotFound)
+	case resp.StatusCode == http.StatusRequestTimeout || resp.StatusCode == http.StatusGatewayTimeout:
+		return fmt.Errorf("%s: %w", resp.Status, ErrTimeout)
+	}
+	return errors.New("unexpected response " + resp.Status)
+}
@@ -0,0 +1,45 @@
+package bsw
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestClassify(t *testing.T) {
+	tests := map[int]error{
+		http.StatusOK:              nil,
+		http.StatusUnauthorized:    ErrAuth,
+		http.StatusNotFound:        ErrNotFound,
+		http.StatusTooManyRequests: ErrRateLimited,
+		http.StatusGatewayTimeout:  ErrTimeout,
+		http.StatusBadGateway:      nil,
+	}
+	for code, category := range tests {
+		err := responseError(&http.Response{StatusCode: code, Status: http.StatusText(code)})
+		if c := Classify(err); c != category {
+			t.Errorf("Classify returned %v for status %d, expected %v", c, code, category)
+		}
+	}
+	if Classify(errors.New("no Answer")) != nil {
+		t.Error("Classify returned a category for an unknown error")
+	}
+}
+
+func TestResponseErrorRetryAfter(t *testing.T) {
+	resp := &http.Response{StatusCode: http.StatusForbidden, Status: "403 Forbidden", Header: http.Header{}}
+	resp.Header.Set("Retry-After", "30")
+	err := responseError(resp)
+	if Classify(err) != ErrRateLimited {
+		t.Error("responseError did not classify a 403 with Retry-After as rate limited")
+	}
+	if RetryAfter(err) != 30*time.Second {
+		t.Error("RetryAfter returned incorrect duration")
+		t.Log(RetryAfter(err))
+	}
+	resp.Header.Del("Retry-After")
+	if Classify(responseError(resp)) != ErrAuth {
+		t.Error("responseError did not classify a 403 as an authentication failure")
+	}
+}
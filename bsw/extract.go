@@ -0,0 +1,114 @@
+package bsw
+
+import (
+	"errors"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Extractor finds hostnames or URLs on a scraped page. Selector picks the elements and Attr
+// the attribute read from each, or its text when empty. When Pattern matches the value its
+// first submatch, or the whole match, is used instead, URL decoded.
+type Extractor struct {
+	Selector string `json:"selector"`
+	Attr     string `json:"attr,omitempty"`
+	Pattern  string `json:"pattern,omitempty"`
+}
+
+// The extractors used by each scraper, keyed by the scraper's flag name.
+var (
+	extractorsMu sync.RWMutex
+	extractors   = map[string][]Extractor{
+		"bing-html": {{Selector: "cite"}},
+		"baidu":     {{Selector: "[mu]", Attr: "mu"}, {Selector: ".c-showurl"}},
+		"yahoo":     {{Selector: "div.compTitle a", Attr: "href", Pattern: `/RU=([^/]+)/`}},
+		"duckduckgo": {
+			{Selector: "a.result__a", Attr: "href", Pattern: `uddg=([^&]+)`},
+			{Selector: ".result__url"},
+		},
+		"viewdns-html": {{Selector: viewDNSSelector}},
+		"robtex-html":  {{Selector: "#x_summary td:nth-child(1)"}},
+		"netcraft":     {{Selector: "table.results-table a.results-table__host", Attr: "href"}},
+		"he":           {{Selector: "#dns a[href]", Attr: "href", Pattern: `^/(?:dns|ip)/([^/?#]+)$`}},
+	}
+)
+
+// SetExtractors replaces the extractors used by a scraper, so that a change to a site's
+// markup can be worked around without rebuilding.
+func SetExtractors(source string, list []Extractor) error {
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	if _, ok := extractors[source]; !ok {
+		names := []string{}
+		for name := range extractors {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return errors.New("no scraper named \"" + source + "\" to set extractors for, scrapers are: " + strings.Join(names, ", "))
+	}
+	if len(list) == 0 {
+		return errors.New("no extractors given for " + source)
+	}
+	for _, e := range list {
+		if e.Selector == "" {
+			return errors.New("extractor for " + source + " has no selector")
+		}
+		if _, err := regexp.Compile(e.Pattern); err != nil {
+			return errors.New("extractor pattern for " + source + " is invalid " + err.Error())
+		}
+	}
+	extractors[source] = list
+	return nil
+}
+
+// Returns the values found on doc by the extractors of a scraper.
+func extract(source string, doc *goquery.Document) []string {
+	extractorsMu.RLock()
+	list := extractors[source]
+	extractorsMu.RUnlock()
+	values := []string{}
+	for _, e := range list {
+		var re *regexp.Regexp
+		if e.Pattern != "" {
+			re = regexp.MustCompile(e.Pattern)
+		}
+		doc.Selection.Find(e.Selector).Each(func(_ int, s *goquery.Selection) {
+			value := s.Text()
+			if e.Attr != "" {
+				var ok bool
+				if value, ok = s.Attr(e.Attr); !ok {
+					return
+				}
+			}
+			if re != nil {
+				if m := re.FindStringSubmatch(value); m != nil {
+					value = m[len(m)-1]
+					if decoded, err := url.QueryUnescape(value); err == nil {
+						value = decoded
+					}
+				}
+			}
+			values = append(values, strings.TrimSpace(value))
+		})
+	}
+	return values
+}
+
+// Returns the lower case hostname of a URL or hostname found by an extractor, which may be
+// missing a scheme or be truncated with an ellipsis.
+func extractedHostname(value string) string {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "..."))
+	if !strings.Contains(value, "://") {
+		value = "http://" + value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+}
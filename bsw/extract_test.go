@@ -0,0 +1,34 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestSetExtractorsOffline(t *testing.T) {
+	saved := extractors["bing-html"]
+	defer func() { extractors["bing-html"] = saved }()
+	if err := SetExtractors("nosuchsource", []Extractor{{Selector: "cite"}}); err == nil {
+		t.Error("SetExtractors accepted an unknown source")
+	}
+	if err := SetExtractors("bing-html", []Extractor{{Selector: "a", Pattern: "("}}); err == nil {
+		t.Error("SetExtractors accepted an invalid pattern")
+	}
+	if err := SetExtractors("bing-html", []Extractor{{Selector: "a.r", Attr: "href", Pattern: `u=([^&]+)`}}); err != nil {
+		t.Fatal(err)
+	}
+	page := `<a class="r" href="/ck?u=https%3A%2F%2Fwww.example.com%2F&amp;p=1">x</a><a class="r" href="https://Mail.Example.com/">y</a><cite>ignored.example.com</cite>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := extract("bing-html", doc)
+	if len(values) != 2 || extractedHostname(values[0]) != "www.example.com" || extractedHostname(values[1]) != "mail.example.com" {
+		t.Errorf("unexpected values %v", values)
+	}
+	if h := extractedHostname("www.example.com/path..."); h != "www.example.com" {
+		t.Errorf("unexpected hostname %q", h)
+	}
+}
@@ -0,0 +1,94 @@
+package bsw
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const facebookCTURL = "https://graph.facebook.com/v18.0/certificates"
+
+var facebookCTSource = newHTTPSource("fb-ct", 0)
+
+type facebookCTMessage struct {
+	Data []struct {
+		Domains []string `json:"domains"`
+	} `json:"data"`
+	Paging struct {
+		Next string `json:"next"`
+	} `json:"paging"`
+	Error *struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+		Code    int    `json:"code"`
+	} `json:"error"`
+}
+
+// FacebookCT uses the Facebook Graph API's certificates edge to find names on certificates
+// issued to a domain and its subdomains, following every page of results. Names that resolve
+// are returned.
+func FacebookCT(domain, accessToken, serverAddr string) (string, Results, error) {
+	task := "fb-ct"
+	results := Results{}
+	seen := make(map[string]bool)
+	v := url.Values{}
+	v.Set("query", domain)
+	v.Set("fields", "domains")
+	v.Set("limit", "1000")
+	v.Set("access_token", accessToken)
+	next := facebookCTURL + "?" + v.Encode()
+	for next != "" {
+		m := &facebookCTMessage{}
+		err := facebookCTSource.call(func() error {
+			resp, err := http.Get(next)
+			if err != nil {
+				return err
+			}
+			body, err := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			if err != nil {
+				return err
+			}
+			jsonErr := json.Unmarshal(body, m)
+			// Graph API throttling is reported with these error codes rather than a 429.
+			if m.Error != nil && (m.Error.Code == 4 || m.Error.Code == 17 || m.Error.Code == 32 || m.Error.Code == 613) {
+				return &RateLimitError{Status: "facebook: " + m.Error.Message}
+			}
+			if m.Error != nil && m.Error.Type == "OAuthException" {
+				return fmt.Errorf("facebook: %s: %w", m.Error.Message, ErrAuth)
+			}
+			if err := responseError(resp); err != nil {
+				return err
+			}
+			if jsonErr != nil {
+				return jsonErr
+			}
+			if m.Error != nil {
+				return fmt.Errorf("facebook: %s", m.Error.Message)
+			}
+			return nil
+		})
+		if err != nil {
+			return task, results, err
+		}
+		for _, d := range m.Data {
+			for _, name := range d.Domains {
+				name = strings.ToLower(strings.TrimPrefix(name, "*."))
+				if seen[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+					continue
+				}
+				seen[name] = true
+				ip, answer, err := lookupNameOrCname(name, serverAddr)
+				if err != nil || ip == "" {
+					continue
+				}
+				results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+			}
+		}
+		next = m.Paging.Next
+	}
+	return task, results, nil
+}
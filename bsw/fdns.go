@@ -0,0 +1,80 @@
+package bsw
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+type fdnsRecord struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// FDNS streams a gzip compressed Rapid7 Project Sonar forward DNS dataset, returning the A and
+// AAAA records for each domain and its subdomains. CNAME records are returned without an IP and
+// their target in metadata. The file is read once for all domains.
+func FDNS(path string, domains []string) (string, Results, error) {
+	task := "fdns"
+	results := Results{}
+	file, err := os.Open(path)
+	if err != nil {
+		return task, results, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return task, results, err
+	}
+	defer gz.Close()
+
+	needles := [][]byte{}
+	suffixes := []string{}
+	for _, d := range domains {
+		d = strings.ToLower(strings.TrimRight(d, "."))
+		needles = append(needles, []byte(d))
+		suffixes = append(suffixes, d)
+	}
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		// Most lines do not match, so avoid decoding them.
+		found := false
+		for _, n := range needles {
+			if bytes.Contains(line, n) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		r := fdnsRecord{}
+		if err := json.Unmarshal(line, &r); err != nil {
+			continue
+		}
+		name := strings.ToLower(strings.TrimRight(r.Name, "."))
+		inScope := false
+		for _, d := range suffixes {
+			if name == d || strings.HasSuffix(name, "."+d) {
+				inScope = true
+				break
+			}
+		}
+		if !inScope {
+			continue
+		}
+		switch r.Type {
+		case "a", "aaaa":
+			results = append(results, Result{Source: task, IP: r.Value, Hostname: name})
+		case "cname":
+			results = append(results, Result{Source: task, Hostname: name, Meta: map[string]string{"cname": strings.TrimRight(r.Value, ".")}})
+		}
+	}
+	return task, results, scanner.Err()
+}
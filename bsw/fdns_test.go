@@ -0,0 +1,36 @@
+package bsw
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFDNS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fdns.json.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	gz.Write([]byte(`{"timestamp":"1","name":"www.example.com","type":"a","value":"192.0.2.1"}
+{"timestamp":"1","name":"www.example.org","type":"a","value":"192.0.2.2"}
+{"timestamp":"1","name":"notexample.com","type":"a","value":"192.0.2.3"}
+{"timestamp":"1","name":"cdn.example.com","type":"cname","value":"example.cdn.invalid"}
+`))
+	gz.Close()
+	file.Close()
+
+	_, results, err := FDNS(path, []string{"example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatal("FDNS returned incorrect number of results")
+	}
+	if results[0].IP != "192.0.2.1" || results[1].Meta["cname"] != "example.cdn.invalid" {
+		t.Error("FDNS returned incorrect results")
+		t.Log(results)
+	}
+}
@@ -0,0 +1,94 @@
+package bsw
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+)
+
+const fofaURL = "https://fofa.info/api/v1/search/all"
+
+var fofaSource = newHTTPSource("fofa", 0)
+
+type fofaMessage struct {
+	Error   bool       `json:"error"`
+	ErrMsg  string     `json:"errmsg"`
+	Results [][]string `json:"results"`
+}
+
+// Runs a FOFA search for query, calling fn with the host and ip fields of each result.
+// Credentials are in the form email:key.
+func fofaSearch(query, credentials string, fn func(host, ip string)) error {
+	parts := strings.SplitN(credentials, ":", 2)
+	if len(parts) != 2 {
+		return errors.New("FOFA credentials must be in the form email:key")
+	}
+	v := url.Values{}
+	v.Set("email", parts[0])
+	v.Set("key", parts[1])
+	v.Set("qbase64", base64.StdEncoding.EncodeToString([]byte(query)))
+	v.Set("fields", "host,ip")
+	v.Set("size", "1000")
+	body, err := fofaSource.get(fofaURL + "?" + v.Encode())
+	if err != nil {
+		return err
+	}
+	m := &fofaMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return err
+	}
+	if m.Error {
+		if strings.Contains(strings.ToLower(m.ErrMsg), "key") || strings.Contains(strings.ToLower(m.ErrMsg), "email") {
+			return fmt.Errorf("fofa: %s: %w", m.ErrMsg, ErrAuth)
+		}
+		return errors.New("fofa: " + m.ErrMsg)
+	}
+	for _, r := range m.Results {
+		if len(r) < 2 {
+			continue
+		}
+		fn(fofaHostname(r[0]), r[1])
+	}
+	return nil
+}
+
+// FOFA returns hosts as a bare host, host:port, or a URL. This returns only the hostname.
+func fofaHostname(host string) string {
+	if i := strings.Index(host, "://"); i != -1 {
+		host = host[i+3:]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "/"))
+}
+
+// FOFADomain uses FOFA's search API and 'domain=' query to find ips and hostnames for a domain.
+func FOFADomain(domain, credentials string) (string, Results, error) {
+	task := "fofa"
+	results := Results{}
+	err := fofaSearch("domain=\""+domain+"\"", credentials, func(host, ip string) {
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	})
+	return task, results, err
+}
+
+// FOFAIP uses FOFA's search API and 'ip=' query to find hostnames for an IP.
+func FOFAIP(ip, credentials string) (string, Results, error) {
+	task := "fofa"
+	results := Results{}
+	err := fofaSearch("ip=\""+ip+"\"", credentials, func(host, hip string) {
+		if host == "" || host == ip || net.ParseIP(host) != nil || hip != ip {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	})
+	return task, results, err
+}
@@ -0,0 +1,17 @@
+package bsw
+
+import "testing"
+
+func TestFOFAHostname(t *testing.T) {
+	for in, want := range map[string]string{
+		"www.example.com":              "www.example.com",
+		"mail.example.com:8443":        "mail.example.com",
+		"https://VPN.example.com":      "vpn.example.com",
+		"http://dev.example.com:8080/": "dev.example.com",
+	} {
+		if got := fofaHostname(in); got != want {
+			t.Error("fofaHostname returned incorrect hostname")
+			t.Log(in, got)
+		}
+	}
+}
@@ -1,34 +1,44 @@
 package bsw
 
 import (
-	"errors"
+	"fmt"
+	"net"
+	"regexp"
 	"strings"
 
 	"github.com/miekg/dns"
 )
 
+var errNoAnswer = fmt.Errorf("no Answer: %w", ErrNotFound)
+
 // LookupMX returns all the mx servers for a domain.
 func LookupMX(domain, serverAddr string) ([]string, error) {
 	servers := []string{}
+	records, err := lookupMXRecords(domain, serverAddr)
+	for _, mx := range records {
+		servers = append(servers, mx.Mx)
+	}
+	return servers, err
+}
+
+// Returns the MX records for a domain.
+func lookupMXRecords(domain, serverAddr string) ([]*dns.MX, error) {
+	records := []*dns.MX{}
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(domain), dns.TypeMX)
-	in, err := dns.Exchange(m, serverAddr+":53")
+	in, _, err := exchange(m, serverAddr)
 	if err != nil {
-		return servers, err
+		return records, err
 	}
 	if len(in.Answer) < 1 {
-		return servers, errors.New("no Answer")
+		return records, errNoAnswer
 	}
 	for _, a := range in.Answer {
 		if mx, ok := a.(*dns.MX); ok {
-			parts := strings.Split(mx.Mx, " ")
-			if len(parts) < 1 {
-				continue
-			}
-			servers = append(servers, parts[len(parts)-1])
+			records = append(records, mx)
 		}
 	}
-	return servers, nil
+	return records, nil
 }
 
 // LookupNS returns the names servers for a domain.
@@ -36,12 +46,12 @@ func LookupNS(domain, serverAddr string) ([]string, error) {
 	servers := []string{}
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(domain), dns.TypeNS)
-	in, err := dns.Exchange(m, serverAddr+":53")
+	in, _, err := exchange(m, serverAddr)
 	if err != nil {
 		return servers, err
 	}
 	if len(in.Answer) < 1 {
-		return servers, errors.New("no Answer")
+		return servers, errNoAnswer
 	}
 	for _, a := range in.Answer {
 		if ns, ok := a.(*dns.NS); ok {
@@ -51,21 +61,53 @@ func LookupNS(domain, serverAddr string) ([]string, error) {
 	return servers, nil
 }
 
+// LookupTXT returns the TXT records for a domain, with the strings of each record joined.
+func LookupTXT(domain, serverAddr string) ([]string, error) {
+	records, _, err := lookupTXT(domain, serverAddr)
+	return records, err
+}
+
+// Returns the TXT records for a domain like LookupTXT, with metadata of the answer.
+func lookupTXT(domain, serverAddr string) ([]string, map[string]string, error) {
+	records := []string{}
+	m := &dns.Msg{}
+	m.SetQuestion(dns.Fqdn(domain), dns.TypeTXT)
+	in, meta, err := exchange(m, serverAddr)
+	if err != nil {
+		return records, nil, err
+	}
+	if len(in.Answer) < 1 {
+		return records, nil, errNoAnswer
+	}
+	for _, a := range in.Answer {
+		if txt, ok := a.(*dns.TXT); ok {
+			records = append(records, strings.Join(txt.Txt, ""))
+		}
+	}
+	return records, meta, nil
+}
+
 // LookupIP returns hostname from PTR record or error.
 func LookupIP(ip, serverAddr string) ([]string, error) {
+	names, _, err := lookupIP(ip, serverAddr)
+	return names, err
+}
+
+// Returns the hostnames from the PTR records of ip, with metadata of the answer.
+func lookupIP(ip, serverAddr string) ([]string, map[string]string, error) {
 	names := []string{}
 	m := &dns.Msg{}
 	ipArpa, err := dns.ReverseAddr(ip)
 	if err != nil {
-		return names, err
+		return names, nil, err
 	}
 	m.SetQuestion(ipArpa, dns.TypePTR)
-	in, err := dns.Exchange(m, serverAddr+":53")
+	in, meta, err := exchange(m, serverAddr)
 	if err != nil {
-		return names, err
+		return names, nil, err
 	}
 	if len(in.Answer) < 1 {
-		return names, errors.New("no Answer")
+		return names, nil, errNoAnswer
 	}
 
 	for _, a := range in.Answer {
@@ -78,79 +120,203 @@ func LookupIP(ip, serverAddr string) ([]string, error) {
 	}
 
 	if len(names) < 1 {
-		return names, errors.New("no PTR")
+		return names, nil, fmt.Errorf("no PTR: %w", ErrNotFound)
 	}
 
-	return names, nil
+	return names, meta, nil
 }
 
 // LookupName returns IPv4 address from A record or error.
 func LookupName(fqdn, serverAddr string) (string, error) {
+	ip, _, err := lookupName(fqdn, serverAddr)
+	return ip, err
+}
+
+// Returns an IPv4 address from the A record of fqdn, with metadata of the answer.
+func lookupName(fqdn, serverAddr string) (string, map[string]string, error) {
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(fqdn), dns.TypeA)
-	in, err := dns.Exchange(m, serverAddr+":53")
+	in, meta, err := exchange(m, serverAddr)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if len(in.Answer) < 1 {
-		return "", errors.New("no Answer")
+		return "", nil, errNoAnswer
 	}
 	if a, ok := in.Answer[0].(*dns.A); ok {
 		ip := a.A.String()
-		return ip, nil
+		return ip, meta, nil
+	}
+	return "", nil, fmt.Errorf("no A record returned: %w", ErrNotFound)
+}
+
+// LookupAddrs returns every IPv4 and IPv6 address for fqdn, following CNAME records when the
+// server does not.
+func LookupAddrs(fqdn, serverAddr string) ([]string, error) {
+	ips, _, err := lookupAddrs(fqdn, serverAddr)
+	return ips, err
+}
+
+// Returns every address for fqdn like LookupAddrs, with metadata of the first answer that had
+// an address.
+func lookupAddrs(fqdn, serverAddr string) ([]string, map[string]string, error) {
+	ips := []string{}
+	var found map[string]string
+	seen := make(map[string]bool)
+	for _, qtype := range []uint16{dns.TypeA, dns.TypeAAAA} {
+		name := dns.Fqdn(fqdn)
+		// Bound the number of CNAMEs followed so that loops terminate.
+		for hop := 0; hop < 8; hop++ {
+			m := &dns.Msg{}
+			m.SetQuestion(name, qtype)
+			in, meta, err := exchange(m, serverAddr)
+			if err != nil {
+				break
+			}
+			target := ""
+			answered := false
+			for _, a := range in.Answer {
+				var ip string
+				switch v := a.(type) {
+				case *dns.A:
+					ip = v.A.String()
+				case *dns.AAAA:
+					ip = v.AAAA.String()
+				case *dns.CNAME:
+					target = v.Target
+				}
+				if ip == "" {
+					continue
+				}
+				answered = true
+				if !seen[ip] {
+					seen[ip] = true
+					ips = append(ips, ip)
+				}
+			}
+			if answered && found == nil {
+				found = meta
+			}
+			if answered || target == "" {
+				break
+			}
+			name = target
+		}
 	}
-	return "", errors.New("no A record returned")
+	if len(ips) < 1 {
+		return ips, nil, errNoAnswer
+	}
+	return ips, found, nil
+}
+
+// Confirm returns FCRDNSConfirmed if the hostname of r resolves to its IP, and
+// FCRDNSUnconfirmed otherwise.
+func Confirm(r Result, serverAddr string) string {
+	ip := net.ParseIP(r.IP)
+	if ip == nil || r.Hostname == "" {
+		return FCRDNSUnconfirmed
+	}
+	ips, _ := LookupAddrs(r.Hostname, serverAddr)
+	for _, a := range ips {
+		if ip.Equal(net.ParseIP(a)) {
+			return FCRDNSConfirmed
+		}
+	}
+	return FCRDNSUnconfirmed
+}
+
+// Returns an IPv4 address for fqdn, following a CNAME record if there is no A record, with
+// metadata of the answer that had the address.
+func lookupNameOrCname(fqdn, serverAddr string) (string, map[string]string, error) {
+	ip, meta, err := lookupName(fqdn, serverAddr)
+	if err == nil && ip != "" {
+		return ip, meta, nil
+	}
+	cfqdn, err := LookupCname(fqdn, serverAddr)
+	if err != nil {
+		return "", nil, err
+	}
+	return lookupName(cfqdn, serverAddr)
 }
 
 // LookupCname returns a fqdn address from CNAME record or error.
 func LookupCname(fqdn, serverAddr string) (string, error) {
+	name, _, err := lookupCname(fqdn, serverAddr)
+	return name, err
+}
+
+// Returns the target of the CNAME record of fqdn, with metadata of the answer.
+func lookupCname(fqdn, serverAddr string) (string, map[string]string, error) {
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(fqdn), dns.TypeCNAME)
-	in, err := dns.Exchange(m, serverAddr+":53")
+	in, meta, err := exchange(m, serverAddr)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if len(in.Answer) < 1 {
-		return "", errors.New("no Answer")
+		return "", nil, errNoAnswer
 	}
 	if a, ok := in.Answer[0].(*dns.CNAME); ok {
 		name := a.Target
-		return strings.TrimRight(name, "."), nil
+		return strings.TrimRight(name, "."), meta, nil
 	}
-	return "", errors.New("no CNAME record returned")
+	return "", nil, fmt.Errorf("no CNAME record returned: %w", ErrNotFound)
 }
 
 // LookupName6 returns a IPv6 address from AAAA record or error.
 func LookupName6(fqdn, serverAddr string) (string, error) {
+	ip, _, err := lookupName6(fqdn, serverAddr)
+	return ip, err
+}
+
+// Returns an IPv6 address from the AAAA record of fqdn, with metadata of the answer.
+func lookupName6(fqdn, serverAddr string) (string, map[string]string, error) {
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(fqdn), dns.TypeAAAA)
-	in, err := dns.Exchange(m, serverAddr+":53")
+	in, meta, err := exchange(m, serverAddr)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 	if len(in.Answer) < 1 {
-		return "", errors.New("no Answer")
+		return "", nil, errNoAnswer
 	}
 	if a, ok := in.Answer[0].(*dns.AAAA); ok {
 		ip := a.AAAA.String()
-		return ip, nil
+		return ip, meta, nil
 	}
-	return "", errors.New("no AAAA record returned")
+	return "", nil, fmt.Errorf("no AAAA record returned: %w", ErrNotFound)
 }
 
 // LookupSRV returns a hostname from SRV record or error.
 func LookupSRV(fqdn, dnsServer string) (string, error) {
 	m := &dns.Msg{}
 	m.SetQuestion(dns.Fqdn(fqdn), dns.TypeSRV)
-	in, err := dns.Exchange(m, dnsServer+":53")
+	in, _, err := exchange(m, dnsServer)
 	if err != nil {
 		return "", err
 	}
 	if len(in.Answer) < 1 {
-		return "", errors.New("no Answer")
+		return "", errNoAnswer
 	}
 	if a, ok := in.Answer[0].(*dns.SRV); ok {
 		return strings.TrimRight(a.Target, "."), nil
 	}
-	return "", errors.New("no SRV record returned")
+	return "", fmt.Errorf("no SRV record returned: %w", ErrNotFound)
+}
+
+var hostnameReg = regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9\-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9\-]{0,61}[a-z0-9]\b`)
+
+// Returns all unique lowercase hostnames found in a block of text.
+func hostnamesFromText(text string) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, h := range hostnameReg.FindAllString(text, -1) {
+		h = strings.ToLower(h)
+		if seen[h] {
+			continue
+		}
+		seen[h] = true
+		names = append(names, h)
+	}
+	return names
 }
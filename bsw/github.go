@@ -0,0 +1,92 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const gitHubSearchURL = "https://api.github.com/search/code"
+
+// GitHub's code search allows ten requests a minute, and returns at most 1000 results.
+var gitHubSource = newHTTPSource("github", 6*time.Second)
+
+const gitHubMaxPages = 10
+
+type gitHubSearchMessage struct {
+	TotalCount int `json:"total_count"`
+	Items      []struct {
+		TextMatches []struct {
+			Fragment string `json:"fragment"`
+		} `json:"text_matches"`
+	} `json:"items"`
+}
+
+// Requests a page of GitHub code search results. When the rate limit is exhausted GitHub gives
+// the time it resets rather than a Retry-After.
+func gitHubSearchPage(query, token string, page int) (*gitHubSearchMessage, error) {
+	req, err := http.NewRequest("GET", gitHubSearchURL+"?q="+url.QueryEscape(query)+"&per_page=100&page="+strconv.Itoa(page), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "token "+token)
+	req.Header.Set("Accept", "application/vnd.github.v3.text-match+json")
+	m := &gitHubSearchMessage{}
+	err = gitHubSource.call(func() error {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := responseError(resp); err != nil {
+			if e, ok := err.(*RateLimitError); ok && e.RetryAfter == 0 {
+				if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+					e.RetryAfter = time.Until(time.Unix(reset, 0))
+				}
+			}
+			return err
+		}
+		return json.NewDecoder(resp.Body).Decode(m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GitHub searches code on GitHub for a domain and extracts subdomains from the matched fragments,
+// returning those that resolve.
+func GitHub(domain, token, serverAddr string) (string, Results, error) {
+	task := "github"
+	results := Results{}
+	domainSet := make(map[string]bool)
+	for page := 1; page <= gitHubMaxPages; page++ {
+		m, err := gitHubSearchPage("\""+domain+"\"", token, page)
+		if err != nil {
+			return task, results, err
+		}
+		for _, item := range m.Items {
+			for _, match := range item.TextMatches {
+				for _, name := range hostnamesFromText(match.Fragment) {
+					name = strings.TrimPrefix(name, "*.")
+					if domainSet[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+						continue
+					}
+					domainSet[name] = true
+					ip, answer, err := lookupNameOrCname(name, serverAddr)
+					if err != nil || ip == "" {
+						continue
+					}
+					results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+				}
+			}
+		}
+		if len(m.Items) == 0 || page*100 >= m.TotalCount {
+			break
+		}
+	}
+	return task, results, nil
+}
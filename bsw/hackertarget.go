@@ -0,0 +1,67 @@
+package bsw
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const hackerTargetURL = "https://api.hackertarget.com"
+
+// The free HackerTarget API allows a small number of requests per second.
+var hackerTargetSource = newHTTPSource("hackertarget", time.Second).withHealthCheck(hackerTargetURL + "/")
+
+// Requests a HackerTarget API endpoint and returns the lines of the plain text response.
+func hackerTargetRequest(path, query string) ([]string, error) {
+	body, err := hackerTargetSource.get(hackerTargetURL + path + "?q=" + query)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(string(body))
+	switch {
+	case strings.HasPrefix(text, "API count exceeded"):
+		return nil, fmt.Errorf("hackertarget: %s: %w", text, ErrRateLimited)
+	case strings.HasPrefix(text, "No DNS A records found"), strings.HasPrefix(text, "No records found"):
+		return nil, nil
+	case strings.HasPrefix(text, "error"):
+		return nil, errors.New("hackertarget: " + text)
+	}
+	return strings.Split(text, "\n"), nil
+}
+
+// HackerTargetDomain uses hackertarget.com's hostsearch API to find ips and hostnames for a domain.
+func HackerTargetDomain(domain string) (string, Results, error) {
+	task := "hackertarget"
+	results := Results{}
+	lines, err := hackerTargetRequest("/hostsearch/", domain)
+	if err != nil {
+		return task, results, err
+	}
+	for _, line := range lines {
+		parts := strings.Split(strings.TrimSpace(line), ",")
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: parts[1], Hostname: strings.ToLower(parts[0])})
+	}
+	return task, results, nil
+}
+
+// HackerTargetIP uses hackertarget.com's reverseiplookup API to find hostnames for an IP.
+func HackerTargetIP(ip string) (string, Results, error) {
+	task := "hackertarget"
+	results := Results{}
+	lines, err := hackerTargetRequest("/reverseiplookup/", ip)
+	if err != nil {
+		return task, results, err
+	}
+	for _, line := range lines {
+		hostname := strings.ToLower(strings.TrimSpace(line))
+		if hostname == "" || hostname == ip {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+	}
+	return task, results, nil
+}
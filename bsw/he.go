@@ -0,0 +1,75 @@
+package bsw
+
+import (
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var heSource = newHTTPSource("he", 2*time.Second)
+
+// Returns the hostnames and ips linked from the DNS tab of a bgp.he.net page.
+func heLinks(doc *goquery.Document) ([]string, []string) {
+	hosts := []string{}
+	ips := []string{}
+	seen := make(map[string]bool)
+	for _, v := range extract("he", doc) {
+		v = strings.ToLower(strings.TrimSuffix(v, "."))
+		// Links that are not to a hostname or ip are left unmatched, with their path.
+		if v == "" || seen[v] || strings.Contains(v, "/") {
+			continue
+		}
+		seen[v] = true
+		if ip := net.ParseIP(v); ip != nil {
+			ips = append(ips, ip.String())
+		} else if host := extractedHostname(v); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts, ips
+}
+
+// HE scrapes the DNS tab of bgp.he.net's page for an ip to find the hostnames that resolve
+// to it or that it resolves to.
+func HE(ip string) (string, Results, error) {
+	task := "he"
+	results := Results{}
+	doc, err := heSource.scrapeURL("https://bgp.he.net/ip/" + url.PathEscape(ip))
+	if err != nil {
+		return task, results, err
+	}
+	hosts, _ := heLinks(doc)
+	for _, host := range hosts {
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	}
+	return task, results, nil
+}
+
+// HEDomain scrapes the DNS tab of bgp.he.net's page for a domain to find the ips it resolves
+// to, and the ips of the hostnames within the domain it lists.
+func HEDomain(domain, server string) (string, Results, error) {
+	task := "he"
+	results := Results{}
+	doc, err := heSource.scrapeURL("https://bgp.he.net/dns/" + url.PathEscape(domain))
+	if err != nil {
+		return task, results, err
+	}
+	hosts, ips := heLinks(doc)
+	for _, ip := range ips {
+		results = append(results, Result{Source: task, IP: ip, Hostname: domain})
+	}
+	for _, host := range hosts {
+		if host == domain || !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		ip, answer, err := lookupNameOrCname(host, server)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: answer})
+	}
+	return task, results, nil
+}
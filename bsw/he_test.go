@@ -0,0 +1,28 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestHELinksOffline(t *testing.T) {
+	page := `<div id="ipinfo"><a href="/AS15169">AS15169</a></div>
+<div id="dns">
+<a href="/dns/example.com">example.com</a> <a href="/ip/93.184.216.34">93.184.216.34</a>
+<a href="/dns/WWW.example.com.">www.example.com</a> <a href="/dns/example.com">example.com</a>
+<a href="/net/93.184.216.0/24">93.184.216.0/24</a> <a href="/ip/2606:2800:220:1::">2606:2800:220:1::</a>
+</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, ips := heLinks(doc)
+	if len(hosts) != 2 || hosts[0] != "example.com" || hosts[1] != "www.example.com" {
+		t.Errorf("unexpected hostnames %v", hosts)
+	}
+	if len(ips) != 2 || ips[0] != "93.184.216.34" || ips[1] != "2606:2800:220:1::" {
+		t.Errorf("unexpected ips %v", ips)
+	}
+}
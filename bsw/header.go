@@ -1,7 +1,7 @@
 package bsw
 
 import (
-	"crypto/tls"
+	"bufio"
 	"fmt"
 	"net"
 	"net/http"
@@ -15,17 +15,70 @@ import (
 func Headers(ip string, timeout int64) (string, Results, error) {
 	task := "Headers"
 	results := []Result{}
+	ports := map[string]int{"http": 80, "https": 443}
 	for _, proto := range []string{"http", "https"} {
 		host, err := hostnameFromHTTPLocationHeader(ip, proto, timeout)
 		if err != nil {
 			return task, results, err
 		} else if host != "" {
-			results = append(results, Result{Source: task, IP: ip, Hostname: host})
+			results = append(results, Result{Source: task, IP: ip, Hostname: host, Port: ports[proto], Protocol: "tcp"})
 		}
 	}
 	return task, results, nil
 }
 
+// HeadersTLS performs both the Headers and TLS tasks for an IP, using a single TLS connection on
+// port 443 to read the certificate and then make the https request. Results keep the source of
+// the task they would have come from.
+func HeadersTLS(ip string, timeout int64) (string, Results, error) {
+	task := "Headers and TLS Certificate"
+	results := Results{}
+	host, httpErr := hostnameFromHTTPLocationHeader(ip, "http", timeout)
+	if host != "" {
+		results = append(results, Result{Source: "Headers", IP: ip, Hostname: host, Port: 80, Protocol: "tcp"})
+	}
+	conn, err := tlsHandshake(net.JoinHostPort(ip, "443"), timeout)
+	if err != nil {
+		if len(results) > 0 {
+			return task, results, nil
+		}
+		return task, results, err
+	}
+	defer conn.Close()
+	results = append(results, tlsCertResults("TLS Certificate", ip, 443, conn)...)
+
+	res, err := httpGetOverConn(conn, "https://"+ip)
+	if err != nil {
+		return task, results, nil
+	}
+	if host, err := hostnameFromLocation(ip, res); err == nil {
+		results = append(results, Result{Source: "Headers", IP: ip, Hostname: host, Port: 443, Protocol: "tcp"})
+	}
+	if len(results) == 0 && httpErr != nil {
+		return task, results, httpErr
+	}
+	return task, results, nil
+}
+
+// Makes a GET request for u over an established connection. The body of the response has been
+// closed.
+func httpGetOverConn(conn net.Conn, u string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Close = true
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	res, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return nil, err
+	}
+	res.Body.Close()
+	return res, nil
+}
+
 // Performs http(s) request and parses possible 'Location' headers.
 func hostnameFromHTTPLocationHeader(ip, protocol string, timeout int64) (string, error) {
 	req, err := http.NewRequest("GET", protocol+"://"+ip, nil)
@@ -36,12 +89,18 @@ func hostnameFromHTTPLocationHeader(ip, protocol string, timeout int64) (string,
 		Dial: func(network, addr string) (net.Conn, error) {
 			return net.DialTimeout(network, addr, time.Duration(timeout)*time.Millisecond)
 		},
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		TLSClientConfig: clientTLSConfig,
 	}
 	res, err := tr.RoundTrip(req)
 	if err != nil {
 		return "", err
 	}
+	res.Body.Close()
+	return hostnameFromLocation(ip, res)
+}
+
+// Returns the hostname in the 'Location' header of res.
+func hostnameFromLocation(ip string, res *http.Response) (string, error) {
 	location := res.Header["Location"]
 	if location != nil {
 		u, err := url.Parse(location[0])
@@ -0,0 +1,103 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const hunterDomainSearchURL = "https://api.hunter.io/v2/domain-search"
+
+var hunterSource = newHTTPSource("hunterio", 0)
+
+const (
+	hunterPageSize = 100
+	hunterMaxPages = 10
+)
+
+type hunterMessage struct {
+	Data struct {
+		Emails []struct {
+			Value   string `json:"value"`
+			Sources []struct {
+				Domain string `json:"domain"`
+				URI    string `json:"uri"`
+			} `json:"sources"`
+		} `json:"emails"`
+	} `json:"data"`
+	Meta struct {
+		Results int `json:"results"`
+	} `json:"meta"`
+}
+
+// Requests a page of Hunter's domain search results.
+func hunterDomainSearchPage(domain, key string, offset int) (*hunterMessage, error) {
+	v := url.Values{}
+	v.Set("domain", domain)
+	v.Set("api_key", key)
+	v.Set("limit", strconv.Itoa(hunterPageSize))
+	v.Set("offset", strconv.Itoa(offset))
+	body, err := hunterSource.get(hunterDomainSearchURL + "?" + v.Encode())
+	if err != nil {
+		return nil, err
+	}
+	m := &hunterMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Returns the hostnames within domain from the email addresses in m and the pages they were
+// found on.
+func hunterHostnames(m *hunterMessage, domain string) []string {
+	names := []string{}
+	add := func(name string) {
+		name = strings.ToLower(strings.TrimSuffix(name, "."))
+		if name == domain || strings.HasSuffix(name, "."+domain) {
+			names = append(names, name)
+		}
+	}
+	for _, email := range m.Data.Emails {
+		if i := strings.LastIndex(email.Value, "@"); i != -1 {
+			add(email.Value[i+1:])
+		}
+		for _, source := range email.Sources {
+			add(source.Domain)
+			if u, err := url.Parse(source.URI); err == nil {
+				add(u.Hostname())
+			}
+		}
+	}
+	return names
+}
+
+// HunterIO uses Hunter's domain search API to find email addresses for a domain, returning the
+// hostnames of the addresses and of the pages they were found on that resolve.
+func HunterIO(domain, key, serverAddr string) (string, Results, error) {
+	task := "hunterio"
+	results := Results{}
+	domainSet := make(map[string]bool)
+	for page := 0; page < hunterMaxPages; page++ {
+		m, err := hunterDomainSearchPage(domain, key, page*hunterPageSize)
+		if err != nil {
+			return task, results, err
+		}
+		for _, name := range hunterHostnames(m, domain) {
+			if domainSet[name] {
+				continue
+			}
+			domainSet[name] = true
+			ip, answer, err := lookupNameOrCname(name, serverAddr)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+		}
+		if len(m.Data.Emails) < hunterPageSize || (page+1)*hunterPageSize >= m.Meta.Results {
+			break
+		}
+	}
+	return task, results, nil
+}
@@ -0,0 +1,32 @@
+package bsw
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestHunterHostnames(t *testing.T) {
+	m := &hunterMessage{}
+	data := `{"data": {"emails": [
+		{"value": "jane@mail.example.com", "sources": [
+			{"domain": "blog.example.com", "uri": "https://blog.example.com/team"},
+			{"domain": "other.org", "uri": "https://other.org/example"}
+		]},
+		{"value": "john@example.com", "sources": [{"domain": "example.com", "uri": "http://www.example.com:8080/about"}]}
+	]}, "meta": {"results": 2}}`
+	if err := json.Unmarshal([]byte(data), m); err != nil {
+		t.Fatal(err)
+	}
+	names := hunterHostnames(m, "example.com")
+	expected := []string{"mail.example.com", "blog.example.com", "blog.example.com", "example.com", "example.com", "www.example.com"}
+	if len(names) != len(expected) {
+		t.Fatal("hunterHostnames returned incorrect hostnames", names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Error("hunterHostnames returned incorrect hostnames")
+			t.Log(names)
+			break
+		}
+	}
+}
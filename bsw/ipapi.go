@@ -0,0 +1,91 @@
+package bsw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// The free ip-api.com endpoint is only served over http.
+const ipAPIBatchURL = "http://ip-api.com/batch?fields=status,country,city,isp,org,query"
+
+// ip-api.com allows 15 batch requests a minute, of at most 100 IPs each.
+var ipAPISource = newHTTPSource("geo", 4*time.Second)
+
+const ipAPIBatchSize = 100
+
+// GeoInfo is the location and hosting provider of an IP.
+type GeoInfo struct {
+	Country  string `json:"country"`
+	City     string `json:"city"`
+	Provider string `json:"provider"`
+}
+
+type ipAPIRecord struct {
+	Status  string `json:"status"`
+	Country string `json:"country"`
+	City    string `json:"city"`
+	ISP     string `json:"isp"`
+	Org     string `json:"org"`
+	Query   string `json:"query"`
+}
+
+// Returns the location of each successful record, keyed by IP. The organisation is preferred
+// to the ISP as the provider, since the ISP of a hosted IP is often an upstream carrier.
+func ipAPIGeoInfo(records []ipAPIRecord) map[string]GeoInfo {
+	byIP := make(map[string]GeoInfo)
+	for _, r := range records {
+		if r.Status != "success" {
+			continue
+		}
+		provider := r.Org
+		if provider == "" {
+			provider = r.ISP
+		}
+		byIP[r.Query] = GeoInfo{Country: r.Country, City: r.City, Provider: provider}
+	}
+	return byIP
+}
+
+// Requests the location of a batch of IPs.
+func ipAPIBatch(ips []string) ([]ipAPIRecord, error) {
+	data, err := json.Marshal(ips)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest("POST", ipAPIBatchURL, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	body, err := ipAPISource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return nil, err
+	}
+	records := []ipAPIRecord{}
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// IPAPIGeo uses ip-api.com's batch API to find the country, city, and hosting provider of
+// each IP. IPs that can not be located, such as private addresses, are omitted.
+func IPAPIGeo(ips []string) (map[string]GeoInfo, error) {
+	byIP := make(map[string]GeoInfo)
+	for i := 0; i < len(ips); i += ipAPIBatchSize {
+		end := i + ipAPIBatchSize
+		if end > len(ips) {
+			end = len(ips)
+		}
+		records, err := ipAPIBatch(ips[i:end])
+		if err != nil {
+			return byIP, err
+		}
+		for ip, info := range ipAPIGeoInfo(records) {
+			byIP[ip] = info
+		}
+	}
+	return byIP, nil
+}
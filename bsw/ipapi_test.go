@@ -0,0 +1,26 @@
+package bsw
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestIPAPIGeoInfoOffline(t *testing.T) {
+	body := `[{"status":"success","country":"Germany","city":"Falkenstein","isp":"Hetzner Online GmbH","org":"","query":"192.0.2.1"},
+{"status":"success","country":"United States","city":"Ashburn","isp":"Amazon.com, Inc.","org":"AWS EC2 (us-east-1)","query":"192.0.2.2"},
+{"status":"fail","query":"10.0.0.1"}]`
+	records := []ipAPIRecord{}
+	if err := json.Unmarshal([]byte(body), &records); err != nil {
+		t.Fatal(err)
+	}
+	byIP := ipAPIGeoInfo(records)
+	if len(byIP) != 2 {
+		t.Fatalf("expected 2 located IPs, got %d", len(byIP))
+	}
+	if byIP["192.0.2.1"].Provider != "Hetzner Online GmbH" {
+		t.Errorf("provider should fall back to the ISP, got %q", byIP["192.0.2.1"].Provider)
+	}
+	if g := byIP["192.0.2.2"]; g.Country != "United States" || g.City != "Ashburn" || g.Provider != "AWS EC2 (us-east-1)" {
+		t.Errorf("unexpected location %+v", g)
+	}
+}
@@ -0,0 +1,63 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const ipinfoDomainsURL = "https://ipinfo.io/domains/"
+
+var ipinfoSource = newHTTPSource("ipinfo", 0)
+
+const (
+	ipinfoPageSize = 1000
+	ipinfoMaxPages = 10
+)
+
+type ipinfoDomainsMessage struct {
+	IP      string   `json:"ip"`
+	Total   int      `json:"total"`
+	Domains []string `json:"domains"`
+}
+
+// Requests a page of the domains hosted on ip.
+func ipinfoDomainsPage(ip, token string, page int) (*ipinfoDomainsMessage, error) {
+	v := url.Values{}
+	v.Set("token", token)
+	v.Set("page", strconv.Itoa(page))
+	v.Set("limit", strconv.Itoa(ipinfoPageSize))
+	body, err := ipinfoSource.get(ipinfoDomainsURL + ip + "?" + v.Encode())
+	if err != nil {
+		return nil, err
+	}
+	m := &ipinfoDomainsMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// IPInfo uses IPinfo's hosted domains API to find the domains hosted on an IP. At most
+// ipinfoMaxPages are requested.
+func IPInfo(ip, token string) (string, Results, error) {
+	task := "ipinfo"
+	results := Results{}
+	for page := 0; page < ipinfoMaxPages; page++ {
+		m, err := ipinfoDomainsPage(ip, token, page)
+		if err != nil {
+			if Classify(err) == ErrNotFound {
+				break
+			}
+			return task, results, err
+		}
+		for _, d := range m.Domains {
+			results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(strings.TrimSuffix(d, "."))})
+		}
+		if len(m.Domains) < ipinfoPageSize || (page+1)*ipinfoPageSize >= m.Total {
+			break
+		}
+	}
+	return task, results, nil
+}
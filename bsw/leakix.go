@@ -0,0 +1,125 @@
+package bsw
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const leakIXURL = "https://leakix.net"
+
+var leakIXSource = newHTTPSource("leakix", 0)
+
+// LeakIX limits requests per API key, or per client without one, to about one a second.
+var (
+	leakIXLimitersMu sync.Mutex
+	leakIXLimiters   = make(map[string]*limiter)
+)
+
+func leakIXLimiter(key string) *limiter {
+	leakIXLimitersMu.Lock()
+	defer leakIXLimitersMu.Unlock()
+	l, ok := leakIXLimiters[key]
+	if !ok {
+		l = &limiter{interval: time.Second}
+		leakIXLimiters[key] = l
+	}
+	return l
+}
+
+type leakIXEvent struct {
+	IP   string `json:"ip"`
+	Host string `json:"host"`
+	Port string `json:"port"`
+}
+
+type leakIXMessage struct {
+	Services []leakIXEvent `json:"Services"`
+	Leaks    []leakIXEvent `json:"Leaks"`
+}
+
+// Requests a LeakIX host or domain page, calling fn with each service and leak event on it. A
+// target LeakIX has not seen is not an error. When throttled LeakIX gives the time to wait in
+// the x-limited-for header rather than a Retry-After.
+func leakIXSearch(path, key string, fn func(e leakIXEvent)) error {
+	m := &leakIXMessage{}
+	req, err := http.NewRequest("GET", leakIXURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if key != "" {
+		req.Header.Set("api-key", key)
+	}
+	leakIXLimiter(key).wait()
+	err = leakIXSource.call(func() error {
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := responseError(resp); err != nil {
+			if e, ok := err.(*RateLimitError); ok && e.RetryAfter == 0 {
+				if d, err := time.ParseDuration(resp.Header.Get("x-limited-for")); err == nil {
+					e.RetryAfter = d
+				}
+			}
+			return err
+		}
+		return json.NewDecoder(resp.Body).Decode(m)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range append(m.Services, m.Leaks...) {
+		e.Host = strings.ToLower(strings.TrimSuffix(e.Host, "."))
+		fn(e)
+	}
+	return nil
+}
+
+// Returns a result for a LeakIX event, keeping the port the service was seen on.
+func leakIXResult(task string, e leakIXEvent) Result {
+	r := Result{Source: task, IP: e.IP, Hostname: e.Host}
+	if port, err := strconv.Atoi(e.Port); err == nil && port > 0 {
+		r.Port = port
+		r.Protocol = "tcp"
+	}
+	return r
+}
+
+// LeakIXDomain uses LeakIX's domain endpoint to find ips and hostnames for a domain and its
+// subdomains. The key is optional.
+func LeakIXDomain(domain, key string) (string, Results, error) {
+	task := "leakix"
+	results := Results{}
+	err := leakIXSearch("/domain/"+url.PathEscape(domain), key, func(e leakIXEvent) {
+		if e.IP == "" || (e.Host != domain && !strings.HasSuffix(e.Host, "."+domain)) {
+			return
+		}
+		results = append(results, leakIXResult(task, e))
+	})
+	return task, results, err
+}
+
+// LeakIXIP uses LeakIX's host endpoint to find hostnames for an IP. The key is optional.
+func LeakIXIP(ip, key string) (string, Results, error) {
+	task := "leakix"
+	results := Results{}
+	err := leakIXSearch("/host/"+url.PathEscape(ip), key, func(e leakIXEvent) {
+		if e.Host == "" || net.ParseIP(e.Host) != nil || e.IP != ip {
+			return
+		}
+		results = append(results, leakIXResult(task, e))
+	})
+	return task, results, err
+}
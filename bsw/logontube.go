@@ -2,10 +2,10 @@ package bsw
 
 import (
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
 )
 
+var logontubeSource = newHTTPSource("logontube", 0).withHealthCheck("http://reverseip.logontube.com/")
+
 type logontubeMessage struct {
 	Hostip   string `json:"hostip"`
 	Hostname string `json:"hostname"`
@@ -16,15 +16,12 @@ type logontubeMessage struct {
 }
 
 // LogonTubeAPI sends either a domain or IP to logontube.com's API.
+//
+// Deprecated: logontube.com no longer responds, use ReverseIPAPI.
 func LogonTubeAPI(search string) (string, Results, error) {
 	task := "logontube.com API"
 	results := Results{}
-	resp, err := http.Get("http://reverseip.logontube.com/?url=" + search + "&output=json")
-	if err != nil {
-		return task, results, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := logontubeSource.get("http://reverseip.logontube.com/?url=" + search + "&output=json")
 	if err != nil {
 		return task, results, err
 	}
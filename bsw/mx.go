@@ -1,27 +1,32 @@
 package bsw
 
 import (
+	"strconv"
 	"strings"
 )
 
-// MX returns the A record for any MX records for a domain.
+// MX returns every A and AAAA record for each MX record of a domain. The MX priority is recorded
+// in each result's metadata.
 func MX(domain, serverAddr string) (string, Results, error) {
 	task := "mx"
 	results := Results{}
-	servers, err := LookupMX(domain, serverAddr)
+	records, err := lookupMXRecords(domain, serverAddr)
 	if err != nil {
 		return task, results, err
 	}
-	for _, s := range servers {
-		ip, err := LookupName(s, serverAddr)
-		if err != nil || ip == "" {
+	for _, mx := range records {
+		ips, answer, err := lookupAddrs(mx.Mx, serverAddr)
+		if err != nil {
 			continue
 		}
-		results = append(results, Result{
-			Source:   task,
-			IP:       ip,
-			Hostname: strings.TrimRight(s, "."),
-		})
+		for _, ip := range ips {
+			results = append(results, Result{
+				Source:   task,
+				IP:       ip,
+				Hostname: strings.TrimRight(mx.Mx, "."),
+				Meta:     mergeMeta(answer, map[string]string{"mx_priority": strconv.Itoa(int(mx.Preference))}),
+			})
+		}
 	}
 	return task, results, nil
 }
@@ -0,0 +1,111 @@
+package bsw
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const netcraftURL = "https://searchdns.netcraft.com/"
+
+// Netcraft blocks clients that page quickly, so requests are spaced out.
+var netcraftSource = newHTTPSource("netcraft", 2*time.Second)
+
+// Most pages of results that are requested.
+const netcraftMaxPages = 10
+
+// Returns the cookie answering Netcraft's JavaScript verification challenge, which is the hex
+// encoded SHA-1 of the URL decoded challenge cookie.
+func netcraftResponseCookie(challenge string) *http.Cookie {
+	if decoded, err := url.QueryUnescape(challenge); err == nil {
+		challenge = decoded
+	}
+	sum := sha1.Sum([]byte(challenge))
+	return &http.Cookie{Name: "netcraft_js_verification_response", Value: hex.EncodeToString(sum[:])}
+}
+
+// Requests a page of results with the cookies in client's jar.
+func netcraftPage(client *http.Client, u string) (*goquery.Document, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", browserUserAgent)
+	return netcraftSource.scrape(client, req)
+}
+
+// Returns the absolute URL of the next page of results, or an empty string on the last page.
+// The link carries the position in the results as its last and from parameters.
+func netcraftNextPage(doc *goquery.Document) string {
+	href, ok := doc.Selection.Find("a:contains('Next Page')").First().Attr("href")
+	if !ok {
+		return ""
+	}
+	base, _ := url.Parse(netcraftURL)
+	next, err := base.Parse(href)
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
+
+// Netcraft searches searchdns.netcraft.com for sites ending with the domain and scrapes the
+// HTML to find hostnames, resolving each to an IP. At most netcraftMaxPages are requested.
+func Netcraft(domain, server string) (string, Results, error) {
+	task := "netcraft"
+	results := Results{}
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return task, results, err
+	}
+	client := &http.Client{Jar: jar}
+	v := url.Values{}
+	v.Set("restriction", "site ends with")
+	v.Set("host", "*."+domain)
+	u := netcraftURL + "?" + v.Encode()
+	doc, err := netcraftPage(client, u)
+	if err != nil {
+		return task, results, err
+	}
+	// The first response sets a challenge cookie, and results are only served once it is
+	// answered.
+	base, _ := url.Parse(netcraftURL)
+	for _, c := range jar.Cookies(base) {
+		if c.Name == "netcraft_js_verification_challenge" {
+			jar.SetCookies(base, []*http.Cookie{netcraftResponseCookie(c.Value)})
+			if doc, err = netcraftPage(client, u); err != nil {
+				return task, results, err
+			}
+			break
+		}
+	}
+	seen := make(map[string]bool)
+	for page := 0; page < netcraftMaxPages; page++ {
+		for _, v := range extract("netcraft", doc) {
+			name := extractedHostname(v)
+			if seen[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+				continue
+			}
+			seen[name] = true
+			ip, answer, err := lookupNameOrCname(name, server)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+		}
+		next := netcraftNextPage(doc)
+		if next == "" {
+			break
+		}
+		if doc, err = netcraftPage(client, next); err != nil {
+			return task, results, err
+		}
+	}
+	return task, results, nil
+}
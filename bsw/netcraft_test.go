@@ -0,0 +1,34 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestNetcraftResponseCookieOffline(t *testing.T) {
+	// sha1("abc")
+	c := netcraftResponseCookie("ab%63")
+	if c.Name != "netcraft_js_verification_response" || c.Value != "a9993e364706816aba3e25717850c26c9cd0d89d" {
+		t.Errorf("unexpected cookie %s=%s", c.Name, c.Value)
+	}
+}
+
+func TestNetcraftPageOffline(t *testing.T) {
+	page := `<table class="results-table"><tr><td><a class="results-table__host" href="https://www.example.com/">www.example.com</a></td></tr>
+<tr><td><a class="results-table__host" href="http://mail.example.com">mail.example.com</a></td></tr></table>
+<a href="?host=*.example.com&amp;last=mail.example.com&amp;from=21&amp;restriction=site%20ends%20with">Next Page</a>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := extract("netcraft", doc)
+	if len(values) != 2 || extractedHostname(values[0]) != "www.example.com" || extractedHostname(values[1]) != "mail.example.com" {
+		t.Errorf("unexpected values %v", values)
+	}
+	next := netcraftNextPage(doc)
+	if !strings.HasPrefix(next, netcraftURL+"?") || !strings.Contains(next, "from=21") {
+		t.Errorf("unexpected next page %q", next)
+	}
+}
@@ -0,0 +1,95 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const netlasDomainsURL = "https://app.netlas.io/api/domains/"
+
+// Netlas allows one request a second, and returns 20 items a page.
+var netlasSource = newHTTPSource("netlas", time.Second)
+
+const (
+	netlasPageSize = 20
+	netlasMaxPages = 10
+)
+
+type netlasMessage struct {
+	Items []struct {
+		Data struct {
+			Domain string   `json:"domain"`
+			A      []string `json:"a"`
+		} `json:"data"`
+	} `json:"items"`
+}
+
+// Requests a page of the Netlas domains search for query.
+func netlasDomainsPage(query, key string, start int) (*netlasMessage, error) {
+	req, err := http.NewRequest("GET", netlasDomainsURL+"?q="+url.QueryEscape(query)+"&start="+strconv.Itoa(start), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-API-Key", key)
+	body, err := netlasSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return nil, err
+	}
+	m := &netlasMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Runs a Netlas domains search for query, calling fn with the hostname and each of its
+// addresses. At most netlasMaxPages are requested.
+func netlasSearch(query, key string, fn func(host, ip string)) error {
+	for page := 0; page < netlasMaxPages; page++ {
+		m, err := netlasDomainsPage(query, key, page*netlasPageSize)
+		if err != nil {
+			return err
+		}
+		for _, item := range m.Items {
+			host := strings.ToLower(strings.TrimSuffix(item.Data.Domain, "."))
+			for _, ip := range item.Data.A {
+				fn(host, ip)
+			}
+		}
+		if len(m.Items) < netlasPageSize {
+			break
+		}
+	}
+	return nil
+}
+
+// NetlasDomain uses Netlas's domains search and 'domain:' query to find ips and hostnames for
+// a domain.
+func NetlasDomain(domain, key string) (string, Results, error) {
+	task := "netlas"
+	results := Results{}
+	err := netlasSearch("domain:*."+domain, key, func(host, ip string) {
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	})
+	return task, results, err
+}
+
+// NetlasIP uses Netlas's domains search and 'a:' query to find hostnames for an IP.
+func NetlasIP(ip, key string) (string, Results, error) {
+	task := "netlas"
+	results := Results{}
+	err := netlasSearch("a:\""+ip+"\"", key, func(host, hip string) {
+		if host == "" || hip != ip {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	})
+	return task, results, err
+}
@@ -4,7 +4,20 @@ import (
 	"strings"
 )
 
-// NS returns the A record for any NS records for a domain.
+// Delegation looks up NS records for a name, which are only returned when it is the apex of a
+// zone. Each name server found is returned with the zone in its "delegation" metadata.
+func Delegation(zone, serverAddr string) (string, Results, error) {
+	task := "delegation"
+	_, results, err := NS(zone, serverAddr)
+	for i := range results {
+		results[i].Source = task
+		results[i].Meta["delegation"] = strings.ToLower(strings.TrimRight(zone, "."))
+	}
+	return task, results, err
+}
+
+// NS returns every A and AAAA record for each NS record of a domain. The zone served and whether
+// the name server is within it (in bailiwick) are recorded in each result's metadata.
 func NS(domain, serverAddr string) (string, Results, error) {
 	task := "ns"
 	results := Results{}
@@ -12,16 +25,25 @@ func NS(domain, serverAddr string) (string, Results, error) {
 	if err != nil {
 		return task, results, err
 	}
+	zone := strings.ToLower(strings.TrimRight(domain, "."))
 	for _, s := range servers {
-		ip, err := LookupName(s, serverAddr)
-		if err != nil || ip == "" {
+		hostname := strings.TrimRight(s, ".")
+		bailiwick := "out"
+		if name := strings.ToLower(hostname); name == zone || strings.HasSuffix(name, "."+zone) {
+			bailiwick = "in"
+		}
+		ips, answer, err := lookupAddrs(s, serverAddr)
+		if err != nil {
 			continue
 		}
-		results = append(results, Result{
-			Source:   task,
-			IP:       ip,
-			Hostname: strings.TrimRight(s, "."),
-		})
+		for _, ip := range ips {
+			results = append(results, Result{
+				Source:   task,
+				IP:       ip,
+				Hostname: hostname,
+				Meta:     mergeMeta(answer, map[string]string{"zone": zone, "bailiwick": bailiwick}),
+			})
+		}
 	}
 	return task, results, nil
 }
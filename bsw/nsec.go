@@ -0,0 +1,108 @@
+package bsw
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// The most names followed in an NSEC chain, in case a server never returns to the apex.
+const nsecMaxNames = 50000
+
+var (
+	errNSEC3  = errors.New("zone uses NSEC3, which hashes names and can not be walked")
+	errNoNSEC = fmt.Errorf("zone is not signed with NSEC: %w", ErrNotFound)
+	// Online signers answer with minimally covering records whose next name is made up, so
+	// the chain never reaches a real name.
+	errMinimalNSEC = errors.New("zone uses minimally covering NSEC records, which can not be walked")
+)
+
+// Returns the NSEC record owned by name, asking for it directly and, for servers that do not
+// answer NSEC queries, from the denial of the name that immediately follows it.
+func nsecRecord(name, serverAddr string) (*dns.NSEC, error) {
+	name = dns.Fqdn(name)
+	for _, q := range []struct {
+		name  string
+		qtype uint16
+	}{{name, dns.TypeNSEC}, {"\\000." + name, dns.TypeA}} {
+		m := &dns.Msg{}
+		m.SetQuestion(q.name, q.qtype)
+		m.SetEdns0(4096, true)
+		in, _, err := exchange(m, serverAddr)
+		if err != nil {
+			return nil, err
+		}
+		for _, rr := range append(in.Answer, in.Ns...) {
+			switch v := rr.(type) {
+			case *dns.NSEC:
+				if strings.EqualFold(v.Hdr.Name, name) {
+					return v, nil
+				}
+			case *dns.NSEC3:
+				return nil, errNSEC3
+			}
+		}
+	}
+	return nil, errNoNSEC
+}
+
+// Follows the NSEC chain of zone from its apex using next, which returns the NSEC record owned
+// by a name, until it returns to the apex or leaves the zone. Returns the records in order.
+func walkNSEC(zone string, next func(name string) (*dns.NSEC, error)) ([]*dns.NSEC, error) {
+	zone = strings.ToLower(dns.Fqdn(zone))
+	chain := []*dns.NSEC{}
+	seen := make(map[string]bool)
+	name := zone
+	for len(chain) < nsecMaxNames {
+		seen[name] = true
+		nsec, err := next(name)
+		if err != nil {
+			return chain, err
+		}
+		chain = append(chain, nsec)
+		following := strings.ToLower(dns.Fqdn(nsec.NextDomain))
+		if strings.HasPrefix(following, "\\000.") {
+			return chain, errMinimalNSEC
+		}
+		if seen[following] || !dns.IsSubDomain(zone, following) {
+			return chain, nil
+		}
+		name = following
+	}
+	return chain, errors.New("NSEC chain is longer than the limit of names")
+}
+
+// NSECWalk enumerates every name in a zone signed with DNSSEC NSEC records by following the
+// chain of records from the apex, each naming the next name in the zone. Names with A or AAAA
+// records are returned with each of their ips, and other names without an ip. The record types
+// at each name are recorded in "nsec_types" metadata. It fails for zones signed with NSEC3.
+func NSECWalk(domain, serverAddr string) (string, Results, error) {
+	task := "nsec-walk"
+	results := Results{}
+	chain, err := walkNSEC(domain, func(name string) (*dns.NSEC, error) { return nsecRecord(name, serverAddr) })
+	if len(chain) == 0 {
+		return task, results, err
+	}
+	for _, nsec := range chain {
+		host := strings.ToLower(strings.TrimRight(nsec.Hdr.Name, "."))
+		types := []string{}
+		hasAddr := false
+		for _, t := range nsec.TypeBitMap {
+			types = append(types, dns.TypeToString[t])
+			hasAddr = hasAddr || t == dns.TypeA || t == dns.TypeAAAA
+		}
+		meta := map[string]string{"nsec_types": strings.Join(types, " ")}
+		if hasAddr {
+			if ips, answer, err := lookupAddrs(host, serverAddr); err == nil && len(ips) > 0 {
+				for _, ip := range ips {
+					results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: mergeMeta(meta, answer)})
+				}
+				continue
+			}
+		}
+		results = append(results, Result{Source: task, Hostname: host, Meta: meta})
+	}
+	return task, results, err
+}
@@ -0,0 +1,209 @@
+package bsw
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Limits on collecting the NSEC3 chain of a zone: the most queries sent, and the most names
+// hashed looking for one in a gap of the chain before giving up.
+const (
+	nsec3MaxQueries = 10000
+	nsec3MaxHashes  = 1000000
+)
+
+var errNSEC = errors.New("zone uses NSEC, which can be walked with -nsec-walk")
+
+// NSEC3Zone is the NSEC3 chain of a zone, as hashes of its names along with the parameters
+// needed to crack them.
+type NSEC3Zone struct {
+	Zone       string
+	Salt       string
+	Iterations uint16
+	// Each hashed owner name and the next hashed owner name in the chain, in lower case.
+	Chain    map[string]string
+	Complete bool
+}
+
+// Hashes returns the unique hashes in the chain, sorted.
+func (z NSEC3Zone) Hashes() []string {
+	hashes := []string{}
+	for h := range z.Chain {
+		hashes = append(hashes, h)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// Returns whether hash is an owner in the chain or falls in the gap after one.
+func (z NSEC3Zone) covers(hash string) bool {
+	for owner, next := range z.Chain {
+		if hash == owner || hash == next {
+			return true
+		}
+		if owner < next && owner < hash && hash < next {
+			return true
+		}
+		// The last record in the chain wraps around to the first.
+		if owner >= next && (hash > owner || hash < next) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns whether the next hash of every record is the owner of another, closing the chain.
+func (z NSEC3Zone) closed() bool {
+	if len(z.Chain) == 0 {
+		return false
+	}
+	for _, next := range z.Chain {
+		if _, ok := z.Chain[next]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Adds the NSEC3 records in rrs to the chain, returning an error if the zone uses NSEC.
+func (z *NSEC3Zone) add(rrs []dns.RR) error {
+	for _, rr := range rrs {
+		switch v := rr.(type) {
+		case *dns.NSEC3:
+			if v.Hash != dns.SHA1 {
+				continue
+			}
+			if len(z.Chain) == 0 {
+				z.Salt, z.Iterations = v.Salt, v.Iterations
+			}
+			owner := strings.ToLower(strings.SplitN(v.Hdr.Name, ".", 2)[0])
+			z.Chain[owner] = strings.ToLower(v.NextDomain)
+		case *dns.NSEC:
+			return errNSEC
+		}
+	}
+	return nil
+}
+
+// Returns the hash of a name in the zone using the zone's parameters, in lower case.
+func (z NSEC3Zone) hash(name string) string {
+	return strings.ToLower(dns.HashName(dns.Fqdn(name), dns.SHA1, z.Iterations, z.Salt))
+}
+
+// NSEC3Collect collects the hashed names of a zone signed with DNSSEC NSEC3 records. Names that
+// hash into a gap of the chain collected so far are queried, and each denial returns the
+// records covering them, until the chain is closed or a limit is reached. The returned zone is
+// marked incomplete when a limit was reached first.
+func NSEC3Collect(domain, serverAddr string) (NSEC3Zone, error) {
+	zone := strings.ToLower(strings.TrimRight(domain, "."))
+	z := NSEC3Zone{Zone: zone, Chain: make(map[string]string)}
+	hashed := 0
+	for queries := 0; queries < nsec3MaxQueries; queries++ {
+		// Find a name that is not covered yet. Until the parameters are known any name will do.
+		name := ""
+		for ; hashed < nsec3MaxHashes; hashed++ {
+			candidate := strconv.FormatInt(int64(hashed), 36) + "." + zone
+			if len(z.Chain) == 0 || !z.covers(z.hash(candidate)) {
+				name = candidate
+				hashed++
+				break
+			}
+		}
+		if name == "" {
+			return z, nil
+		}
+		m := &dns.Msg{}
+		m.SetQuestion(dns.Fqdn(name), dns.TypeA)
+		m.SetEdns0(4096, true)
+		in, _, err := exchange(m, serverAddr)
+		if err != nil {
+			return z, err
+		}
+		before := len(z.Chain)
+		if err := z.add(in.Ns); err != nil {
+			return z, err
+		}
+		if len(z.Chain) == 0 {
+			return z, fmt.Errorf("zone is not signed with NSEC3: %w", ErrNotFound)
+		}
+		if z.closed() {
+			z.Complete = true
+			return z, nil
+		}
+		// A name the records should have covered was answered without them.
+		if len(z.Chain) == before && queries > 0 && !z.covers(z.hash(name)) {
+			return z, errors.New("server did not return NSEC3 records covering " + name)
+		}
+	}
+	return z, nil
+}
+
+// WriteHashcat writes the hashes of the zone in the format of hashcat's mode 8300, one line of
+// "<hash>:.<zone>:<salt>:<iterations>" per hash.
+func (z NSEC3Zone) WriteHashcat(w io.Writer) error {
+	for _, h := range z.Hashes() {
+		if _, err := fmt.Fprintf(w, "%s:.%s:%s:%d\n", h, z.Zone, z.Salt, z.Iterations); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJohn writes the hashes of the zone in the format of John the Ripper's nsec3 format, one
+// line of "$NSEC3$<iterations>$<salt>$<hash>$<zone>" per hash.
+func (z NSEC3Zone) WriteJohn(w io.Writer) error {
+	for _, h := range z.Hashes() {
+		if _, err := fmt.Fprintf(w, "$NSEC3$%d$%s$%s$%s\n", z.Iterations, z.Salt, h, z.Zone); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NSEC3Cracked converts cracked NSEC3 hashes into hostnames. Lines are in the format hashcat
+// writes cracked mode 8300 hashes, "<hash>:.<zone>:<salt>:<iterations>:<label>". Each label is
+// checked by hashing it again, and the hostnames are returned with each of their ips, or
+// without an ip if they do not resolve. The hash is recorded in "nsec3_hash" metadata.
+func NSEC3Cracked(lines []string, serverAddr string) (string, Results, error) {
+	task := "nsec3"
+	results := Results{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, ":", 5)
+		if len(fields) != 5 || !strings.HasPrefix(fields[1], ".") {
+			return task, results, errors.New("\"" + line + "\" is not a cracked NSEC3 hash")
+		}
+		iterations, err := strconv.ParseUint(fields[3], 10, 16)
+		if err != nil {
+			return task, results, errors.New("\"" + line + "\" is not a cracked NSEC3 hash")
+		}
+		z := NSEC3Zone{Zone: strings.ToLower(strings.Trim(fields[1], ".")), Salt: fields[2], Iterations: uint16(iterations)}
+		hash, label := strings.ToLower(fields[0]), strings.ToLower(fields[4])
+		host := label + "." + z.Zone
+		if label == "" {
+			host = z.Zone
+		}
+		if z.hash(host) != hash {
+			return task, results, errors.New("\"" + label + "\" does not match the hash " + hash)
+		}
+		meta := map[string]string{"nsec3_hash": hash}
+		ips, answer, err := lookupAddrs(host, serverAddr)
+		if err != nil || len(ips) == 0 {
+			results = append(results, Result{Source: task, Hostname: host, Meta: meta})
+			continue
+		}
+		for _, ip := range ips {
+			results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: mergeMeta(meta, answer)})
+		}
+	}
+	return task, results, nil
+}
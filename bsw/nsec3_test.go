@@ -0,0 +1,57 @@
+package bsw
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestNSEC3ZoneOffline(t *testing.T) {
+	z := NSEC3Zone{Zone: "example.com", Salt: "aabb", Iterations: 1, Chain: make(map[string]string)}
+	rrs := []dns.RR{
+		&dns.NSEC3{Hdr: dns.RR_Header{Name: "2T7B4G4VSA5SMI47K61MV5BV1A22BOJR.example.com."}, Hash: dns.SHA1, Iterations: 1, Salt: "aabb", NextDomain: "7UHB1NDI4G3N5D4P2SUFSTK70BB3DSB8"},
+		&dns.NSEC3{Hdr: dns.RR_Header{Name: "7UHB1NDI4G3N5D4P2SUFSTK70BB3DSB8.example.com."}, Hash: dns.SHA1, Iterations: 1, Salt: "aabb", NextDomain: "2T7B4G4VSA5SMI47K61MV5BV1A22BOJR"},
+	}
+	if err := z.add(rrs[:1]); err != nil {
+		t.Fatal(err)
+	}
+	if z.closed() {
+		t.Error("chain with one record should not be closed")
+	}
+	if !z.covers("3aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa") || z.covers("9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa") {
+		t.Error("unexpected coverage of the chain with one record")
+	}
+	z.add(rrs[1:])
+	if !z.closed() {
+		t.Error("chain should be closed")
+	}
+	// The last record wraps around to cover hashes after it and before the first.
+	if !z.covers("9aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa") || !z.covers("1aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa") {
+		t.Error("last record should wrap around the chain")
+	}
+	if err := z.add([]dns.RR{&dns.NSEC{Hdr: dns.RR_Header{Name: "example.com."}}}); err != errNSEC {
+		t.Errorf("expected NSEC error, got %v", err)
+	}
+
+	var hashcat, john bytes.Buffer
+	z.WriteHashcat(&hashcat)
+	z.WriteJohn(&john)
+	if hashcat.String() != "2t7b4g4vsa5smi47k61mv5bv1a22bojr:.example.com:aabb:1\n7uhb1ndi4g3n5d4p2sufstk70bb3dsb8:.example.com:aabb:1\n" {
+		t.Errorf("unexpected hashcat output %q", hashcat.String())
+	}
+	if !strings.HasPrefix(john.String(), "$NSEC3$1$aabb$2t7b4g4vsa5smi47k61mv5bv1a22bojr$example.com\n") {
+		t.Errorf("unexpected john output %q", john.String())
+	}
+}
+
+func TestNSEC3CrackedOffline(t *testing.T) {
+	hash := strings.ToLower(dns.HashName("www.example.com.", dns.SHA1, 1, "aabb"))
+	if _, _, err := NSEC3Cracked([]string{hash + ":.example.com:aabb:1:mail"}, "127.0.0.1"); err == nil {
+		t.Error("expected error for a label that does not match its hash")
+	}
+	if _, _, err := NSEC3Cracked([]string{hash + ":example.com:aabb"}, "127.0.0.1"); err == nil {
+		t.Error("expected error for a malformed line")
+	}
+}
@@ -0,0 +1,43 @@
+package bsw
+
+import (
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestWalkNSECOffline(t *testing.T) {
+	links := map[string]string{
+		"example.com.":      "mail.example.com.",
+		"mail.example.com.": "www.example.com.",
+		"www.example.com.":  "example.com.",
+	}
+	next := func(name string) (*dns.NSEC, error) {
+		return &dns.NSEC{Hdr: dns.RR_Header{Name: name}, NextDomain: links[name]}, nil
+	}
+	chain, err := walkNSEC("Example.com", next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chain) != 3 || chain[1].Hdr.Name != "mail.example.com." || chain[2].Hdr.Name != "www.example.com." {
+		t.Errorf("unexpected chain %v", chain)
+	}
+
+	minimal := func(name string) (*dns.NSEC, error) {
+		return &dns.NSEC{Hdr: dns.RR_Header{Name: name}, NextDomain: "\\000." + name}, nil
+	}
+	if _, err := walkNSEC("example.com", minimal); err != errMinimalNSEC {
+		t.Errorf("expected minimally covering error, got %v", err)
+	}
+}
+
+func TestNSECWalk(t *testing.T) {
+	_, results, err := NSECWalk("nsec.zonewalk.dev", "8.8.8.8")
+	if err != nil {
+		t.Error("error returned from NSECWalk")
+		t.Log(err)
+	}
+	if len(results) < 2 {
+		t.Error("expected more results from NSECWalk")
+	}
+}
@@ -0,0 +1,101 @@
+package bsw
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// NTP mode 6 control messages are spread out so that a large range is never flooded.
+var ntpLimiter = &limiter{interval: 200 * time.Millisecond}
+
+// System variables that contain version strings rather than names.
+var ntpIgnoredVars = map[string]bool{"version": true, "processor": true, "system": true}
+
+// NTP sends a mode 6 control 'readvar' request to an IP on port 123 and parses the refid and system
+// variables for hostnames. These are hints only and are tagged as low confidence.
+func NTP(ip string, timeout int64) (string, Results, error) {
+	task := "NTP (low confidence)"
+	results := Results{}
+	ntpLimiter.wait()
+	vars, err := ntpReadVar(ip+":123", timeout)
+	if err != nil {
+		return task, results, err
+	}
+	for _, name := range ntpHostnames(vars) {
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Port: 123, Protocol: "udp"})
+	}
+	return task, results, nil
+}
+
+// Sends a readvar request for the system variables and returns the reassembled variable list.
+func ntpReadVar(addr string, timeout int64) (string, error) {
+	conn, err := net.DialTimeout("udp", addr, time.Duration(timeout)*time.Millisecond)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(timeout) * time.Millisecond)); err != nil {
+		return "", err
+	}
+	// LI 0, version 2, mode 6, opcode 2 (readvar), sequence 1, association 0.
+	req := make([]byte, 12)
+	req[0] = 0x16
+	req[1] = 0x02
+	binary.BigEndian.PutUint16(req[2:], 1)
+	if _, err := conn.Write(req); err != nil {
+		return "", err
+	}
+	data := make(map[uint16][]byte)
+	buf := make([]byte, 1024)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return "", err
+		}
+		if n < 12 || buf[1]&0x80 == 0 {
+			return "", errors.New("invalid NTP control response")
+		}
+		offset := binary.BigEndian.Uint16(buf[8:])
+		count := int(binary.BigEndian.Uint16(buf[10:]))
+		if 12+count > n {
+			return "", errors.New("truncated NTP control response")
+		}
+		data[offset] = append([]byte{}, buf[12:12+count]...)
+		if buf[1]&0x20 == 0 {
+			break
+		}
+	}
+	vars := []byte{}
+	for offset := uint16(0); ; {
+		part, ok := data[offset]
+		if !ok {
+			break
+		}
+		vars = append(vars, part...)
+		offset += uint16(len(part))
+		if len(part) == 0 {
+			break
+		}
+	}
+	return string(vars), nil
+}
+
+// Parses a readvar variable list, returning hostnames found in the values.
+func ntpHostnames(vars string) []string {
+	values := []string{}
+	for _, kv := range strings.Split(vars, ",") {
+		parts := strings.SplitN(strings.TrimSpace(kv), "=", 2)
+		if len(parts) != 2 || ntpIgnoredVars[parts[0]] {
+			continue
+		}
+		v := strings.Trim(parts[1], "\"")
+		if net.ParseIP(v) != nil {
+			continue
+		}
+		values = append(values, v)
+	}
+	return hostnamesFromText(strings.Join(values, " "))
+}
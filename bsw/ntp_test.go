@@ -0,0 +1,19 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestNTPHostnames(t *testing.T) {
+	vars := `version="ntpd 4.2.6p5@1.2349-o Fri Apr 13 12:52:27 UTC 2018 (1)", processor="x86_64",
+system="Linux/3.10.0-1160.el7.x86_64", leap=0, stratum=3, precision=-24, rootdelay=31.893,
+refid=10.1.1.20, reftime=0xe2f7a3e1.6e2a1c14, peer=2641, host="ntp01.corp.example.com"`
+	names := ntpHostnames(vars)
+	if len(names) != 1 {
+		t.Fatal("ntpHostnames returned incorrect number of hostnames")
+	}
+	if names[0] != "ntp01.corp.example.com" {
+		t.Error("ntpHostnames returned incorrect hostname")
+		t.Log(names)
+	}
+}
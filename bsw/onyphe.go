@@ -0,0 +1,86 @@
+package bsw
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const onypheURL = "https://www.onyphe.io/api/v2"
+
+var onypheSource = newHTTPSource("onyphe", 0)
+
+type onypheMessage struct {
+	Error   int    `json:"error"`
+	Text    string `json:"text"`
+	Results []struct {
+		IP       string `json:"ip"`
+		Hostname string `json:"hostname"`
+		Forward  string `json:"forward"`
+		Reverse  string `json:"reverse"`
+	} `json:"results"`
+}
+
+// Requests an Onyphe API path, calling fn with the ip and hostname of each resolver result.
+func onypheQuery(path, key string, fn func(ip, hostname string)) error {
+	req, err := http.NewRequest("GET", onypheURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "bearer "+key)
+	req.Header.Set("Content-Type", "application/json")
+	body, err := onypheSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return err
+	}
+	m := &onypheMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return err
+	}
+	if m.Error != 0 {
+		return fmt.Errorf("onyphe: %s", m.Text)
+	}
+	for _, r := range m.Results {
+		for _, h := range []string{r.Hostname, r.Forward, r.Reverse} {
+			if h != "" {
+				fn(r.IP, strings.ToLower(strings.TrimSuffix(h, ".")))
+			}
+		}
+	}
+	return nil
+}
+
+// OnypheIP uses Onyphe's resolver/forward and resolver/reverse API endpoints to find hostnames for an IP.
+func OnypheIP(ip, key string) (string, Results, error) {
+	task := "onyphe"
+	results := Results{}
+	for _, path := range []string{"/simple/resolver/forward/", "/simple/resolver/reverse/"} {
+		err := onypheQuery(path+ip, key, func(rip, hostname string) {
+			if rip != ip || hostname == ip {
+				return
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+		})
+		if err != nil {
+			return task, results, err
+		}
+	}
+	return task, results, nil
+}
+
+// OnypheDomain searches Onyphe's resolver category to find ips and hostnames for a domain and its
+// subdomains. The resolver/forward and resolver/reverse endpoints only accept an IP.
+func OnypheDomain(domain, key string) (string, Results, error) {
+	task := "onyphe"
+	results := Results{}
+	query := url.QueryEscape("category:resolver domain:" + domain)
+	err := onypheQuery("/search/?q="+query, key, func(ip, hostname string) {
+		if ip == "" || (hostname != domain && !strings.HasSuffix(hostname, "."+domain)) {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+	})
+	return task, results, err
+}
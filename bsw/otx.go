@@ -0,0 +1,58 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+)
+
+const otxURL = "https://otx.alienvault.com/api/v1/indicators"
+
+var otxSource = newHTTPSource("otx", 0)
+
+type otxMessage struct {
+	PassiveDNS []struct {
+		Address    string `json:"address"`
+		Hostname   string `json:"hostname"`
+		RecordType string `json:"record_type"`
+	} `json:"passive_dns"`
+}
+
+// Requests an OTX passive DNS endpoint, returning the A and AAAA records as results.
+func otxPassiveDNS(task, path string) (Results, error) {
+	results := Results{}
+	body, err := otxSource.get(otxURL + path)
+	if err != nil {
+		return results, err
+	}
+	m := &otxMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return results, err
+	}
+	for _, r := range m.PassiveDNS {
+		if r.RecordType != "A" && r.RecordType != "AAAA" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: r.Address, Hostname: strings.ToLower(r.Hostname)})
+	}
+	return results, nil
+}
+
+// OTXDomain uses AlienVault OTX's passive DNS endpoint to find ips and hostnames for a domain
+// and its subdomains.
+func OTXDomain(domain string) (string, Results, error) {
+	task := "otx"
+	results, err := otxPassiveDNS(task, "/domain/"+domain+"/passive_dns")
+	return task, results, err
+}
+
+// OTXIP uses AlienVault OTX's passive DNS endpoint to find hostnames that have resolved to an IP.
+func OTXIP(ip string) (string, Results, error) {
+	task := "otx"
+	section := "IPv4"
+	if net.ParseIP(ip).To4() == nil {
+		section = "IPv6"
+	}
+	results, err := otxPassiveDNS(task, "/"+section+"/"+ip+"/passive_dns")
+	return task, results, err
+}
@@ -0,0 +1,26 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOTXDomain(t *testing.T) {
+	tsk, results, err := OTXDomain("stacktitan.com")
+	if err != nil {
+		t.Error("OTXDomain returned an error")
+		t.Log(err)
+	}
+	if tsk != "otx" {
+		t.Error("task from OTXDomain was not otx")
+	}
+	found := false
+	for _, r := range results {
+		if strings.HasSuffix(r.Hostname, "stacktitan.com") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("OTXDomain did not find the correct domain")
+	}
+}
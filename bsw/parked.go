@@ -0,0 +1,180 @@
+package bsw
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"sync"
+)
+
+// Networks used for domain parking or sinkholing. Results resolving into these rarely belong to
+// the target.
+var sinkholeNetworks = []struct {
+	name  string
+	cidrs []string
+}{
+	{"unspecified", []string{"0.0.0.0/8"}},
+	{"loopback", []string{"127.0.0.0/8", "::1/128"}},
+	{"sedo-parking", []string{"91.195.240.0/23"}},
+	{"bodis-parking", []string{"199.59.240.0/22"}},
+	{"above-parking", []string{"103.224.182.0/23", "103.224.212.0/23"}},
+	{"parkingcrew-parking", []string{"185.53.176.0/22"}},
+	{"godaddy-parking", []string{"34.102.136.180/32", "34.98.99.30/32"}},
+}
+
+// Domains of the name servers of parking services and sinkholes. A zone served by one of these
+// is parked or sinkholed.
+var sinkholeNameservers = []struct {
+	name    string
+	domains []string
+}{
+	{"sedo-parking", []string{"sedoparking.com"}},
+	{"bodis-parking", []string{"bodis.com"}},
+	{"above-parking", []string{"above.com", "trafficz.com"}},
+	{"parkingcrew-parking", []string{"parkingcrew.net"}},
+	{"dan-parking", []string{"dan.com", "undeveloped.com"}},
+	{"afternic-parking", []string{"afternic.com"}},
+	{"hugedomains-parking", []string{"hugedomains.com"}},
+	{"parklogic-parking", []string{"parklogic.com"}},
+	{"shadowserver-sinkhole", []string{"shadowserver.org"}},
+	{"microsoft-sinkhole", []string{"microsoftinternetsafety.net"}},
+}
+
+// The parking and sinkhole networks and name server domains, by name, including those added
+// with AddParked.
+var parked = struct {
+	sync.RWMutex
+	networks    map[string][]*net.IPNet
+	nameservers map[string]string
+}{
+	networks: func() map[string][]*net.IPNet {
+		nets := make(map[string][]*net.IPNet)
+		for _, s := range sinkholeNetworks {
+			nets[s.name] = parseCIDRs(s.cidrs)
+		}
+		return nets
+	}(),
+	nameservers: func() map[string]string {
+		domains := make(map[string]string)
+		for _, s := range sinkholeNameservers {
+			for _, d := range s.domains {
+				domains[d] = s.name
+			}
+		}
+		return domains
+	}(),
+}
+
+// AddParked extends the built in parking and sinkhole lists with lines in the format
+// "<ip, network, or name server domain> [name]". Blank lines and lines starting with '#' are
+// ignored, and entries without a name are named "custom".
+func AddParked(lines []string) error {
+	parked.Lock()
+	defer parked.Unlock()
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return errors.New("\"" + line + "\" is not a valid parked entry")
+		}
+		name := "custom"
+		if len(fields) == 2 {
+			name = fields[1]
+		}
+		entry := fields[0]
+		if ip := net.ParseIP(entry); ip != nil {
+			if ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			parked.networks[name] = append(parked.networks[name], network)
+			continue
+		}
+		if !strings.Contains(entry, ".") || strings.Contains(entry, "/") {
+			return errors.New("\"" + line + "\" is not a valid parked entry")
+		}
+		parked.nameservers[strings.ToLower(strings.TrimRight(entry, "."))] = name
+	}
+	return nil
+}
+
+// Returns the name of the parking or sinkhole network containing ip, or an empty string.
+func parkedNetwork(ip string) string {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return ""
+	}
+	parked.RLock()
+	defer parked.RUnlock()
+	for name, nets := range parked.networks {
+		for _, n := range nets {
+			if n.Contains(parsed) {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// Returns the name of the parking service or sinkhole operating a name server, or an empty
+// string.
+func parkedNameserver(host string) string {
+	host = strings.ToLower(strings.TrimRight(host, "."))
+	parked.RLock()
+	defer parked.RUnlock()
+	for {
+		if name, ok := parked.nameservers[host]; ok {
+			return name
+		}
+		i := strings.Index(host, ".")
+		if i < 0 {
+			return ""
+		}
+		host = host[i+1:]
+	}
+}
+
+// MarkParked returns a copy of results where results that resolve to a parking or sinkhole
+// network, that are a parking or sinkhole name server found by NS lookups, or whose hostname
+// is within a zone served by one, have the name of the service recorded in their "parked"
+// metadata.
+func MarkParked(results Results) Results {
+	marked := append(Results{}, results...)
+	zones := make(map[string]string)
+	for _, r := range results {
+		if zone := r.Meta["zone"]; zone != "" {
+			if name := parkedNameserver(r.Hostname); name != "" {
+				zones[zone] = name
+			}
+		}
+	}
+	for i, r := range marked {
+		name := parkedNetwork(r.IP)
+		if name == "" && r.Meta["zone"] != "" {
+			name = parkedNameserver(r.Hostname)
+		}
+		for host := strings.ToLower(strings.TrimRight(r.Hostname, ".")); name == "" && host != ""; {
+			name = zones[host]
+			j := strings.Index(host, ".")
+			if j < 0 {
+				break
+			}
+			host = host[j+1:]
+		}
+		if name == "" {
+			continue
+		}
+		meta := map[string]string{"parked": name}
+		for k, v := range r.Meta {
+			meta[k] = v
+		}
+		marked[i].Meta = meta
+	}
+	return marked
+}
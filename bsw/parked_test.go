@@ -0,0 +1,30 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestMarkParkedOffline(t *testing.T) {
+	if err := AddParked([]string{"# local list", "192.0.2.77 test-sinkhole", "parking.example.net"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddParked([]string{"not-a-domain"}); err == nil {
+		t.Error("AddParked did not return error for invalid entry")
+	}
+	results := Results{
+		{Source: "bing", IP: "91.195.240.10", Hostname: "old.example.com"},
+		{Source: "crt.sh", IP: "192.0.2.77", Hostname: "c2.example.com"},
+		{Source: "ns", IP: "198.51.100.1", Hostname: "ns1.parking.example.net", Meta: map[string]string{"zone": "example.org"}},
+		{Source: "crt.sh", IP: "198.51.100.2", Hostname: "www.example.org"},
+		{Source: "crt.sh", IP: "198.51.100.3", Hostname: "www.example.com"},
+	}
+	marked := MarkParked(results)
+	for i, want := range []string{"sedo-parking", "test-sinkhole", "custom", "custom", ""} {
+		if marked[i].Meta["parked"] != want {
+			t.Errorf("result %d marked %q, expected %q", i, marked[i].Meta["parked"], want)
+		}
+	}
+	if results[0].Meta != nil {
+		t.Error("MarkParked modified its input")
+	}
+}
@@ -0,0 +1,27 @@
+package bsw
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"time"
+)
+
+// VerifyPorts attempts a TCP connection to each port on an IP previously identified for hostname,
+// returning a result for every port that accepted the connection.
+func VerifyPorts(ip, hostname string, ports []int, timeout int64) (string, Results, error) {
+	task := "port verify"
+	results := Results{}
+	for _, port := range ports {
+		conn, err := net.DialTimeout("tcp", net.JoinHostPort(ip, strconv.Itoa(port)), time.Duration(timeout)*time.Millisecond)
+		if err != nil {
+			continue
+		}
+		conn.Close()
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname, Port: port, Protocol: "tcp"})
+	}
+	if len(results) < 1 {
+		return task, results, errors.New(ip + ": no open ports")
+	}
+	return task, results, nil
+}
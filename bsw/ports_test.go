@@ -0,0 +1,23 @@
+package bsw
+
+import (
+	"net"
+	"testing"
+)
+
+func TestVerifyPorts(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+	_, results, err := VerifyPorts("127.0.0.1", "localhost", []int{port}, 1000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Port != port || results[0].Protocol != "tcp" {
+		t.Error("VerifyPorts returned incorrect results")
+		t.Log(results)
+	}
+}
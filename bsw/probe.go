@@ -0,0 +1,109 @@
+package bsw
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Ports that usually serve plaintext HTTP. A plaintext request is tried on these before TLS,
+// on any other port TLS is tried first.
+var plaintextHTTPPorts = map[int]bool{
+	80: true, 81: true, 591: true, 3000: true, 5000: true, 8000: true, 8008: true,
+	8080: true, 8081: true, 8088: true, 8888: true, 9000: true, 9080: true,
+}
+
+// Returned by a probe when the port appears to speak the other protocol.
+var errWrongProtocol = errors.New("wrong protocol")
+
+// ProbeHTTP connects to a port on an IP that may serve either http or https. The protocol
+// expected for the port is tried first, and if the server does not speak it the other is tried.
+// With certs the names in the certificate are returned, and with headers the hostname in a
+// Location header. Results keep the source of the task they would have come from, with the
+// protocol found in the scheme metadata.
+func ProbeHTTP(ip string, port int, certs, headers bool, timeout int64) (string, Results, error) {
+	task := "HTTP Probe"
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	probes := []func(string, string, int, bool, bool, int64) (Results, error){probeHTTPS, probeHTTP}
+	if plaintextHTTPPorts[port] {
+		probes[0], probes[1] = probes[1], probes[0]
+	}
+	results, err := probes[0](addr, ip, port, certs, headers, timeout)
+	if err == errWrongProtocol {
+		results, err = probes[1](addr, ip, port, certs, headers, timeout)
+	}
+	if err == errWrongProtocol {
+		return task, results, errors.New(addr + ": neither http nor https")
+	}
+	return task, results, err
+}
+
+// Probes addr with TLS. A failed handshake on an open port is treated as the wrong protocol.
+func probeHTTPS(addr, ip string, port int, certs, headers bool, timeout int64) (Results, error) {
+	results := Results{}
+	conn, err := tlsHandshake(addr, timeout)
+	if err != nil {
+		if opErr, ok := err.(*net.OpError); ok && opErr.Op == "dial" {
+			return results, err
+		}
+		return results, errWrongProtocol
+	}
+	defer conn.Close()
+	if certs {
+		results = append(results, tlsCertResults("TLS Certificate", ip, port, conn)...)
+	}
+	if headers {
+		if res, err := httpGetOverConn(conn, "https://"+addr); err == nil {
+			if host, err := hostnameFromLocation(ip, res); err == nil {
+				results = append(results, Result{Source: "Headers", IP: ip, Hostname: host, Port: port, Protocol: "tcp"})
+			}
+		}
+	}
+	for i := range results {
+		results[i].Meta = map[string]string{"scheme": "https"}
+	}
+	return results, nil
+}
+
+// Probes addr with a plaintext request. A response that is not HTTP, or that asks for https,
+// is treated as the wrong protocol.
+func probeHTTP(addr, ip string, port int, certs, headers bool, timeout int64) (Results, error) {
+	results := Results{}
+	conn, err := net.DialTimeout("tcp", addr, time.Duration(timeout)*time.Millisecond)
+	if err != nil {
+		return results, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(time.Duration(timeout) * time.Millisecond)); err != nil {
+		return results, err
+	}
+	req, err := http.NewRequest("GET", "http://"+addr, nil)
+	if err != nil {
+		return results, err
+	}
+	req.Close = true
+	if err := req.Write(conn); err != nil {
+		return results, errWrongProtocol
+	}
+	res, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return results, errWrongProtocol
+	}
+	body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+	res.Body.Close()
+	if res.StatusCode == http.StatusBadRequest && bytes.Contains(bytes.ToLower(body), []byte("https")) {
+		return results, errWrongProtocol
+	}
+	if headers {
+		if host, err := hostnameFromLocation(ip, res); err == nil {
+			results = append(results, Result{Source: "Headers", IP: ip, Hostname: host, Port: port, Protocol: "tcp", Meta: map[string]string{"scheme": "http"}})
+		}
+	}
+	return results, nil
+}
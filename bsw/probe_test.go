@@ -0,0 +1,47 @@
+package bsw
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func testServerAddr(t *testing.T, s *httptest.Server) (string, int) {
+	addr := s.Listener.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port
+}
+
+func TestProbeHTTP(t *testing.T) {
+	redirect := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://www.example.com/", http.StatusFound)
+	})
+	for _, s := range []*httptest.Server{httptest.NewServer(redirect), httptest.NewTLSServer(redirect)} {
+		defer s.Close()
+		ip, port := testServerAddr(t, s)
+		scheme := "http"
+		if s.TLS != nil {
+			scheme = "https"
+		}
+		_, results, err := ProbeHTTP(ip, port, false, true, 2000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 1 || results[0].Hostname != "www.example.com" || results[0].Port != port || results[0].Meta["scheme"] != scheme {
+			t.Error("ProbeHTTP returned incorrect results for " + scheme + " on port " + strconv.Itoa(port))
+			t.Log(results)
+		}
+	}
+}
+
+func TestProbeHTTPWrongProtocol(t *testing.T) {
+	s := httptest.NewTLSServer(http.NotFoundHandler())
+	defer s.Close()
+	ip, port := testServerAddr(t, s)
+	addr := net.JoinHostPort(ip, strconv.Itoa(port))
+	if _, err := probeHTTP(addr, ip, port, false, true, 2000); err != errWrongProtocol {
+		t.Error("plaintext probe of a TLS port did not detect the wrong protocol")
+		t.Log(err)
+	}
+}
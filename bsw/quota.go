@@ -0,0 +1,172 @@
+package bsw
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long to wait for the lock on a quota ledger, and the age after which a lock left by a
+// process that died is removed.
+const (
+	quotaLockTimeout = 10 * time.Second
+	quotaLockStale   = 30 * time.Second
+)
+
+// Quota is a budget of requests a source may make in each period.
+type Quota struct {
+	Requests int
+	Period   time.Duration
+}
+
+// The requests made by a source in the current period of its quota.
+type quotaWindow struct {
+	Start time.Time `json:"start"`
+	Used  int       `json:"used"`
+}
+
+// The quota of each source and the ledger they are spent from. Without a ledger file the
+// windows are only kept in memory, and the budget is not shared with other processes.
+var quotas struct {
+	mu      sync.Mutex
+	path    string
+	budgets map[string]Quota
+	windows map[string]quotaWindow
+}
+
+// SetQuotaLedger sets the quota of each source by the name of the option that enables it, and
+// the file the requests made are counted in. Every process using the same file shares the
+// budgets, so that parallel scans with the same keys do not exhaust them. The file is locked
+// while it is updated by creating path with ".lock" appended.
+func SetQuotaLedger(path string, budgets map[string]Quota) {
+	quotas.mu.Lock()
+	defer quotas.mu.Unlock()
+	quotas.path = path
+	quotas.budgets = budgets
+	quotas.windows = make(map[string]quotaWindow)
+}
+
+// ParseQuotas parses quotas in the form <source>=<requests>/<period>, separated by commas,
+// where period is a duration such as 1h or 30m.
+func ParseQuotas(s string) (map[string]Quota, error) {
+	budgets := make(map[string]Quota)
+	for _, f := range strings.Split(s, ",") {
+		f = strings.TrimSpace(f)
+		i, j := strings.Index(f, "="), strings.Index(f, "/")
+		if i <= 0 || j < i {
+			return nil, errors.New("\"" + f + "\" is not in the form <source>=<requests>/<period>")
+		}
+		requests, err := strconv.Atoi(f[i+1 : j])
+		if err != nil || requests < 0 {
+			return nil, errors.New("\"" + f + "\" does not have a valid number of requests")
+		}
+		period, err := time.ParseDuration(f[j+1:])
+		if err != nil || period <= 0 {
+			return nil, errors.New("\"" + f + "\" does not have a valid period")
+		}
+		budgets[f[:i]] = Quota{Requests: requests, Period: period}
+	}
+	return budgets, nil
+}
+
+// Locks the ledger at path for this process, returning a function that unlocks it.
+func lockQuotaLedger(path string) (func(), error) {
+	lock := path + ".lock"
+	deadline := time.Now().Add(quotaLockTimeout)
+	for {
+		f, err := os.OpenFile(lock, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(lock) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if info, err := os.Stat(lock); err == nil && time.Since(info.ModTime()) > quotaLockStale {
+			os.Remove(lock)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("timed out waiting for the lock on quota ledger " + path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// Reads the windows of the ledger at path. A ledger that does not exist yet is empty.
+func readQuotaLedger(path string) (map[string]quotaWindow, error) {
+	windows := make(map[string]quotaWindow)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return windows, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return windows, nil
+	}
+	if err := json.Unmarshal(data, &windows); err != nil {
+		return nil, fmt.Errorf("quota ledger %s: %v", path, err)
+	}
+	return windows, nil
+}
+
+// Writes the windows to the ledger at path, replacing it whole so that a reader never sees a
+// partial ledger.
+func writeQuotaLedger(path string, windows map[string]quotaWindow) error {
+	data, err := json.Marshal(windows)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Spends a request from the quota of source, if it has one. When the budget of the current
+// period is used up a rate limit error is returned, asking to wait until the period ends.
+func spendQuota(source string) error {
+	quotas.mu.Lock()
+	defer quotas.mu.Unlock()
+	q, ok := quotas.budgets[source]
+	if !ok {
+		return nil
+	}
+	windows := quotas.windows
+	if quotas.path != "" {
+		unlock, err := lockQuotaLedger(quotas.path)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+		if windows, err = readQuotaLedger(quotas.path); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	w := windows[source]
+	if now.Sub(w.Start) >= q.Period {
+		w = quotaWindow{Start: now}
+	}
+	if w.Used >= q.Requests {
+		return &RateLimitError{
+			Status:     fmt.Sprintf("-%s quota of %d requests per %s used", source, q.Requests, q.Period),
+			RetryAfter: w.Start.Add(q.Period).Sub(now),
+		}
+	}
+	w.Used++
+	windows[source] = w
+	if quotas.path != "" {
+		return writeQuotaLedger(quotas.path, windows)
+	}
+	return nil
+}
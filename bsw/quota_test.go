@@ -0,0 +1,56 @@
+package bsw
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestParseQuotasOffline(t *testing.T) {
+	budgets, err := ParseQuotas("shodan=100/24h, virustotal=4/1m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if budgets["shodan"] != (Quota{Requests: 100, Period: 24 * time.Hour}) || budgets["virustotal"] != (Quota{Requests: 4, Period: time.Minute}) {
+		t.Errorf("unexpected quotas %v", budgets)
+	}
+	for _, s := range []string{"shodan", "shodan=100", "shodan=x/1h", "shodan=100/day", "=1/1h"} {
+		if _, err := ParseQuotas(s); err == nil {
+			t.Errorf("expected error parsing %q", s)
+		}
+	}
+}
+
+func TestSpendQuotaOffline(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bsw-quota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer SetQuotaLedger("", nil)
+	path := filepath.Join(dir, "ledger.json")
+	SetQuotaLedger(path, map[string]Quota{"shodan": {Requests: 2, Period: time.Hour}})
+	if err := spendQuota("other"); err != nil {
+		t.Errorf("source without a quota returned %v", err)
+	}
+	if err := spendQuota("shodan"); err != nil {
+		t.Fatal(err)
+	}
+	// Another process using the same ledger shares the budget.
+	SetQuotaLedger(path, map[string]Quota{"shodan": {Requests: 2, Period: time.Hour}})
+	if err := spendQuota("shodan"); err != nil {
+		t.Fatal(err)
+	}
+	err = spendQuota("shodan")
+	if Classify(err) != ErrRateLimited {
+		t.Fatalf("expected rate limit error once the quota is used, got %v", err)
+	}
+	if d := RetryAfter(err); d <= 0 || d > time.Hour {
+		t.Errorf("unexpected retry after %s", d)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Error("ledger lock was not removed")
+	}
+}
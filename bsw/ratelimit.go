@@ -0,0 +1,27 @@
+package bsw
+
+import (
+	"sync"
+	"time"
+)
+
+// limiter spaces out calls to wait so that at most one returns per interval. It is
+// used by tasks that must remain low-rate regardless of the concurrency setting.
+type limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+// Blocks until the caller is allowed to proceed.
+func (l *limiter) wait() {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	d := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+	l.mu.Unlock()
+	time.Sleep(d)
+}
@@ -0,0 +1,98 @@
+package bsw
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ipSet is a sorted set of IPv4 addresses, with IPv6 addresses kept in a map.
+type ipSet struct {
+	v4 []uint32
+	v6 map[string]bool
+}
+
+func newIPSet(ips []string) *ipSet {
+	s := &ipSet{v6: make(map[string]bool)}
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		if parsed == nil {
+			continue
+		}
+		if v4 := parsed.To4(); v4 != nil {
+			s.v4 = append(s.v4, binary.BigEndian.Uint32(v4))
+			continue
+		}
+		s.v6[parsed.String()] = true
+	}
+	sort.Slice(s.v4, func(i, j int) bool { return s.v4[i] < s.v4[j] })
+	return s
+}
+
+func (s *ipSet) contains(ip net.IP) bool {
+	if v4 := ip.To4(); v4 != nil {
+		n := binary.BigEndian.Uint32(v4)
+		i := sort.Search(len(s.v4), func(i int) bool { return s.v4[i] >= n })
+		return i < len(s.v4) && s.v4[i] == n
+	}
+	return s.v6[ip.String()]
+}
+
+// Returns the value of a string field in a line of JSON without decoding the whole line.
+func jsonField(line []byte, field string) string {
+	key := []byte(`"` + field + `":"`)
+	i := bytes.Index(line, key)
+	if i < 0 {
+		return ""
+	}
+	rest := line[i+len(key):]
+	j := bytes.IndexByte(rest, '"')
+	if j < 0 {
+		return ""
+	}
+	return string(rest[:j])
+}
+
+// RDNS streams a gzip compressed Rapid7 Project Sonar reverse DNS dataset, returning the PTR
+// name of each IP in ips. Both the JSON ("name" is the IP and "value" the PTR name) and the older
+// "ip,name" formats are supported. The file is read once for all IPs.
+func RDNS(path string, ips []string) (string, Results, error) {
+	task := "rdns"
+	results := Results{}
+	file, err := os.Open(path)
+	if err != nil {
+		return task, results, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return task, results, err
+	}
+	defer gz.Close()
+
+	set := newIPSet(ips)
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var ip, name string
+		if bytes.HasPrefix(line, []byte("{")) {
+			ip = jsonField(line, "name")
+			name = jsonField(line, "value")
+		} else if i := bytes.IndexByte(line, ','); i > 0 {
+			ip = string(line[:i])
+			name = string(line[i+1:])
+		}
+		parsed := net.ParseIP(ip)
+		if parsed == nil || name == "" || !set.contains(parsed) {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(strings.TrimRight(name, "."))})
+	}
+	return task, results, scanner.Err()
+}
@@ -0,0 +1,35 @@
+package bsw
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRDNS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rdns.json.gz")
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(file)
+	gz.Write([]byte(`{"timestamp":"1","name":"192.0.2.1","type":"ptr","value":"www.example.com"}
+{"timestamp":"1","name":"192.0.2.2","type":"ptr","value":"mail.example.com"}
+198.51.100.1,host.example.org
+`))
+	gz.Close()
+	file.Close()
+
+	_, results, err := RDNS(path, []string{"198.51.100.1", "192.0.2.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatal("RDNS returned incorrect number of results")
+	}
+	if results[0].Hostname != "www.example.com" || results[1].Hostname != "host.example.org" {
+		t.Error("RDNS returned incorrect results")
+		t.Log(results)
+	}
+}
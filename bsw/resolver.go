@@ -0,0 +1,292 @@
+package bsw
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+// ResolverRule maps a domain pattern to the DNS server used for names matching it. A pattern
+// of "example.com" matches the domain and all of its subdomains, "*.example.com" matches only
+// the subdomains, and "*" matches every name.
+type ResolverRule struct {
+	Pattern string
+	Server  string
+}
+
+var (
+	resolverMu    sync.RWMutex
+	resolverRules []ResolverRule
+)
+
+// Query hardening options used when sending queries to untrusted resolvers.
+var (
+	mixedCase   bool
+	randomPorts bool
+)
+
+// SetQueryHardening enables 0x20 encoding, which sends each query name in random mixed case and
+// rejects answers that do not echo it exactly, and binds each query to a random source port.
+// It should be called before any lookups are made.
+func SetQueryHardening(encode0x20, randomizePorts bool) {
+	mixedCase = encode0x20
+	randomPorts = randomizePorts
+}
+
+// Attribution options, so that the queries of a scan can be found in the target's DNS logs.
+var (
+	scanLabel  string
+	ednsClient string
+)
+
+// The EDNS0 option code, from the range reserved for local use, that carries the client string.
+const ednsClientCode = 65001
+
+// SetAttribution sets a label prepended to the name queried by wildcard checks, and a string
+// sent in an EDNS0 option with every query. Either may be empty. It should be called before
+// any lookups are made.
+func SetAttribution(label, client string) {
+	scanLabel = label
+	ednsClient = client
+}
+
+// Returns a copy of m carrying the EDNS0 client string.
+func withEDNSClient(m *dns.Msg) *dns.Msg {
+	m = m.Copy()
+	opt := m.IsEdns0()
+	if opt == nil {
+		m.SetEdns0(dns.DefaultMsgSize, false)
+		opt = m.IsEdns0()
+	}
+	opt.Option = append(opt.Option, &dns.EDNS0_LOCAL{Code: ednsClientCode, Data: []byte(ednsClient)})
+	return m
+}
+
+// The number of refused, failed, or timed out queries after which a preferred authoritative
+// server is no longer used.
+const authoritativeMaxFailures = 3
+
+// Authoritative servers preferred for the names of a zone, and the failures of each server.
+var preferred = struct {
+	sync.Mutex
+	zones    map[string][]string
+	failures map[string]int
+}{zones: make(map[string][]string), failures: make(map[string]int)}
+
+// PreferAuthoritative sends queries for names within zone to its authoritative servers, which
+// bypasses the stale caches of recursive resolvers. When a server refuses, fails, or does not
+// answer a query, it is retried with the server otherwise selected, and a server that does
+// so repeatedly is no longer used. Answers without the authoritative flag, such as referrals
+// to delegated subzones, are also retried.
+func PreferAuthoritative(zone string, servers []string) {
+	zone = strings.ToLower(strings.TrimRight(zone, "."))
+	preferred.Lock()
+	defer preferred.Unlock()
+	preferred.zones[zone] = append(preferred.zones[zone], servers...)
+}
+
+// Returns a usable authoritative server preferred for name, or an empty string. Servers of
+// the closest enclosing zone are chosen in turn to spread the queries among them.
+func preferredServer(name string) string {
+	name = strings.ToLower(strings.TrimRight(name, "."))
+	preferred.Lock()
+	defer preferred.Unlock()
+	best := ""
+	for zone := range preferred.zones {
+		if (name == zone || strings.HasSuffix(name, "."+zone)) && len(zone) > len(best) {
+			best = zone
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	servers := preferred.zones[best]
+	start := int(randomUint32() % uint32(len(servers)))
+	for i := range servers {
+		s := servers[(start+i)%len(servers)]
+		if preferred.failures[s] < authoritativeMaxFailures {
+			return s
+		}
+	}
+	return ""
+}
+
+// Records a failed query to a preferred server.
+func authoritativeFailed(server string) {
+	preferred.Lock()
+	defer preferred.Unlock()
+	preferred.failures[server]++
+}
+
+// SetResolverRules sets the rules used to select a DNS server for each query. Rules are matched
+// in order and the first match wins. Names that do not match a rule are sent to the server
+// provided to the lookup function.
+func SetResolverRules(rules []ResolverRule) {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	resolverRules = rules
+}
+
+// AddResolverRule appends a rule, so that it applies only to names that match no earlier rule.
+func AddResolverRule(rule ResolverRule) {
+	resolverMu.Lock()
+	defer resolverMu.Unlock()
+	resolverRules = append(resolverRules, rule)
+}
+
+// ParseResolverRules parses lines in the format "<pattern> <server>". Blank lines and lines
+// starting with '#' are ignored.
+func ParseResolverRules(lines []string) ([]ResolverRule, error) {
+	rules := []ResolverRule{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return rules, errors.New("\"" + line + "\" is not a valid resolver rule")
+		}
+		rules = append(rules, ResolverRule{Pattern: strings.ToLower(fields[0]), Server: fields[1]})
+	}
+	return rules, nil
+}
+
+// ResolverFor returns the server that will be used to query name, falling back to serverAddr
+// when no rule matches.
+func ResolverFor(name, serverAddr string) string {
+	name = strings.ToLower(strings.TrimRight(name, "."))
+	resolverMu.RLock()
+	defer resolverMu.RUnlock()
+	for _, r := range resolverRules {
+		if r.matches(name) {
+			return r.Server
+		}
+	}
+	return serverAddr
+}
+
+func (r ResolverRule) matches(name string) bool {
+	switch {
+	case r.Pattern == "*":
+		return true
+	case strings.HasPrefix(r.Pattern, "*."):
+		return strings.HasSuffix(name, r.Pattern[1:])
+	default:
+		return name == r.Pattern || strings.HasSuffix(name, "."+r.Pattern)
+	}
+}
+
+// Sends a query to a preferred authoritative server for its question, falling back to the
+// server selected by the resolver rules. Returns the answer with result metadata recording the
+// server that answered it.
+func exchange(m *dns.Msg, serverAddr string) (*dns.Msg, map[string]string, error) {
+	name := m.Question[0].Name
+	server := ResolverFor(name, serverAddr)
+	if dnssecEnabled {
+		m = m.Copy()
+		m.SetEdns0(4096, true)
+	}
+	if auth := preferredServer(name); auth != "" {
+		in, err := send(m, auth)
+		switch {
+		case err != nil || in.Rcode == dns.RcodeRefused || in.Rcode == dns.RcodeServerFailure:
+			authoritativeFailed(auth)
+		// A referral to a delegated subzone is not an answer, so the query is retried.
+		case in.Authoritative:
+			return in, answerMeta(auth, in), nil
+		}
+	}
+	in, err := send(m, server)
+	if err != nil {
+		return in, nil, err
+	}
+	return in, answerMeta(server, in), nil
+}
+
+// Returns result metadata recording the server that answered in and, when enabled, the DNSSEC
+// validation status of the answer.
+func answerMeta(server string, in *dns.Msg) map[string]string {
+	meta := map[string]string{"resolver": server}
+	if dnssecEnabled && len(in.Answer) > 0 {
+		meta["dnssec"] = validateAnswer(in, server)
+	}
+	return meta
+}
+
+// Sends a query to server applying the query hardening options, retrying over TCP when the
+// answer is truncated.
+func send(m *dns.Msg, server string) (*dns.Msg, error) {
+	name := m.Question[0].Name
+	if mixedCase {
+		m = m.Copy()
+		m.Question[0].Name = mixCase(name)
+	}
+	if ednsClient != "" {
+		m = withEDNSClient(m)
+	}
+	c := &dns.Client{}
+	var in *dns.Msg
+	var err error
+	for attempt := 0; attempt < 3; attempt++ {
+		if randomPorts {
+			c.Dialer = &net.Dialer{LocalAddr: &net.UDPAddr{Port: 1024 + int(randomUint32()%(65536-1024))}}
+		}
+		in, _, err = c.Exchange(m, server+":53")
+		// A random port may already be bound, so try again with another.
+		if !randomPorts || !isAddrInUse(err) {
+			break
+		}
+	}
+	if err == nil && in.Truncated {
+		c = &dns.Client{Net: "tcp"}
+		in, _, err = c.Exchange(m, server+":53")
+	}
+	if err == nil && mixedCase {
+		if len(in.Question) != 1 || in.Question[0].Name != m.Question[0].Name {
+			return nil, errors.New("answer for " + name + " did not match the 0x20 encoded query")
+		}
+		in.Question[0].Name = name
+		for _, rr := range in.Answer {
+			if strings.EqualFold(rr.Header().Name, name) {
+				rr.Header().Name = name
+			}
+		}
+	}
+	return in, err
+}
+
+// Returns name with the case of each letter chosen at random.
+func mixCase(name string) string {
+	b := []byte(name)
+	var bits uint32
+	for i, c := range b {
+		if i%32 == 0 {
+			bits = randomUint32()
+		}
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' {
+			if bits&1 == 1 {
+				b[i] = c &^ 0x20
+			} else {
+				b[i] = c | 0x20
+			}
+		}
+		bits >>= 1
+	}
+	return string(b)
+}
+
+func randomUint32() uint32 {
+	var b [4]byte
+	rand.Read(b[:])
+	return binary.BigEndian.Uint32(b[:])
+}
+
+func isAddrInUse(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "address already in use")
+}
@@ -0,0 +1,103 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+)
+
+func TestResolverFor(t *testing.T) {
+	rules, err := ParseResolverRules([]string{
+		"# internal zones",
+		"corp.example.com 10.0.0.53",
+		"*.lab.example.com 10.1.0.53",
+		"",
+		"10.in-addr.arpa 10.0.0.53",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetResolverRules(rules)
+	defer SetResolverRules(nil)
+
+	tests := map[string]string{
+		"corp.example.com":       "10.0.0.53",
+		"dc01.corp.example.com.": "10.0.0.53",
+		"lab.example.com":        "8.8.8.8",
+		"web.lab.example.com":    "10.1.0.53",
+		"4.3.2.10.in-addr.arpa.": "10.0.0.53",
+		"www.example.com":        "8.8.8.8",
+		"notcorp.example.com":    "8.8.8.8",
+	}
+	for name, server := range tests {
+		if s := ResolverFor(name, "8.8.8.8"); s != server {
+			t.Errorf("ResolverFor returned %s for %s, expected %s", s, name, server)
+		}
+	}
+}
+
+func TestMixCase(t *testing.T) {
+	name := "www.corp.example.com."
+	changed := false
+	for i := 0; i < 10; i++ {
+		mixed := mixCase(name)
+		if !strings.EqualFold(mixed, name) {
+			t.Fatalf("mixCase returned %s for %s", mixed, name)
+		}
+		if mixed != name {
+			changed = true
+		}
+	}
+	if !changed {
+		t.Error("mixCase did not change the case of any name")
+	}
+}
+
+func TestParseResolverRulesInvalid(t *testing.T) {
+	if _, err := ParseResolverRules([]string{"corp.example.com"}); err == nil {
+		t.Error("ParseResolverRules did not return error for invalid rule")
+	}
+}
+
+func TestAttributionOffline(t *testing.T) {
+	defer SetAttribution("", "")
+	SetAttribution("bsw-1a2b", "blacksheepwall/bsw-1a2b")
+	if name := wildcardName("example.com"); name != "bsw-1a2b-youmustcontstuctmoreplyons.example.com" {
+		t.Errorf("unexpected wildcard name %s", name)
+	}
+	m := &dns.Msg{}
+	m.SetQuestion("www.example.com.", dns.TypeA)
+	out := withEDNSClient(m)
+	if m.IsEdns0() != nil {
+		t.Error("withEDNSClient modified the original message")
+	}
+	opt := out.IsEdns0()
+	if opt == nil || len(opt.Option) != 1 {
+		t.Fatal("EDNS0 client option was not added")
+	}
+	local, ok := opt.Option[0].(*dns.EDNS0_LOCAL)
+	if !ok || local.Code != ednsClientCode || string(local.Data) != "blacksheepwall/bsw-1a2b" {
+		t.Errorf("unexpected EDNS0 option %v", opt.Option[0])
+	}
+}
+
+func TestPreferredServerOffline(t *testing.T) {
+	PreferAuthoritative("Example.org.", []string{"192.0.2.1"})
+	PreferAuthoritative("lab.example.org", []string{"192.0.2.2"})
+	if s := preferredServer("www.example.org."); s != "192.0.2.1" {
+		t.Errorf("unexpected server %s for www.example.org", s)
+	}
+	if s := preferredServer("host.lab.example.org"); s != "192.0.2.2" {
+		t.Errorf("unexpected server %s for host.lab.example.org", s)
+	}
+	if s := preferredServer("example.net"); s != "" {
+		t.Errorf("unexpected server %s for example.net", s)
+	}
+	for i := 0; i < authoritativeMaxFailures; i++ {
+		authoritativeFailed("192.0.2.2")
+	}
+	if s := preferredServer("host.lab.example.org"); s != "" {
+		t.Errorf("failed server %s was still preferred", s)
+	}
+}
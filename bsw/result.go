@@ -8,11 +8,38 @@ import (
 	"net"
 )
 
-// Result is used to store a single IP and Hostname record.
+// Result is used to store a single IP and Hostname record. Port and Protocol are set when
+// the record was found on, or verified against, a service listening on the IP. FCRDNS is set
+// when the hostname has been checked to resolve to the IP. Meta holds provenance such as the
+// resolver that answered a DNS query.
 type Result struct {
-	Source   string `json:"src"`
-	IP       string `json:"ip"`
-	Hostname string `json:"hostname"`
+	Source   string            `json:"src"`
+	IP       string            `json:"ip"`
+	Hostname string            `json:"hostname"`
+	Port     int               `json:"port,omitempty"`
+	Protocol string            `json:"protocol,omitempty"`
+	FCRDNS   string            `json:"fcrdns,omitempty"`
+	Meta     map[string]string `json:"meta,omitempty"`
+}
+
+// Forward confirmation statuses of a Result.
+const (
+	FCRDNSConfirmed   = "confirmed"
+	FCRDNSUnconfirmed = "unconfirmed"
+)
+
+// ResultKey identifies a Result without its metadata. Results with the same key are duplicates.
+type ResultKey struct {
+	Source   string
+	IP       string
+	Hostname string
+	Port     int
+	Protocol string
+}
+
+// Key returns the key identifying r.
+func (r Result) Key() ResultKey {
+	return ResultKey{Source: r.Source, IP: r.IP, Hostname: r.Hostname, Port: r.Port, Protocol: r.Protocol}
 }
 
 // Results is a slice of Result.
@@ -33,3 +60,65 @@ func (r Results) Less(i, j int) bool {
 	}
 	return binary.BigEndian.Uint32(first) < binary.BigEndian.Uint32(second)
 }
+
+// Dedupe returns the unique results in r, keeping the order in which they first appear. The
+// metadata of duplicates is merged into the first, without replacing keys it already has.
+func (r Results) Dedupe() Results {
+	index := make(map[ResultKey]int)
+	unique := Results{}
+	for _, res := range r {
+		i, ok := index[res.Key()]
+		if !ok {
+			index[res.Key()] = len(unique)
+			unique = append(unique, res)
+			continue
+		}
+		if len(res.Meta) > 0 {
+			unique[i].Meta = mergeMeta(unique[i].Meta, res.Meta)
+		}
+	}
+	return unique
+}
+
+// Returns a new map holding the keys of each of metas. Earlier maps take precedence.
+func mergeMeta(metas ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for i := len(metas) - 1; i >= 0; i-- {
+		for k, v := range metas[i] {
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// Merge returns the unique results in r and each of others.
+func (r Results) Merge(others ...Results) Results {
+	merged := append(Results{}, r...)
+	for _, o := range others {
+		merged = append(merged, o...)
+	}
+	return merged.Dedupe()
+}
+
+// FilterBySource returns the results in r found by any of sources.
+func (r Results) FilterBySource(sources ...string) Results {
+	filtered := Results{}
+	for _, res := range r {
+		for _, s := range sources {
+			if res.Source == s {
+				filtered = append(filtered, res)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// ByIP groups the results in r by IP address.
+func (r Results) ByIP() map[string]Results {
+	byIP := make(map[string]Results)
+	for _, res := range r {
+		byIP[res.IP] = append(byIP[res.IP], res)
+	}
+	return byIP
+}
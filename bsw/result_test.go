@@ -0,0 +1,80 @@
+package bsw
+
+import (
+	"sort"
+	"testing"
+)
+
+var testResults = Results{
+	{Source: "Reverse", IP: "192.0.2.2", Hostname: "www.example.com"},
+	{Source: "TLS Certificate", IP: "192.0.2.1", Hostname: "example.com"},
+	{Source: "Reverse", IP: "192.0.2.2", Hostname: "www.example.com"},
+	{Source: "Reverse", IP: "192.0.2.1", Hostname: "mail.example.com"},
+}
+
+func TestResultsDedupe(t *testing.T) {
+	unique := testResults.Dedupe()
+	if len(unique) != 3 {
+		t.Fatal("Dedupe returned incorrect number of results")
+	}
+	if unique[0].Key() != testResults[0].Key() || unique[2].Key() != testResults[3].Key() {
+		t.Error("Dedupe did not keep the order of results")
+	}
+}
+
+func TestResultsDedupeMeta(t *testing.T) {
+	results := Results{
+		{Source: "mx", IP: "192.0.2.1", Hostname: "mail.example.com", Meta: map[string]string{"resolver": "192.0.2.53"}},
+		{Source: "mx", IP: "192.0.2.1", Hostname: "mail.example.com", Meta: map[string]string{"resolver": "198.51.100.53", "other": "x"}},
+	}
+	unique := results.Dedupe()
+	if len(unique) != 1 {
+		t.Fatal("Dedupe returned incorrect number of results")
+	}
+	if unique[0].Meta["resolver"] != "192.0.2.53" || unique[0].Meta["other"] != "x" {
+		t.Error("Dedupe did not merge metadata")
+		t.Log(unique[0].Meta)
+	}
+	if results[0].Meta["other"] != "" {
+		t.Error("Dedupe modified the metadata of its input")
+	}
+}
+
+func TestResultsMerge(t *testing.T) {
+	other := Results{
+		{Source: "Reverse", IP: "192.0.2.1", Hostname: "mail.example.com"},
+		{Source: "SMTP", IP: "192.0.2.1", Hostname: "mail.example.com", Port: 25, Protocol: "tcp"},
+	}
+	merged := testResults[:2].Merge(other, testResults[2:])
+	if len(merged) != 4 {
+		t.Error("Merge returned incorrect number of results")
+		t.Log(merged)
+	}
+}
+
+func TestResultsFilterBySource(t *testing.T) {
+	filtered := testResults.FilterBySource("TLS Certificate", "SMTP")
+	if len(filtered) != 1 || filtered[0].Hostname != "example.com" {
+		t.Error("FilterBySource returned incorrect results")
+		t.Log(filtered)
+	}
+}
+
+func TestResultsByIP(t *testing.T) {
+	byIP := testResults.Dedupe().ByIP()
+	if len(byIP) != 2 {
+		t.Fatal("ByIP returned incorrect number of IPs")
+	}
+	if len(byIP["192.0.2.1"]) != 2 {
+		t.Error("ByIP returned incorrect results for 192.0.2.1")
+	}
+}
+
+func TestResultsSort(t *testing.T) {
+	results := testResults.Dedupe()
+	sort.Sort(results)
+	if results[0].IP != "192.0.2.1" || results[2].IP != "192.0.2.2" {
+		t.Error("Results were not sorted by IP")
+		t.Log(results)
+	}
+}
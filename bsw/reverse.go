@@ -1,15 +1,20 @@
 package bsw
 
-// Reverse uses LookupIP to get PTR record for an IP.
-func Reverse(ip, serverAddr string) (string, Results, error) {
+// Reverse uses LookupIP to get PTR record for an IP. With confirm each name is also checked to
+// resolve back to the IP, setting the FCRDNS status of its result.
+func Reverse(ip, serverAddr string, confirm bool) (string, Results, error) {
 	task := "Reverse"
 	results := Results{}
-	hostname, err := LookupIP(ip, serverAddr)
+	hostname, answer, err := lookupIP(ip, serverAddr)
 	if err != nil {
 		return task, results, err
 	}
 	for _, host := range hostname {
-		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+		r := Result{Source: task, IP: ip, Hostname: host, Meta: mergeMeta(answer)}
+		if confirm {
+			r.FCRDNS = Confirm(r, serverAddr)
+		}
+		results = append(results, r)
 	}
 	return task, results, nil
 }
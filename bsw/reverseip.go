@@ -0,0 +1,66 @@
+package bsw
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const youGetSignalURL = "https://domains.yougetsignal.com/domains.php"
+
+// The free service allows few lookups, so requests are spaced out.
+var reverseIPSource = newHTTPSource("reverseip-api", 2*time.Second).withHealthCheck("https://domains.yougetsignal.com/")
+
+type youGetSignalMessage struct {
+	Status          string     `json:"status"`
+	Message         string     `json:"message"`
+	RemoteIPAddress string     `json:"remoteIpAddress"`
+	DomainArray     [][]string `json:"domainArray"`
+}
+
+// Returns the results in m, or an error when the lookup failed. A message about the lookup
+// limit is returned as rate limiting.
+func youGetSignalResults(task string, m *youGetSignalMessage) (Results, error) {
+	results := Results{}
+	if m.Status != "Success" {
+		if strings.Contains(strings.ToLower(m.Message), "limit") {
+			return results, &RateLimitError{Status: task + ": " + m.Message}
+		}
+		return results, errors.New(task + ": " + m.Message)
+	}
+	for _, d := range m.DomainArray {
+		if len(d) == 0 || d[0] == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: m.RemoteIPAddress, Hostname: strings.ToLower(d[0])})
+	}
+	return results, nil
+}
+
+// ReverseIPAPI uses the reverse IP lookup at domains.yougetsignal.com to find other domains
+// hosted on an IP, or on the IP a domain resolves to.
+func ReverseIPAPI(search string) (string, Results, error) {
+	task := "yougetsignal.com reverse IP"
+	v := url.Values{}
+	v.Set("remoteAddress", search)
+	v.Set("key", "")
+	req, err := http.NewRequest("POST", youGetSignalURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return task, Results{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=UTF-8")
+	req.Header.Set("User-Agent", browserUserAgent)
+	body, err := reverseIPSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return task, Results{}, err
+	}
+	m := &youGetSignalMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return task, Results{}, err
+	}
+	results, err := youGetSignalResults(task, m)
+	return task, results, err
+}
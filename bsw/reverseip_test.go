@@ -0,0 +1,25 @@
+package bsw
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestYouGetSignalResultsOffline(t *testing.T) {
+	m := &youGetSignalMessage{}
+	body := `{"status":"Success","resultsMethod":"database","domainCount":"2","remoteAddress":"192.0.2.1","remoteIpAddress":"192.0.2.1","domainArray":[["Example.com",""],["www.example.org",""],[]]}`
+	if err := json.Unmarshal([]byte(body), m); err != nil {
+		t.Fatal(err)
+	}
+	results, err := youGetSignalResults("test", m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Hostname != "example.com" || results[0].IP != "192.0.2.1" || results[1].Hostname != "www.example.org" {
+		t.Errorf("unexpected results %v", results)
+	}
+	m = &youGetSignalMessage{Status: "Fail", Message: "Daily reverse IP check limit reached for 203.0.113.1."}
+	if _, err := youGetSignalResults("test", m); Classify(err) != ErrRateLimited {
+		t.Errorf("expected the lookup limit to be rate limiting, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package bsw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const reverseWhoisURL = "https://reverse-whois.whoisxmlapi.com/api/v2"
+
+// Most pages of 10000 domains requested from the reverse WHOIS API.
+const reverseWhoisMaxPages = 5
+
+type reverseWhoisRequest struct {
+	APIKey           string `json:"apiKey"`
+	SearchType       string `json:"searchType"`
+	Mode             string `json:"mode"`
+	SearchAfter      string `json:"searchAfter,omitempty"`
+	BasicSearchTerms struct {
+		Include []string `json:"include"`
+	} `json:"basicSearchTerms"`
+}
+
+type reverseWhoisMessage struct {
+	DomainsList         []string    `json:"domainsList"`
+	NextPageSearchAfter interface{} `json:"nextPageSearchAfter"`
+}
+
+// ReverseWhois uses WhoisXML's reverse WHOIS API to find the domains whose current WHOIS record
+// contains term, such as a registrant email address or organization. The domains are returned
+// without ips, to be scanned as domains.
+func ReverseWhois(term, apiKey string) (string, Results, error) {
+	task := "reverse-whois"
+	results := Results{}
+	r := reverseWhoisRequest{APIKey: apiKey, SearchType: "current", Mode: "purchase"}
+	r.BasicSearchTerms.Include = []string{term}
+	seen := make(map[string]bool)
+	for page := 0; page < reverseWhoisMaxPages; page++ {
+		data, err := json.Marshal(r)
+		if err != nil {
+			return task, results, err
+		}
+		req, err := http.NewRequest("POST", reverseWhoisURL, bytes.NewReader(data))
+		if err != nil {
+			return task, results, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		body, err := whoisXMLSource.fetch(http.DefaultClient, req, false)
+		if err != nil {
+			return task, results, err
+		}
+		m := &reverseWhoisMessage{}
+		if err := json.Unmarshal(body, m); err != nil {
+			return task, results, err
+		}
+		for _, d := range m.DomainsList {
+			d = strings.ToLower(strings.TrimSuffix(d, "."))
+			if d == "" || seen[d] {
+				continue
+			}
+			seen[d] = true
+			results = append(results, Result{Source: task, Hostname: d, Meta: map[string]string{"reverse_whois": term}})
+		}
+		next, ok := m.NextPageSearchAfter.(string)
+		if !ok || next == "" {
+			break
+		}
+		r.SearchAfter = next
+	}
+	return task, results, nil
+}
@@ -0,0 +1,62 @@
+package bsw
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+const ripeStatURL = "https://stat.ripe.net/data"
+
+var ripeStatSource = newHTTPSource("asn", 0)
+
+// ASNInfo is the autonomous system announcing the prefix that contains an IP.
+type ASNInfo struct {
+	ASN    string `json:"asn"`
+	Holder string `json:"holder"`
+	Prefix string `json:"prefix"`
+}
+
+type ripeStatNetworkInfo struct {
+	Data struct {
+		ASNs   []string `json:"asns"`
+		Prefix string   `json:"prefix"`
+	} `json:"data"`
+}
+
+type ripeStatASOverview struct {
+	Data struct {
+		Holder string `json:"holder"`
+	} `json:"data"`
+}
+
+// Requests a RIPEstat data call and decodes the JSON response into v.
+func ripeStatRequest(call, resource string, v interface{}) error {
+	body, err := ripeStatSource.get(ripeStatURL + "/" + call + "/data.json?resource=" + resource)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// RIPEStatNetwork uses RIPEstat's network-info data call to find the announced prefix containing
+// an IP and the ASN announcing it. Holder is not set.
+func RIPEStatNetwork(ip string) (ASNInfo, error) {
+	m := &ripeStatNetworkInfo{}
+	if err := ripeStatRequest("network-info", ip, m); err != nil {
+		return ASNInfo{}, err
+	}
+	if len(m.Data.ASNs) == 0 {
+		return ASNInfo{}, errors.New(ip + " is not announced")
+	}
+	return ASNInfo{ASN: "AS" + m.Data.ASNs[0], Prefix: m.Data.Prefix}, nil
+}
+
+// RIPEStatHolder uses RIPEstat's as-overview data call to find the holder of an ASN.
+func RIPEStatHolder(asn string) (string, error) {
+	m := &ripeStatASOverview{}
+	if err := ripeStatRequest("as-overview", strings.ToUpper(asn), m); err != nil {
+		return "", err
+	}
+	return m.Data.Holder, nil
+}
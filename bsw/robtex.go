@@ -1,38 +1,130 @@
 package bsw
 
 import (
-	"net/http"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
+)
+
+const robtexPDNSURL = "https://freeapi.robtex.com/pdns/"
 
-	"github.com/PuerkitoBio/goquery"
+// The free API throttles bursts, so its requests are spaced out.
+var (
+	robtexSource     = newHTTPSource("robtex", time.Second).withHealthCheck("https://freeapi.robtex.com/")
+	robtexHTMLSource = newHTTPSource("robtex-html", 0)
 )
 
-// Robtex looks up a host at robtex.com.
+// Most pages of a cursor continued response that are requested.
+const robtexMaxPages = 10
+
+type robtexRecord struct {
+	RRName string `json:"rrname"`
+	RRData string `json:"rrdata"`
+	RRType string `json:"rrtype"`
+	Cursor string `json:"cursor"`
+}
+
+// Calls fn for each A and AAAA record in a newline delimited JSON response from the pdns
+// API, returning the cursor to continue from when the response is cut short.
+func robtexRecords(r io.Reader, fn func(r robtexRecord)) (string, error) {
+	cursor := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		r := robtexRecord{}
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			continue
+		}
+		if r.Cursor != "" {
+			cursor = r.Cursor
+			continue
+		}
+		if r.RRType != "A" && r.RRType != "AAAA" {
+			continue
+		}
+		r.RRName = strings.ToLower(strings.TrimSuffix(r.RRName, "."))
+		fn(r)
+	}
+	return cursor, scanner.Err()
+}
+
+// Requests a page of a pdns lookup, returning the cursor for the next page.
+func robtexPDNSPage(path, cursor string, fn func(r robtexRecord)) (string, error) {
+	u := robtexPDNSURL + path
+	if cursor != "" {
+		u += "?cursor=" + url.QueryEscape(cursor)
+	}
+	body, err := robtexSource.get(u)
+	if err != nil {
+		return "", err
+	}
+	return robtexRecords(bytes.NewReader(body), fn)
+}
+
+// Performs a pdns lookup, following the cursor for at most robtexMaxPages.
+func robtexPDNS(path string, fn func(r robtexRecord)) error {
+	cursor := ""
+	for page := 0; page < robtexMaxPages; page++ {
+		var err error
+		cursor, err = robtexPDNSPage(path, cursor, fn)
+		if err != nil {
+			if Classify(err) == ErrNotFound {
+				return nil
+			}
+			return err
+		}
+		if cursor == "" {
+			break
+		}
+	}
+	return nil
+}
+
+// Robtex uses robtex.com's reverse passive DNS API to find hostnames that have resolved to an IP.
 func Robtex(ip string) (string, Results, error) {
 	task := "robtex.com"
 	results := Results{}
-	resp, err := http.Get("http://www.robtex.com/ip/" + ip + ".html")
-	if err != nil {
-		return task, results, err
-	}
-	defer resp.Body.Close()
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	err := robtexPDNS("reverse/"+ip, func(r robtexRecord) {
+		results = append(results, Result{Source: task, IP: ip, Hostname: r.RRName})
+	})
+	return task, results, err
+}
+
+// RobtexDomain uses robtex.com's forward passive DNS API to find addresses a domain has
+// resolved to.
+func RobtexDomain(domain string) (string, Results, error) {
+	task := "robtex.com"
+	results := Results{}
+	err := robtexPDNS("forward/"+domain, func(r robtexRecord) {
+		results = append(results, Result{Source: task, IP: r.RRData, Hostname: r.RRName})
+	})
+	return task, results, err
+}
+
+// RobtexHTML looks up a host at robtex.com, scraping the HTML page.
+func RobtexHTML(ip string) (string, Results, error) {
+	task := "robtex.com"
+	results := Results{}
+	doc, err := robtexHTMLSource.scrapeURL("http://www.robtex.com/ip/" + ip + ".html")
 	if err != nil {
 		return task, results, err
 	}
-	doc.Selection.Find("#x_summary td:nth-child(1)").Each(func(_ int, s *goquery.Selection) {
-		hostname := s.Text()
+	for _, hostname := range extract("robtex-html", doc) {
 		if strings.Contains(hostname, "*") {
-			return
+			continue
 		}
 		if hostname == "." {
-			return
+			continue
 		}
 		if _, err := strconv.Atoi(hostname); err == nil {
-			return
+			continue
 		}
-		results = append(results, Result{Source: task, IP: ip, Hostname: s.Text()})
-	})
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+	}
 	return task, results, nil
 }
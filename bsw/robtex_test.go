@@ -1,6 +1,7 @@
 package bsw
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -17,3 +18,29 @@ func TestRobtex(t *testing.T) {
 		t.Error("robtex did not return any results")
 	}
 }
+
+func TestRobtexRecords(t *testing.T) {
+	body := `{"rrname":"www.example.com.","rrdata":"192.0.2.1","rrtype":"A","time_first":1,"time_last":2,"count":3}
+{"rrname":"example.com","rrdata":"mail.example.com","rrtype":"MX"}
+not json
+{"rrname":"WWW.example.com","rrdata":"2001:db8::1","rrtype":"AAAA"}
+{"cursor":"abc123"}
+`
+	records := []robtexRecord{}
+	cursor, err := robtexRecords(strings.NewReader(body), func(r robtexRecord) { records = append(records, r) })
+	if err != nil {
+		t.Error("error returned from robtexRecords")
+		t.Log(err)
+	}
+	if cursor != "abc123" {
+		t.Error("robtexRecords did not return the cursor")
+		t.Log(cursor)
+	}
+	if len(records) != 2 {
+		t.Fatal("robtexRecords returned incorrect number of records")
+	}
+	if records[0].RRName != "www.example.com" || records[0].RRData != "192.0.2.1" || records[1].RRName != "www.example.com" || records[1].RRData != "2001:db8::1" {
+		t.Error("robtexRecords returned incorrect records")
+		t.Log(records)
+	}
+}
@@ -0,0 +1,44 @@
+package bsw
+
+import (
+	"bytes"
+	"errors"
+	"time"
+)
+
+// Markers of CAPTCHA and block pages served to scrapers in place of results.
+var blockPageMarkers = []string{
+	"g-recaptcha",
+	"hcaptcha.com",
+	"/cdn-cgi/challenge-platform",
+	"attention required! | cloudflare",
+	"unusual traffic from your computer",
+	"are you a robot",
+	"b_captcha",
+	"id=\"captcha",
+	"solve the captcha",
+	"exceeded the maximum number of queries",
+}
+
+// How long a source is paused when it serves a block page.
+const blockPagePause = time.Minute
+
+// Returns true if body looks like a CAPTCHA or block page.
+func isBlockPage(body []byte) bool {
+	lower := bytes.ToLower(body)
+	for _, m := range blockPageMarkers {
+		if bytes.Contains(lower, []byte(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsBlocked reports whether err was caused by a source serving a CAPTCHA or block page.
+func IsBlocked(err error) bool {
+	var e *RateLimitError
+	return errors.As(err, &e) && e.Blocked
+}
+
+// A browser User-Agent for scrapers of sites that refuse unknown clients.
+const browserUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
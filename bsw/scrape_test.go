@@ -0,0 +1,15 @@
+package bsw
+
+import "testing"
+
+func TestIsBlockPage(t *testing.T) {
+	if !isBlockPage([]byte(`<html><div class="g-recaptcha" data-sitekey="x"></div></html>`)) {
+		t.Error("isBlockPage did not detect a CAPTCHA page")
+	}
+	if isBlockPage([]byte(`<html><cite>https://www.example.com</cite></html>`)) {
+		t.Error("isBlockPage detected a results page as a block page")
+	}
+	if !IsBlocked(&RateLimitError{Status: "bing", Blocked: true}) {
+		t.Error("IsBlocked did not detect a block page error")
+	}
+}
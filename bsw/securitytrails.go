@@ -0,0 +1,83 @@
+package bsw
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+const securityTrailsURL = "https://api.securitytrails.com/v1"
+
+var securityTrailsSource = newHTTPSource("securitytrails", 0)
+
+type securityTrailsSubdomains struct {
+	Subdomains []string `json:"subdomains"`
+	Message    string   `json:"message"`
+}
+
+type securityTrailsList struct {
+	Records []struct {
+		Hostname string `json:"hostname"`
+	} `json:"records"`
+	Meta struct {
+		TotalPages int `json:"total_pages"`
+	} `json:"meta"`
+	Message string `json:"message"`
+}
+
+// Sends a request to the SecurityTrails API and decodes the JSON response into v.
+func securityTrailsRequest(method, path, key string, body []byte, v interface{}) error {
+	req, err := http.NewRequest(method, securityTrailsURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("APIKEY", key)
+	req.Header.Set("Content-Type", "application/json")
+	data, err := securityTrailsSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// SecurityTrailsDomain uses SecurityTrails' '/domain/{domain}/subdomains' REST API endpoint to find
+// subdomains of a domain, returning those that resolve.
+func SecurityTrailsDomain(domain, key, serverAddr string) (string, Results, error) {
+	task := "securitytrails"
+	results := Results{}
+	m := &securityTrailsSubdomains{}
+	if err := securityTrailsRequest("GET", "/domain/"+domain+"/subdomains?children_only=false", key, nil, m); err != nil {
+		return task, results, err
+	}
+	for _, sub := range m.Subdomains {
+		fqdn := sub + "." + domain
+		ip, answer, err := lookupNameOrCname(fqdn, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: fqdn, Meta: answer})
+	}
+	return task, results, nil
+}
+
+// SecurityTrailsIP uses SecurityTrails' '/domains/list' REST API endpoint to find hostnames that
+// currently resolve to an IP.
+func SecurityTrailsIP(ip, key string) (string, Results, error) {
+	task := "securitytrails"
+	results := Results{}
+	body, _ := json.Marshal(map[string]map[string]string{"filter": {"ipv4": ip}})
+	for page := 1; ; page++ {
+		m := &securityTrailsList{}
+		if err := securityTrailsRequest("POST", "/domains/list?page="+strconv.Itoa(page), key, body, m); err != nil {
+			return task, results, err
+		}
+		for _, r := range m.Records {
+			results = append(results, Result{Source: task, IP: ip, Hostname: r.Hostname})
+		}
+		if page >= m.Meta.TotalPages {
+			break
+		}
+	}
+	return task, results, nil
+}
@@ -1,19 +1,29 @@
 package bsw
 
 import (
+	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/tomsteele/go-shodan"
 )
 
+// Shodan's API, which is called through its client library.
+var shodanSource = newHTTPSource("shodan", 0)
+
 // ShodanAPIReverse uses Shodan's '/dns/reverse' REST API to get hostnames for
 // a list of ips.
 func ShodanAPIReverse(ips []string, key string) (string, Results, error) {
 	task := "shodan API reverse"
 	results := Results{}
 	c := shodan.New(key)
-	d, err := c.DNSReverse(ips)
+	var d []shodan.HostDNSReverse
+	err := shodanSource.call(func() error {
+		var err error
+		d, err = c.DNSReverse(ips)
+		return shodanError(err)
+	})
 	if err != nil {
 		return task, results, err
 	}
@@ -38,18 +48,28 @@ func ShodanAPIHostSearch(domain string, key string) (string, Results, error) {
 		domain = "." + domain
 	}
 	c := shodan.New(key)
-	count, err := c.HostCount("hostname:"+domain, []string{})
+	var count *shodan.HostCount
+	err := shodanSource.call(func() error {
+		var err error
+		count, err = c.HostCount("hostname:"+domain, []string{})
+		return shodanError(err)
+	})
 	if err != nil {
 		return task, results, err
 	}
-	pages := count.Total / 100
+	pages := (count.Total + 99) / 100
 	if pages < 1 {
 		pages = 1
 	}
 	for i := 1; i <= pages; i++ {
 		opts := url.Values{}
 		opts.Set("page", strconv.Itoa(i))
-		hs, err := c.HostSearch("hostname:"+domain, []string{}, opts)
+		var hs *shodan.HostSearch
+		err := shodanSource.call(func() error {
+			var err error
+			hs, err = c.HostSearch("hostname:"+domain, []string{}, opts)
+			return shodanError(err)
+		})
 		if err != nil {
 			return task, results, err
 		}
@@ -67,3 +87,19 @@ func ShodanAPIHostSearch(domain string, key string) (string, Results, error) {
 	}
 	return task, results, nil
 }
+
+// The Shodan client returns the API's error message as a string. This wraps the matching
+// category so that throttled requests are retried and invalid keys skip remaining tasks.
+func shodanError(err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "rate limit"):
+		return &RateLimitError{Status: "shodan: " + err.Error()}
+	case strings.Contains(msg, "invalid api key"), strings.Contains(msg, "access denied"):
+		return fmt.Errorf("shodan: %s: %w", err.Error(), ErrAuth)
+	}
+	return err
+}
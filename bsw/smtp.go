@@ -0,0 +1,90 @@
+package bsw
+
+import (
+	"crypto/tls"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+const smtpHelo = "blacksheepwall.invalid"
+
+// SMTP connects to an IP on port 25 and issues EHLO, NOOP, VRFY, and EXPN, parsing any hostnames
+// disclosed in the banner and multiline responses. If the server offers STARTTLS, the certificate
+// is parsed for CommonName and SubjectAlt names.
+func SMTP(ip string, timeout int64) (string, Results, error) {
+	task := "SMTP"
+	results := Results{}
+	names, err := smtpHostnames(ip+":25", timeout)
+	if err != nil {
+		return task, results, err
+	}
+	for _, name := range names {
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Port: 25, Protocol: "tcp"})
+	}
+	return task, results, nil
+}
+
+// Holds the conversation with an SMTP server at addr and returns every unique hostname seen.
+func smtpHostnames(addr string, timeout int64) ([]string, error) {
+	t := time.Duration(timeout) * time.Millisecond
+	conn, err := net.DialTimeout("tcp", addr, t)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(t)); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	names := []string{}
+	add := func(text string) {
+		for _, h := range hostnamesFromText(text) {
+			if h == smtpHelo || seen[h] {
+				continue
+			}
+			seen[h] = true
+			names = append(names, h)
+		}
+	}
+
+	tp := textproto.NewConn(conn)
+	_, banner, err := tp.ReadResponse(220)
+	if err != nil {
+		return nil, err
+	}
+	add(banner)
+
+	var ehlo string
+	if _, err := tp.Cmd("EHLO %s", smtpHelo); err == nil {
+		_, ehlo, _ = tp.ReadResponse(250)
+		add(ehlo)
+	}
+	for _, cmd := range []string{"NOOP", "VRFY postmaster", "EXPN postmaster"} {
+		if _, err := tp.Cmd("%s", cmd); err != nil {
+			break
+		}
+		_, msg, _ := tp.ReadResponse(0)
+		add(msg)
+	}
+
+	if strings.Contains(strings.ToUpper(ehlo), "STARTTLS") {
+		if _, err := tp.Cmd("STARTTLS"); err == nil {
+			if _, _, err := tp.ReadResponse(220); err == nil {
+				tconn := tls.Client(conn, clientTLSConfig)
+				if err := tconn.Handshake(); err == nil {
+					cert := tconn.ConnectionState().PeerCertificates[0]
+					add(cert.Subject.CommonName)
+					for _, name := range cert.DNSNames {
+						add(name)
+					}
+				}
+			}
+		}
+	} else {
+		tp.Cmd("QUIT")
+	}
+	return names, nil
+}
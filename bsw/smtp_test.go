@@ -0,0 +1,51 @@
+package bsw
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestSMTPHostnames(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.Write([]byte("220 mail.example.com ESMTP Postfix\r\n"))
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				conn.Write([]byte("250-mail.example.com Hello\r\n250-SIZE 1024\r\n250 relay01.corp.example.local\r\n"))
+			case strings.HasPrefix(line, "QUIT"):
+				conn.Write([]byte("221 Bye\r\n"))
+				return
+			default:
+				conn.Write([]byte("250 Ok\r\n"))
+			}
+		}
+	}()
+	names, err := smtpHostnames(l.Addr().String(), 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("smtpHostnames returned incorrect number of hostnames %v", names)
+	}
+	if names[0] != "mail.example.com" || names[1] != "relay01.corp.example.local" {
+		t.Error("smtpHostnames returned incorrect hostnames")
+		t.Log(names)
+	}
+}
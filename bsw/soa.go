@@ -0,0 +1,74 @@
+package bsw
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/miekg/dns"
+)
+
+// Returns the SOA record for a domain.
+func lookupSOARecord(domain, serverAddr string) (*dns.SOA, error) {
+	m := &dns.Msg{}
+	m.SetQuestion(dns.Fqdn(domain), dns.TypeSOA)
+	in, _, err := exchange(m, serverAddr)
+	if err != nil {
+		return nil, err
+	}
+	for _, a := range in.Answer {
+		if soa, ok := a.(*dns.SOA); ok {
+			return soa, nil
+		}
+	}
+	return nil, errNoAnswer
+}
+
+// Returns the mailbox of a SOA RNAME as an email address, where the first label not ending
+// in an escaped dot is the local part.
+func soaMailbox(rname string) string {
+	rname = strings.TrimRight(rname, ".")
+	for i := 0; i < len(rname); i++ {
+		switch rname[i] {
+		case '\\':
+			i++
+		case '.':
+			return strings.Replace(rname[:i], "\\.", ".", -1) + "@" + strings.ToLower(rname[i+1:])
+		}
+	}
+	return ""
+}
+
+// SOA looks up the SOA record of a domain, returning every A and AAAA record of its primary
+// name server (MNAME) and of the domain of its administrator mailbox (RNAME). The field and
+// the zone's serial are recorded in "soa" and "soa_serial" metadata, and the mailbox in
+// "soa_mailbox".
+func SOA(domain, serverAddr string) (string, Results, error) {
+	task := "soa"
+	results := Results{}
+	soa, err := lookupSOARecord(domain, serverAddr)
+	if err != nil {
+		return task, results, err
+	}
+	serial := strconv.FormatUint(uint64(soa.Serial), 10)
+	mailbox := soaMailbox(soa.Mbox)
+	hosts := [][2]string{{"mname", strings.ToLower(strings.TrimRight(soa.Ns, "."))}}
+	if i := strings.LastIndex(mailbox, "@"); i >= 0 {
+		hosts = append(hosts, [2]string{"rname", mailbox[i+1:]})
+	}
+	for _, h := range hosts {
+		field, host := h[0], h[1]
+		meta := map[string]string{"soa": field, "soa_serial": serial}
+		if field == "rname" {
+			meta["soa_mailbox"] = mailbox
+		}
+		ips, answer, err := lookupAddrs(host, serverAddr)
+		if err != nil || len(ips) == 0 {
+			results = append(results, Result{Source: task, Hostname: host, Meta: meta})
+			continue
+		}
+		for _, ip := range ips {
+			results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: mergeMeta(answer, meta)})
+		}
+	}
+	return task, results, nil
+}
@@ -0,0 +1,28 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestSOAMailboxOffline(t *testing.T) {
+	for rname, want := range map[string]string{
+		"hostmaster.Example.com.":  "hostmaster@example.com",
+		"dns\\.admin.example.com.": "dns.admin@example.com",
+		"localhost.":               "",
+	} {
+		if got := soaMailbox(rname); got != want {
+			t.Errorf("soaMailbox(%q) is %q, expected %q", rname, got, want)
+		}
+	}
+}
+
+func TestSOA(t *testing.T) {
+	_, results, err := SOA("google.com", "8.8.8.8")
+	if err != nil {
+		t.Error("error returned from SOA")
+		t.Log(err)
+	}
+	if len(results) < 1 || results[0].Meta["soa"] != "mname" {
+		t.Error("primary name server not returned from SOA")
+	}
+}
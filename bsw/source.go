@@ -0,0 +1,247 @@
+package bsw
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Consecutive failures after which a source's circuit opens, and how long it stays open.
+const (
+	breakerThreshold = 5
+	breakerCooldown  = time.Minute
+)
+
+// SourceStats counts the requests made by a source. CircuitOpen is the number of times its
+// circuit opened, and Rejected the calls refused without a request while it was open.
+type SourceStats struct {
+	Requests    int
+	Failures    int
+	RateLimited int
+	Rejected    int
+	CircuitOpen int
+}
+
+// httpSource is the plumbing shared by sources that call an HTTP API or scrape a site. Each
+// request is spaced out by the source's limiter, if it has one, and counted. After
+// breakerThreshold consecutive failures the source's circuit opens, and further calls fail
+// with ErrCircuitOpen without a request until breakerCooldown has passed. Missing records and
+// rate limiting are not failures, rate limited requests are retried by the caller.
+type httpSource struct {
+	name      string
+	limiter   *limiter
+	healthURL string
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	stats     SourceStats
+}
+
+var (
+	sourcesMu sync.Mutex
+	sources   = make(map[string]*httpSource)
+)
+
+// Returns a source named after the option that enables it, making at most one request per
+// interval when interval is not zero.
+func newHTTPSource(name string, interval time.Duration) *httpSource {
+	s := &httpSource{name: name}
+	if interval > 0 {
+		s.limiter = &limiter{interval: interval}
+	}
+	sourcesMu.Lock()
+	sources[name] = s
+	sourcesMu.Unlock()
+	return s
+}
+
+// Metered returns whether the named source makes its requests through an httpSource, and so
+// spends them from the quota set for it by SetQuotaLedger.
+func Metered(name string) bool {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	_, ok := sources[name]
+	return ok
+}
+
+// Sets a page requested by CheckHealth to check that the source is still online.
+func (s *httpSource) withHealthCheck(u string) *httpSource {
+	s.healthURL = u
+	return s
+}
+
+// CheckHealth requests the health check page of the named source, if it has one. An error is
+// returned if the page can not be requested within timeout, or the server fails or says the
+// page is gone.
+func CheckHealth(name string, timeout time.Duration) error {
+	sourcesMu.Lock()
+	s, ok := sources[name]
+	sourcesMu.Unlock()
+	if !ok || s.healthURL == "" {
+		return nil
+	}
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Get(s.healthURL)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("%s returned %s", s.healthURL, resp.Status)
+	}
+	return nil
+}
+
+// HealthChecked returns the names of the sources that have a health check page, sorted.
+func HealthChecked() []string {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	names := []string{}
+	for name, s := range sources {
+		if s.healthURL != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SourceMetrics returns the stats of each source that has been called, by name.
+func SourceMetrics() map[string]SourceStats {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	metrics := make(map[string]SourceStats)
+	for name, s := range sources {
+		s.mu.Lock()
+		if s.stats.Requests > 0 || s.stats.Rejected > 0 {
+			metrics[name] = s.stats
+		}
+		s.mu.Unlock()
+	}
+	return metrics
+}
+
+// FormatSourceMetrics returns a line describing the stats of each source, sorted by name.
+func FormatSourceMetrics(metrics map[string]SourceStats) []string {
+	lines := []string{}
+	for name, m := range metrics {
+		lines = append(lines, fmt.Sprintf("-%s: %d requests, %d failed, %d rate limited, circuit opened %d times, %d calls rejected",
+			name, m.Requests, m.Failures, m.RateLimited, m.CircuitOpen, m.Rejected))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// Returns ErrCircuitOpen if the source's circuit is open.
+func (s *httpSource) allow() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Now().Before(s.openUntil) {
+		s.stats.Rejected++
+		return fmt.Errorf("-%s failed %d times in a row: %w", s.name, breakerThreshold, ErrCircuitOpen)
+	}
+	return nil
+}
+
+// Counts a request and its outcome, opening the circuit after breakerThreshold failures.
+func (s *httpSource) record(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stats.Requests++
+	c := Classify(err)
+	switch {
+	case err == nil || c == ErrNotFound:
+		s.failures = 0
+	case c == ErrRateLimited:
+		s.stats.RateLimited++
+	default:
+		s.stats.Failures++
+		s.failures++
+		if s.failures >= breakerThreshold {
+			s.failures = 0
+			s.openUntil = time.Now().Add(breakerCooldown)
+			s.stats.CircuitOpen++
+		}
+	}
+}
+
+// Runs fn as a single request of the source. Used directly by sources with their own client.
+func (s *httpSource) call(fn func() error) error {
+	if err := s.allow(); err != nil {
+		return err
+	}
+	if s.limiter != nil {
+		s.limiter.wait()
+	}
+	if err := spendQuota(s.name); err != nil {
+		return err
+	}
+	err := fn()
+	s.record(err)
+	return err
+}
+
+// Performs req with client and returns the response body. A response that is not 2xx is
+// returned as an error, and with checkBlock so is a CAPTCHA or block page. A request with a
+// body may be performed again, such as when it is retried.
+func (s *httpSource) fetch(client *http.Client, req *http.Request, checkBlock bool) ([]byte, error) {
+	var body []byte
+	err := s.call(func() error {
+		if req.GetBody != nil {
+			b, err := req.GetBody()
+			if err != nil {
+				return err
+			}
+			req.Body = b
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		body, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return err
+		}
+		if checkBlock && isBlockPage(body) {
+			return &RateLimitError{Status: s.name, RetryAfter: blockPagePause, Blocked: true}
+		}
+		return responseError(resp)
+	})
+	return body, err
+}
+
+// Requests u and returns the response body.
+func (s *httpSource) get(u string) ([]byte, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.fetch(http.DefaultClient, req, false)
+}
+
+// Requests a page for a scraper with client. A CAPTCHA or block page is returned as a rate
+// limit error, so the source is paused rather than silently returning no results.
+func (s *httpSource) scrape(client *http.Client, req *http.Request) (*goquery.Document, error) {
+	body, err := s.fetch(client, req, true)
+	if err != nil {
+		return nil, err
+	}
+	return goquery.NewDocumentFromReader(bytes.NewReader(body))
+}
+
+// Requests u for a scraper.
+func (s *httpSource) scrapeURL(u string) (*goquery.Document, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return s.scrape(http.DefaultClient, req)
+}
@@ -0,0 +1,64 @@
+package bsw
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPSourceBreakerOffline(t *testing.T) {
+	hits := 0
+	status := http.StatusInternalServerError
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(status)
+	}))
+	defer ts.Close()
+	s := newHTTPSource("test-breaker", 0)
+	defer func() {
+		sourcesMu.Lock()
+		delete(sources, "test-breaker")
+		sourcesMu.Unlock()
+	}()
+
+	// A missing record is not a failure and resets the count.
+	for i := 0; i < breakerThreshold-1; i++ {
+		s.get(ts.URL)
+	}
+	status = http.StatusNotFound
+	if _, err := s.get(ts.URL); Classify(err) != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	status = http.StatusInternalServerError
+	for i := 0; i < breakerThreshold; i++ {
+		if _, err := s.get(ts.URL); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("circuit opened after %d failures", i)
+		}
+	}
+	before := hits
+	if _, err := s.get(ts.URL); Classify(err) != ErrCircuitOpen {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if hits != before {
+		t.Error("a request was made while the circuit was open")
+	}
+	m := SourceMetrics()["test-breaker"]
+	if m.Requests != 2*breakerThreshold || m.Failures != 2*breakerThreshold-1 || m.CircuitOpen != 1 || m.Rejected != 1 {
+		t.Errorf("unexpected metrics %+v", m)
+	}
+}
+
+func TestHTTPSourceScrapeBlockedOffline(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><div class="g-recaptcha"></div></html>`))
+	}))
+	defer ts.Close()
+	s := &httpSource{name: "test-scrape"}
+	if _, err := s.scrapeURL(ts.URL); !IsBlocked(err) {
+		t.Errorf("expected a block page error, got %v", err)
+	}
+	if s.stats.RateLimited != 1 || s.stats.Failures != 0 {
+		t.Errorf("a block page should be counted as rate limiting, got %+v", s.stats)
+	}
+}
@@ -0,0 +1,133 @@
+package bsw
+
+import (
+	"net"
+	"strings"
+)
+
+// The most include and redirect lookups followed from a domain's SPF record, the limit SPF
+// itself places on evaluation.
+const spfMaxLookups = 10
+
+// spfMechanism is a mechanism or modifier of an SPF record that names a host or network.
+type spfMechanism struct {
+	Name  string
+	Value string
+}
+
+// Parses the mechanisms of an SPF record that name a host or network: a, mx, ip4, ip6,
+// include, and the redirect modifier. Qualifiers and the dual CIDR lengths of a and mx are
+// dropped, and a and mx without a domain name the record's own. Domains using macros are
+// skipped. Returns nil if record is not an SPF record.
+func parseSPF(record, owner string) []spfMechanism {
+	fields := strings.Fields(record)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "v=spf1") {
+		return nil
+	}
+	mechanisms := []spfMechanism{}
+	for _, f := range fields[1:] {
+		f = strings.TrimLeft(f, "+-~?")
+		name, value := f, ""
+		if i := strings.IndexAny(f, ":="); i >= 0 {
+			name, value = f[:i], f[i+1:]
+		} else if i := strings.Index(f, "/"); i >= 0 {
+			name = f[:i]
+		}
+		name = strings.ToLower(name)
+		switch name {
+		case "a", "mx":
+			if i := strings.Index(value, "/"); i >= 0 {
+				value = value[:i]
+			}
+			if value == "" {
+				value = owner
+			}
+		case "include", "redirect", "ip4", "ip6":
+			if value == "" {
+				continue
+			}
+		default:
+			continue
+		}
+		if strings.Contains(value, "%") {
+			continue
+		}
+		mechanisms = append(mechanisms, spfMechanism{Name: name, Value: strings.TrimRight(value, ".")})
+	}
+	return mechanisms
+}
+
+// Returns the network of an ip4 or ip6 mechanism in CIDR notation.
+func spfNetblock(value string) string {
+	if ip := net.ParseIP(value); ip != nil {
+		if ip.To4() != nil {
+			return ip.String() + "/32"
+		}
+		return ip.String() + "/128"
+	}
+	if _, network, err := net.ParseCIDR(value); err == nil {
+		return network.String()
+	}
+	return ""
+}
+
+// TXT looks up the TXT records of a domain and follows the includes and redirects of its SPF
+// record. The hosts named by a and mx mechanisms are returned with their ip. Networks named by
+// ip4 and ip6 mechanisms are returned with the record's domain as hostname, no ip, and the
+// network in "netblock" metadata. Each result records the mechanism in "spf" metadata and
+// the domain of the record it was found in as "spf_record".
+func TXT(domain, serverAddr string) (string, Results, error) {
+	task := "txt"
+	results := Results{}
+	domain = strings.ToLower(strings.TrimRight(domain, "."))
+	queue := []string{domain}
+	seen := map[string]bool{domain: true}
+	lookups := 0
+	for len(queue) > 0 {
+		owner := queue[0]
+		queue = queue[1:]
+		records, err := LookupTXT(owner, serverAddr)
+		if err != nil {
+			if owner == domain {
+				return task, results, err
+			}
+			continue
+		}
+		for _, record := range records {
+			for _, m := range parseSPF(record, owner) {
+				meta := map[string]string{"spf": m.Name, "spf_record": owner}
+				switch m.Name {
+				case "include", "redirect":
+					name := strings.ToLower(m.Value)
+					if seen[name] || lookups >= spfMaxLookups {
+						continue
+					}
+					seen[name] = true
+					lookups++
+					queue = append(queue, name)
+				case "ip4", "ip6":
+					if netblock := spfNetblock(m.Value); netblock != "" {
+						meta["netblock"] = netblock
+						results = append(results, Result{Source: task, Hostname: owner, Meta: meta})
+					}
+				case "a":
+					if ip, answer, err := lookupNameOrCname(m.Value, serverAddr); err == nil && ip != "" {
+						results = append(results, Result{Source: task, IP: ip, Hostname: m.Value, Meta: mergeMeta(meta, answer)})
+					}
+				case "mx":
+					servers, err := LookupMX(m.Value, serverAddr)
+					if err != nil {
+						continue
+					}
+					for _, s := range servers {
+						host := strings.TrimRight(s, ".")
+						if ip, answer, err := lookupNameOrCname(host, serverAddr); err == nil && ip != "" {
+							results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: mergeMeta(meta, answer)})
+						}
+					}
+				}
+			}
+		}
+	}
+	return task, results, nil
+}
@@ -0,0 +1,48 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestParseSPFOffline(t *testing.T) {
+	record := "v=spf1 a mx:mail.example.com/24 -a:Relay.example.com. ip4:192.0.2.0/24 ip6:2001:db8::1 " +
+		"include:_spf.example.net ~include:%{i}._spf.example.org exists:example.com redirect=spf.example.com ~all"
+	want := []spfMechanism{
+		{"a", "example.com"},
+		{"mx", "mail.example.com"},
+		{"a", "Relay.example.com"},
+		{"ip4", "192.0.2.0/24"},
+		{"ip6", "2001:db8::1"},
+		{"include", "_spf.example.net"},
+		{"redirect", "spf.example.com"},
+	}
+	got := parseSPF(record, "example.com")
+	if len(got) != len(want) {
+		t.Fatalf("unexpected mechanisms %v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("mechanism %d is %v, expected %v", i, got[i], want[i])
+		}
+	}
+	if parseSPF("google-site-verification=abc", "example.com") != nil {
+		t.Error("non SPF record was parsed")
+	}
+	if n := spfNetblock("2001:db8::1"); n != "2001:db8::1/128" {
+		t.Errorf("unexpected netblock %s", n)
+	}
+	if n := spfNetblock("192.0.2.7/24"); n != "192.0.2.0/24" {
+		t.Errorf("unexpected netblock %s", n)
+	}
+}
+
+func TestTXT(t *testing.T) {
+	_, results, err := TXT("google.com", "8.8.8.8")
+	if err != nil {
+		t.Error("error returned from TXT")
+		t.Log(err)
+	}
+	if len(results) < 1 {
+		t.Error("no results from TXT")
+	}
+}
@@ -1,35 +1,25 @@
 package bsw
 
-// SRV iterates over a list of common SRV records, returning hostname and IP results for each.
+// SRV iterates over the built in list of common SRV records, returning hostname and IP results for each.
 func SRV(domain, dnsServer string) (string, Results, error) {
 	task := "SRV"
 	results := Results{}
-	srvrcdarr := [...]string{"_gc._tcp.", "_kerberos._tcp.", "_kerberos._udp.", "_ldap._tcp.",
-		"_test._tcp.", "_sips._tcp.", "_sip._udp.", "_sip._tcp.", "_aix._tcp.",
-		"_aix._tcp.", "_finger._tcp.", "_ftp._tcp.", "_http._tcp.", "_nntp._tcp.",
-		"_telnet._tcp.", "_whois._tcp.", "_h323cs._tcp.", "_h323cs._udp.",
-		"_h323be._tcp.", "_h323be._udp.", "_h323ls._tcp.", "_https._tcp.",
-		"_h323ls._udp.", "_sipinternal._tcp.", "_sipinternaltls._tcp.",
-		"_sip._tls.", "_sipfederationtls._tcp.", "_jabber._tcp.",
-		"_xmpp-server._tcp.", "_xmpp-client._tcp.", "_imap.tcp.",
-		"_certificates._tcp.", "_crls._tcp.", "_pgpkeys._tcp.",
-		"_pgprevokations._tcp.", "_cmp._tcp.", "_svcp._tcp.", "_crl._tcp.",
-		"_ocsp._tcp.", "_PKIXREP._tcp.", "_smtp._tcp.", "_hkp._tcp.",
-		"_hkps._tcp.", "_jabber._udp.", "_xmpp-server._udp.", "_xmpp-client._udp.",
-		"_jabber-client._tcp.", "_jabber-client._udp.", "_kpasswd._tcp.", "_kpasswd._udp.",
-		"_imap._tcp."}
+	srvNames, err := readWordlist("srv")
+	if err != nil {
+		return task, results, err
+	}
 
-	for _, value := range srvrcdarr {
-		fqdn := value + domain
+	for _, value := range srvNames {
+		fqdn := value + "." + domain
 		srvTarget, err := LookupSRV(fqdn, dnsServer)
 		if err != nil {
 			continue
 		}
-		ip, err := LookupName(srvTarget, dnsServer)
+		ip, answer, err := lookupName(srvTarget, dnsServer)
 		if err != nil {
 			continue
 		}
-		results = append(results, Result{Source: task, IP: ip, Hostname: srvTarget})
+		results = append(results, Result{Source: task, IP: ip, Hostname: srvTarget, Meta: answer})
 	}
 	return task, results, nil
 }
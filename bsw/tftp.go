@@ -0,0 +1,128 @@
+package bsw
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// Configuration files commonly served to network devices regardless of their name.
+var tftpCommonFiles = []string{"network-confg", "router-confg", "switch-confg", "startup-config", "running-config"}
+
+// Upper bound on the size of a file retrieved over TFTP.
+const tftpMaxSize = 1 << 20
+
+// TFTPFilenames returns a list of configuration filenames to request from a TFTP server, derived from
+// the hostnames previously identified for it.
+func TFTPFilenames(hostnames []string) []string {
+	seen := make(map[string]bool)
+	files := []string{}
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			files = append(files, name)
+		}
+	}
+	for _, h := range hostnames {
+		short := strings.Split(h, ".")[0]
+		for _, name := range []string{short, h} {
+			add(name + "-confg")
+			add(name + ".cfg")
+			add(name + ".conf")
+		}
+	}
+	for _, name := range tftpCommonFiles {
+		add(name)
+	}
+	return files
+}
+
+// TFTP attempts to retrieve each file from a TFTP server on port 69 and parses any retrieved
+// configurations for hostnames. Only hostnames that resolve are returned.
+func TFTP(ip string, files []string, serverAddr string, timeout int64) (string, Results, error) {
+	task := "TFTP"
+	results := Results{}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		data, err := tftpGet(ip+":69", file, timeout)
+		if err != nil {
+			continue
+		}
+		for _, h := range hostnamesFromText(string(data)) {
+			if seen[h] {
+				continue
+			}
+			seen[h] = true
+			hip, answer, err := lookupNameOrCname(h, serverAddr)
+			if err != nil || hip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: hip, Hostname: h, Meta: answer})
+		}
+	}
+	return task, results, nil
+}
+
+// Performs a TFTP read request in octet mode, returning the contents of the file.
+func tftpGet(addr, file string, timeout int64) ([]byte, error) {
+	raddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenUDP("udp", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	t := time.Duration(timeout) * time.Millisecond
+
+	req := []byte{0, 1}
+	req = append(req, file...)
+	req = append(req, 0)
+	req = append(req, "octet"...)
+	req = append(req, 0)
+	if _, err := conn.WriteToUDP(req, raddr); err != nil {
+		return nil, err
+	}
+
+	var data bytes.Buffer
+	var block uint16 = 1
+	buf := make([]byte, 516)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(t)); err != nil {
+			return nil, err
+		}
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return nil, err
+		}
+		if n < 4 || !from.IP.Equal(raddr.IP) {
+			continue
+		}
+		switch binary.BigEndian.Uint16(buf) {
+		case 3:
+			if binary.BigEndian.Uint16(buf[2:]) != block {
+				continue
+			}
+			data.Write(buf[4:n])
+			ack := []byte{0, 4, buf[2], buf[3]}
+			if _, err := conn.WriteToUDP(ack, from); err != nil {
+				return nil, err
+			}
+			if n < 516 {
+				return data.Bytes(), nil
+			}
+			if data.Len() > tftpMaxSize {
+				return nil, errors.New("TFTP file exceeds maximum size")
+			}
+			block++
+		case 5:
+			return nil, errors.New("TFTP error: " + strings.TrimRight(string(buf[4:n]), "\x00"))
+		default:
+			return nil, errors.New("unexpected TFTP opcode")
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package bsw
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+func TestTFTPFilenames(t *testing.T) {
+	files := TFTPFilenames([]string{"rtr01.corp.example.com"})
+	found := false
+	for _, f := range files {
+		if f == "rtr01-confg" {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("TFTPFilenames did not return short name config")
+		t.Log(files)
+	}
+}
+
+func TestTFTPGet(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	config := "hostname rtr01\nip domain-name corp.example.com\nlogging host syslog01.corp.example.com\n"
+	go func() {
+		buf := make([]byte, 516)
+		_, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		conn.WriteToUDP(append([]byte{0, 3, 0, 1}, config...), from)
+		conn.ReadFromUDP(buf)
+	}()
+	data, err := tftpGet(conn.LocalAddr().String(), "rtr01-confg", 2000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "syslog01.corp.example.com") {
+		t.Error("tftpGet returned incorrect data")
+		t.Log(string(data))
+	}
+}
@@ -0,0 +1,85 @@
+package bsw
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+type theHiveObservable struct {
+	DataType string   `json:"dataType"`
+	Data     string   `json:"data"`
+	Tags     []string `json:"tags,omitempty"`
+}
+
+type theHiveAlert struct {
+	Type        string              `json:"type"`
+	Source      string              `json:"source"`
+	SourceRef   string              `json:"sourceRef"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Observables []theHiveObservable `json:"observables"`
+}
+
+// TheHiveAlert raises a single alert using TheHive's '/api/v1/alert' REST API, with an fqdn
+// observable for each unique hostname and an ip observable for each unique IP in results.
+// Observables are tagged with the sources that found them.
+func TheHiveAlert(baseURL, key string, results Results) error {
+	alert := theHiveAlert{
+		Type:        "recon",
+		Source:      "blacksheepwall",
+		SourceRef:   fmt.Sprintf("blacksheepwall-%d", time.Now().UnixNano()),
+		Title:       fmt.Sprintf("blacksheepwall: %d new findings", len(results)),
+		Description: "Hostnames and IP addresses found by blacksheepwall " + VERSION + ".",
+	}
+	index := make(map[[2]string]int)
+	seen := make(map[[3]string]bool)
+	add := func(dataType, data, source string) {
+		if data == "" {
+			return
+		}
+		if data == "" {
+			return
+		}
+		key := [2]string{dataType, data}
+		i, ok := index[key]
+		if !ok {
+			i = len(alert.Observables)
+			index[key] = i
+			alert.Observables = append(alert.Observables, theHiveObservable{DataType: dataType, Data: data})
+		}
+		if !seen[[3]string{dataType, data, source}] {
+			seen[[3]string{dataType, data, source}] = true
+			alert.Observables[i].Tags = append(alert.Observables[i].Tags, "src:"+source)
+		}
+	}
+	for _, r := range results {
+		add("fqdn", r.Hostname, r.Source)
+		add("ip", r.IP, r.Source)
+	}
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", strings.TrimRight(baseURL, "/")+"/api/v1/alert", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+key)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("TheHive returned %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTheHiveAlert(t *testing.T) {
+	var alert theHiveAlert
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/alert" || r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewDecoder(r.Body).Decode(&alert)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer ts.Close()
+
+	results := Results{
+		{Source: "Reverse", IP: "192.0.2.1", Hostname: "www.example.com"},
+		{Source: "TLS Certificate", IP: "192.0.2.1", Hostname: "example.com"},
+	}
+	if err := TheHiveAlert(ts.URL, "secret", results); err != nil {
+		t.Fatal(err)
+	}
+	if len(alert.Observables) != 3 {
+		t.Error("TheHiveAlert sent incorrect number of observables")
+		t.Log(alert.Observables)
+	}
+	if err := TheHiveAlert(ts.URL, "wrong", results); err == nil {
+		t.Error("TheHiveAlert did not return error for bad key")
+	}
+}
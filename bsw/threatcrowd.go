@@ -0,0 +1,85 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const threatCrowdURL = "https://www.threatcrowd.org/searchApi/v2"
+
+// ThreatCrowd's terms of use ask for no more than one request every ten seconds.
+var threatCrowdSource = newHTTPSource("threatcrowd", 10*time.Second)
+
+type threatCrowdMessage struct {
+	ResponseCode string `json:"response_code"`
+	Resolutions  []struct {
+		IPAddress string `json:"ip_address"`
+		Domain    string `json:"domain"`
+	} `json:"resolutions"`
+	Subdomains []string `json:"subdomains"`
+}
+
+// Requests a ThreatCrowd report. A response code other than "1" means there is no report.
+func threatCrowdReport(path, param, value string) (*threatCrowdMessage, error) {
+	body, err := threatCrowdSource.get(threatCrowdURL + path + "?" + param + "=" + url.QueryEscape(value))
+	if err != nil {
+		return nil, err
+	}
+	m := &threatCrowdMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return nil, err
+	}
+	if m.ResponseCode != "1" {
+		return &threatCrowdMessage{}, nil
+	}
+	return m, nil
+}
+
+// ThreatCrowdDomain uses ThreatCrowd's domain report to find ips the domain has resolved to, and
+// subdomains of the domain, returning those that resolve.
+func ThreatCrowdDomain(domain, serverAddr string) (string, Results, error) {
+	task := "threatcrowd"
+	results := Results{}
+	m, err := threatCrowdReport("/domain/report/", "domain", domain)
+	if err != nil {
+		return task, results, err
+	}
+	for _, r := range m.Resolutions {
+		if r.IPAddress == "" || r.IPAddress == "-" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: r.IPAddress, Hostname: domain})
+	}
+	for _, name := range m.Subdomains {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != domain && !strings.HasSuffix(name, "."+domain) {
+			continue
+		}
+		ip, answer, err := lookupNameOrCname(name, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, nil
+}
+
+// ThreatCrowdIP uses ThreatCrowd's IP report to find hostnames that have resolved to an IP.
+func ThreatCrowdIP(ip string) (string, Results, error) {
+	task := "threatcrowd"
+	results := Results{}
+	m, err := threatCrowdReport("/ip/report/", "ip", ip)
+	if err != nil {
+		return task, results, err
+	}
+	for _, r := range m.Resolutions {
+		hostname := strings.ToLower(strings.TrimSpace(r.Domain))
+		if hostname == "" || hostname == ip {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+	}
+	return task, results, nil
+}
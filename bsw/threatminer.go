@@ -0,0 +1,95 @@
+package bsw
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const threatMinerURL = "https://api.threatminer.org/v2"
+
+// ThreatMiner allows ten requests a minute.
+var threatMinerSource = newHTTPSource("threatminer", 6*time.Second)
+
+type threatMinerMessage struct {
+	StatusCode    string          `json:"status_code"`
+	StatusMessage string          `json:"status_message"`
+	Results       json.RawMessage `json:"results"`
+}
+
+// Requests a ThreatMiner report type (rt) and unmarshals its results into v. A status code of
+// 404 means there are no results and leaves v unchanged.
+func threatMinerReport(endpoint, query, rt string, v interface{}) error {
+	body, err := threatMinerSource.get(threatMinerURL + endpoint + "?q=" + url.QueryEscape(query) + "&rt=" + rt)
+	if err != nil {
+		return err
+	}
+	m := &threatMinerMessage{}
+	if err := json.Unmarshal(body, m); err != nil {
+		return err
+	}
+	switch m.StatusCode {
+	case "200":
+		return json.Unmarshal(m.Results, v)
+	case "404":
+		return nil
+	}
+	return fmt.Errorf("threatminer: %s %s", m.StatusCode, m.StatusMessage)
+}
+
+// ThreatMinerDomain uses ThreatMiner's domain passive DNS and subdomain reports to find ips and
+// hostnames for a domain. Subdomains are returned if they resolve.
+func ThreatMinerDomain(domain, serverAddr string) (string, Results, error) {
+	task := "threatminer"
+	results := Results{}
+	var pdns []struct {
+		IP string `json:"ip"`
+	}
+	if err := threatMinerReport("/domain.php", domain, "2", &pdns); err != nil {
+		return task, results, err
+	}
+	for _, r := range pdns {
+		if r.IP != "" {
+			results = append(results, Result{Source: task, IP: r.IP, Hostname: domain})
+		}
+	}
+	var subdomains []string
+	if err := threatMinerReport("/domain.php", domain, "5", &subdomains); err != nil {
+		return task, results, err
+	}
+	for _, name := range subdomains {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != domain && !strings.HasSuffix(name, "."+domain) {
+			continue
+		}
+		ip, answer, err := lookupNameOrCname(name, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, nil
+}
+
+// ThreatMinerIP uses ThreatMiner's host passive DNS report to find hostnames that have resolved
+// to an IP.
+func ThreatMinerIP(ip string) (string, Results, error) {
+	task := "threatminer"
+	results := Results{}
+	var pdns []struct {
+		Domain string `json:"domain"`
+	}
+	if err := threatMinerReport("/host.php", ip, "2", &pdns); err != nil {
+		return task, results, err
+	}
+	for _, r := range pdns {
+		hostname := strings.ToLower(strings.TrimSpace(r.Domain))
+		if hostname == "" || hostname == ip {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
+	}
+	return task, results, nil
+}
@@ -6,29 +6,58 @@ import (
 	"time"
 )
 
+// Client configuration used when connecting to hosts with TLS.
+var clientTLSConfig = &tls.Config{InsecureSkipVerify: true}
+
+// SetTLSConfig sets the client configuration used by the TLS, Headers, and SMTP tasks, such as to
+// present a client certificate or allow old protocol versions and cipher suites. Certificates are
+// collected rather than verified, so InsecureSkipVerify is always set. It should be called
+// before any tasks are run.
+func SetTLSConfig(c *tls.Config) {
+	c = c.Clone()
+	c.InsecureSkipVerify = true
+	clientTLSConfig = c
+}
+
 // TLS attempts connection to an IP using TLS on port 443, and if successfull, will parse the server
 // certificate for CommonName and SubjectAlt names.
 func TLS(ip string, timeout int64) (string, Results, error) {
 	task := "TLS Certificate"
 	results := Results{}
-	tconn, err := net.Dial("tcp", ip+":443")
+	conn, err := tlsHandshake(net.JoinHostPort(ip, "443"), timeout)
 	if err != nil {
 		return task, results, err
 	}
+	defer conn.Close()
+	return task, tlsCertResults(task, ip, 443, conn), nil
+}
+
+// Connects to addr and completes a TLS handshake without verifying the certificate.
+func tlsHandshake(addr string, timeout int64) (*tls.Conn, error) {
+	tconn, err := net.DialTimeout("tcp", addr, time.Duration(timeout)*time.Millisecond)
+	if err != nil {
+		return nil, err
+	}
 	t := time.Duration(timeout) * time.Millisecond
 	if err := tconn.SetDeadline(time.Now().Add(t)); err != nil {
-		return task, results, err
+		tconn.Close()
+		return nil, err
 	}
-	conn := tls.Client(tconn, &tls.Config{InsecureSkipVerify: true})
-	defer conn.Close()
+	conn := tls.Client(tconn, clientTLSConfig)
 	if err := conn.Handshake(); err != nil {
-		return task, results, err
+		conn.Close()
+		return nil, err
 	}
-	state := conn.ConnectionState()
-	cert := state.PeerCertificates[0]
-	results = append(results, Result{Source: task, IP: ip, Hostname: cert.Subject.CommonName})
+	return conn, nil
+}
+
+// Returns the CommonName and SubjectAlt names of the certificate presented on conn.
+func tlsCertResults(task, ip string, port int, conn *tls.Conn) Results {
+	results := Results{}
+	cert := conn.ConnectionState().PeerCertificates[0]
+	results = append(results, Result{Source: task, IP: ip, Hostname: cert.Subject.CommonName, Port: port, Protocol: "tcp"})
 	for _, name := range cert.DNSNames {
-		results = append(results, Result{Source: task, IP: ip, Hostname: name})
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Port: port, Protocol: "tcp"})
 	}
-	return task, results, nil
+	return results
 }
@@ -0,0 +1,128 @@
+package bsw
+
+import (
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+)
+
+// Lookalike is a permutation of a domain that could be registered to impersonate it.
+type Lookalike struct {
+	Domain string
+	Kind   string
+}
+
+// Characters that look like others, as ASCII and as Unicode, whose names are converted to
+// punycode.
+var homoglyphs = map[rune][]string{
+	'a': {"4", "а", "à", "á", "ä"},
+	'b': {"d", "lb", "ь"},
+	'c': {"e", "с", "ç"},
+	'd': {"b", "cl", "ԁ"},
+	'e': {"c", "3", "е", "é", "ë"},
+	'g': {"q", "9", "ɡ"},
+	'h': {"lh", "һ"},
+	'i': {"1", "l", "і", "í", "ï"},
+	'j': {"ј"},
+	'k': {"lk", "κ"},
+	'l': {"1", "i", "ӏ"},
+	'm': {"n", "rn", "nn", "м"},
+	'n': {"m", "r", "п"},
+	'o': {"0", "о", "ο", "ö", "ó"},
+	'p': {"р", "ρ"},
+	'q': {"g", "ԛ"},
+	's': {"5", "ѕ"},
+	'u': {"v", "υ", "ü", "ú"},
+	'v': {"u", "ν"},
+	'w': {"vv", "ԝ"},
+	'x': {"х"},
+	'y': {"у", "ý"},
+	'z': {"2", "ʐ"},
+}
+
+// Top level domains swapped in for the public suffix of a domain.
+var lookalikeTLDs = []string{
+	"com", "net", "org", "info", "biz", "co", "io", "us", "uk", "co.uk", "de", "eu", "ca",
+	"me", "app", "online", "site", "xyz", "top", "shop", "cc", "tv", "ru", "cn",
+}
+
+// Returns whether s is a valid hostname label in ASCII.
+func isLabel(s string) bool {
+	if s == "" || len(s) > 63 || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+	for _, c := range s {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// LookalikeDomains returns permutations of the registrable domain of domain: each character
+// replaced by a homoglyph, each character with a single bit flipped, and the public suffix
+// replaced by common top level domains. Unicode permutations are returned in punycode.
+func LookalikeDomains(domain string) ([]Lookalike, error) {
+	apex, err := publicsuffix.EffectiveTLDPlusOne(strings.ToLower(strings.TrimRight(domain, ".")))
+	if err != nil {
+		return nil, err
+	}
+	i := strings.Index(apex, ".")
+	label, suffix := apex[:i], apex[i+1:]
+	lookalikes := []Lookalike{}
+	seen := map[string]bool{apex: true}
+	add := func(name, kind string) {
+		ascii := punycode(name)
+		if seen[ascii] {
+			return
+		}
+		for _, l := range strings.Split(ascii, ".") {
+			if !isLabel(l) {
+				return
+			}
+		}
+		seen[ascii] = true
+		lookalikes = append(lookalikes, Lookalike{Domain: ascii, Kind: kind})
+	}
+	runes := []rune(label)
+	for i, r := range runes {
+		for _, glyph := range homoglyphs[r] {
+			add(string(runes[:i])+glyph+string(runes[i+1:])+"."+suffix, "homoglyph")
+		}
+	}
+	for i := 0; i < len(label); i++ {
+		for bit := uint(0); bit < 7; bit++ {
+			c := label[i] ^ (1 << bit)
+			if c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' {
+				add(label[:i]+string(c)+label[i+1:]+"."+suffix, "bitflip")
+			}
+		}
+	}
+	for _, tld := range lookalikeTLDs {
+		if tld != suffix {
+			add(label+"."+tld, "tld")
+		}
+	}
+	return lookalikes, nil
+}
+
+// Typosquat checks whether a lookalike of domain is registered, by looking up its NS records,
+// and whether it resolves. Registered lookalikes are returned with their ip if they resolve.
+// The kind of permutation and the domain it imitates are recorded in "typosquat" and
+// "lookalike_of" metadata, and "live" is set when it resolves.
+func Typosquat(domain string, lookalike Lookalike, serverAddr string) (string, Results, error) {
+	task := "typosquat"
+	results := Results{}
+	meta := map[string]string{"typosquat": lookalike.Kind, "lookalike_of": domain}
+	ip, answer, err := lookupNameOrCname(lookalike.Domain, serverAddr)
+	if err == nil && ip != "" {
+		meta["live"] = "true"
+		results = append(results, Result{Source: task, IP: ip, Hostname: lookalike.Domain, Meta: mergeMeta(meta, answer)})
+		return task, results, nil
+	}
+	if _, err := LookupNS(lookalike.Domain, serverAddr); err != nil {
+		return task, results, nil
+	}
+	results = append(results, Result{Source: task, Hostname: lookalike.Domain, Meta: meta})
+	return task, results, nil
+}
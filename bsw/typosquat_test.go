@@ -0,0 +1,45 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestLookalikeDomainsOffline(t *testing.T) {
+	lookalikes, err := LookalikeDomains("www.example.co.uk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kinds := make(map[string]string)
+	for _, l := range lookalikes {
+		if l.Domain == "example.co.uk" {
+			t.Error("the domain itself was returned")
+		}
+		kinds[l.Domain] = l.Kind
+	}
+	for domain, kind := range map[string]string{
+		"examp1e.co.uk":        "homoglyph",
+		"xn--xample-2of.co.uk": "homoglyph",
+		"exampme.co.uk":        "bitflip",
+		"example.com":          "tld",
+	} {
+		if kinds[domain] != kind {
+			t.Errorf("expected %s lookalike %s, got %q", kind, domain, kinds[domain])
+		}
+	}
+	if _, err := LookalikeDomains("localhost"); err == nil {
+		t.Error("no error for a name without a registrable domain")
+	}
+}
+
+func TestPunycodeOffline(t *testing.T) {
+	for name, want := range map[string]string{
+		"bücher.example": "xn--bcher-kva.example",
+		"münchen":        "xn--mnchen-3ya",
+		"ехаmple.com":    "xn--mple-43d3a6i.com",
+		"example.com":    "example.com",
+	} {
+		if got := punycode(name); got != want {
+			t.Errorf("punycode(%q) is %s, expected %s", name, got, want)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package bsw
 
 import (
 	"encoding/json"
-	"io/ioutil"
-	"net/http"
+	"strconv"
+	"strings"
+)
 
-	"github.com/PuerkitoBio/goquery"
+// viewdns.info's API, and the scraper of its Reverse IP page.
+var (
+	viewDNSSource     = newHTTPSource("viewdns", 0)
+	viewDNSHTMLSource = newHTTPSource("viewdns-html", 0)
 )
 
 // Very long selector...
@@ -16,18 +20,13 @@ const viewDNSSelector = "#null > tbody:nth-child(1) > tr:nth-child(3) > td:nth-c
 func ViewDNSInfo(ip string) (string, Results, error) {
 	task := "viewdns.info"
 	results := Results{}
-	resp, err := http.Get("http://viewdns.info/reverseip/?host=" + ip + "&t=1")
+	doc, err := viewDNSHTMLSource.scrapeURL("http://viewdns.info/reverseip/?host=" + ip + "&t=1")
 	if err != nil {
 		return task, results, err
 	}
-	defer resp.Body.Close()
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
-	if err != nil {
-		return task, results, err
+	for _, hostname := range extract("viewdns-html", doc) {
+		results = append(results, Result{Source: task, IP: ip, Hostname: hostname})
 	}
-	doc.Selection.Find(viewDNSSelector).Each(func(_ int, s *goquery.Selection) {
-		results = append(results, Result{Source: task, IP: ip, Hostname: s.Text()})
-	})
 	return task, results, nil
 }
 
@@ -49,12 +48,7 @@ type viewDNSInfoMessage struct {
 func ViewDNSInfoAPI(ip, key string) (string, Results, error) {
 	task := "viewdns.info API"
 	results := Results{}
-	resp, err := http.Get("http://pro.viewdns.info/reverseip/?host=" + ip + "&apikey=" + key + "&output=json")
-	if err != nil {
-		return task, results, err
-	}
-	defer resp.Body.Close()
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := viewDNSSource.get("http://pro.viewdns.info/reverseip/?host=" + ip + "&apikey=" + key + "&output=json")
 	if err != nil {
 		return task, results, err
 	}
@@ -72,3 +66,69 @@ func ViewDNSInfoAPI(ip, key string) (string, Results, error) {
 	}
 	return task, results, nil
 }
+
+type viewDNSReverseMessage struct {
+	Response struct {
+		TotalPages string            `json:"total_pages"`
+		Domains    []json.RawMessage `json:"domains"`
+	} `json:"response"`
+}
+
+// Returns the domain names in a reversemx or reversens response, which are either strings or
+// objects with a domain field.
+func (m *viewDNSReverseMessage) domains() []string {
+	domains := []string{}
+	for _, raw := range m.Response.Domains {
+		var name string
+		if err := json.Unmarshal(raw, &name); err == nil {
+			domains = append(domains, name)
+			continue
+		}
+		var d struct {
+			Domain string `json:"domain"`
+		}
+		if err := json.Unmarshal(raw, &d); err == nil && d.Domain != "" {
+			domains = append(domains, d.Domain)
+		}
+	}
+	return domains
+}
+
+// Requests every page of a viewdns.info API reverse lookup, returning the domains that resolve.
+func viewDNSReverse(task, tool, param, server, key, serverAddr string) (string, Results, error) {
+	results := Results{}
+	for page := 1; ; page++ {
+		body, err := viewDNSSource.get("http://pro.viewdns.info/" + tool + "/?" + param + "=" + server + "&apikey=" + key + "&output=json&page=" + strconv.Itoa(page))
+		if err != nil {
+			return task, results, err
+		}
+		m := &viewDNSReverseMessage{}
+		if err := json.Unmarshal(body, m); err != nil {
+			return task, results, err
+		}
+		for _, domain := range m.domains() {
+			domain = strings.ToLower(domain)
+			ip, answer, err := lookupNameOrCname(domain, serverAddr)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: domain, Meta: answer})
+		}
+		if total, _ := strconv.Atoi(m.Response.TotalPages); page >= total {
+			break
+		}
+	}
+	return task, results, nil
+}
+
+// ViewDNSReverseMX uses viewdns.info's API and reversemx function to find other domains that use
+// a mail server.
+func ViewDNSReverseMX(mx, key, serverAddr string) (string, Results, error) {
+	return viewDNSReverse("reverse-mx", "reversemx", "mx", mx, key, serverAddr)
+}
+
+// ViewDNSReverseNS uses viewdns.info's API and reversens function to find other domains that use
+// a name server.
+func ViewDNSReverseNS(ns, key, serverAddr string) (string, Results, error) {
+	return viewDNSReverse("reverse-ns", "reversens", "ns", ns, key, serverAddr)
+}
@@ -0,0 +1,95 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+const virusTotalURL = "https://www.virustotal.com/api/v3"
+
+var virusTotalSource = newHTTPSource("virustotal", 0)
+
+type virusTotalMessage struct {
+	Data []struct {
+		ID         string `json:"id"`
+		Attributes struct {
+			HostName       string `json:"host_name"`
+			IPAddress      string `json:"ip_address"`
+			LastDNSRecords []struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"last_dns_records"`
+		} `json:"attributes"`
+	} `json:"data"`
+	Links struct {
+		Next string `json:"next"`
+	} `json:"links"`
+}
+
+// Requests every page of a VirusTotal API relationship, calling fn for each.
+func virusTotalPages(path, key string, fn func(m *virusTotalMessage)) error {
+	next := virusTotalURL + path
+	for next != "" {
+		req, err := http.NewRequest("GET", next, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("x-apikey", key)
+		body, err := virusTotalSource.fetch(http.DefaultClient, req, false)
+		if err != nil {
+			return err
+		}
+		m := &virusTotalMessage{}
+		if err := json.Unmarshal(body, m); err != nil {
+			return err
+		}
+		fn(m)
+		next = m.Links.Next
+	}
+	return nil
+}
+
+// VirusTotalDomain uses VirusTotal's '/domains/{domain}/subdomains' REST API endpoint to find subdomains
+// of a domain. The A records last seen by VirusTotal are used, otherwise the subdomain is resolved.
+func VirusTotalDomain(domain, key, serverAddr string) (string, Results, error) {
+	task := "virustotal"
+	results := Results{}
+	err := virusTotalPages("/domains/"+domain+"/subdomains?limit=40", key, func(m *virusTotalMessage) {
+		for _, d := range m.Data {
+			hostname := strings.ToLower(d.ID)
+			found := false
+			for _, r := range d.Attributes.LastDNSRecords {
+				if r.Type == "A" || r.Type == "AAAA" {
+					found = true
+					results = append(results, Result{Source: task, IP: r.Value, Hostname: hostname})
+				}
+			}
+			if found {
+				continue
+			}
+			ip, answer, err := lookupNameOrCname(hostname, serverAddr)
+			if err != nil || ip == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: hostname, Meta: answer})
+		}
+	})
+	return task, results, err
+}
+
+// VirusTotalIP uses VirusTotal's '/ip_addresses/{ip}/resolutions' REST API endpoint to find hostnames
+// that have resolved to an IP.
+func VirusTotalIP(ip, key string) (string, Results, error) {
+	task := "virustotal"
+	results := Results{}
+	err := virusTotalPages("/ip_addresses/"+ip+"/resolutions?limit=40", key, func(m *virusTotalMessage) {
+		for _, d := range m.Data {
+			if d.Attributes.HostName == "" {
+				continue
+			}
+			results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(d.Attributes.HostName)})
+		}
+	})
+	return task, results, err
+}
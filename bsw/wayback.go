@@ -0,0 +1,59 @@
+package bsw
+
+import (
+	"bufio"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const waybackURL = "http://web.archive.org/cdx/search/cdx"
+
+var waybackSource = newHTTPSource("wayback", 0)
+
+// Wayback uses the Internet Archive's CDX API to find hostnames in archived URLs for a domain and
+// its subdomains. When resolve is true only hostnames that resolve are returned, otherwise
+// hostnames are returned without an IP.
+func Wayback(domain string, resolve bool, serverAddr string) (string, Results, error) {
+	task := "wayback"
+	results := Results{}
+	names := []string{}
+	err := waybackSource.call(func() error {
+		resp, err := http.Get(waybackURL + "?url=*." + domain + "/*&output=text&fl=original&collapse=urlkey")
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if err := responseError(resp); err != nil {
+			return err
+		}
+		domainSet := make(map[string]bool)
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			u, err := url.Parse(strings.TrimSpace(scanner.Text()))
+			if err != nil {
+				continue
+			}
+			name := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+			if domainSet[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+				continue
+			}
+			domainSet[name] = true
+			names = append(names, name)
+		}
+		return scanner.Err()
+	})
+	for _, name := range names {
+		if !resolve {
+			results = append(results, Result{Source: task, Hostname: name})
+			continue
+		}
+		ip, answer, err := lookupNameOrCname(name, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, err
+}
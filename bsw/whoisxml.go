@@ -0,0 +1,101 @@
+package bsw
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+)
+
+const (
+	whoisXMLSubdomainsURL = "https://subdomains.whoisxmlapi.com/api/v1"
+	whoisXMLReverseIPURL  = "https://reverse-ip.whoisxmlapi.com/api/v1"
+)
+
+// WhoisXML's reverse IP API returns at most 300 names a page, the next page starting after the
+// last name of the previous.
+const (
+	whoisXMLPageSize = 300
+	whoisXMLMaxPages = 10
+)
+
+// The subdomains, reverse IP, and reverse WHOIS APIs share a key and its credits.
+var whoisXMLSource = newHTTPSource("whoisxml", 0)
+
+type whoisXMLSubdomainsMessage struct {
+	Result struct {
+		Records []struct {
+			Domain string `json:"domain"`
+		} `json:"records"`
+	} `json:"result"`
+}
+
+type whoisXMLReverseIPMessage struct {
+	Result []struct {
+		Name string `json:"name"`
+	} `json:"result"`
+}
+
+// Requests a WhoisXML API, decoding the response into m.
+func whoisXMLGet(base string, v url.Values, m interface{}) error {
+	body, err := whoisXMLSource.get(base + "?" + v.Encode())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, m)
+}
+
+// WhoisXMLSubdomains uses WhoisXML's subdomains lookup API to find subdomains of a domain,
+// returning those that resolve.
+func WhoisXMLSubdomains(domain, key, serverAddr string) (string, Results, error) {
+	task := "whoisxml"
+	results := Results{}
+	v := url.Values{}
+	v.Set("apiKey", key)
+	v.Set("domainName", domain)
+	m := &whoisXMLSubdomainsMessage{}
+	if err := whoisXMLGet(whoisXMLSubdomainsURL, v, m); err != nil {
+		return task, results, err
+	}
+	domainSet := make(map[string]bool)
+	for _, record := range m.Result.Records {
+		name := strings.ToLower(strings.TrimSuffix(record.Domain, "."))
+		if domainSet[name] || (name != domain && !strings.HasSuffix(name, "."+domain)) {
+			continue
+		}
+		domainSet[name] = true
+		ip, answer, err := lookupNameOrCname(name, serverAddr)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: name, Meta: answer})
+	}
+	return task, results, nil
+}
+
+// WhoisXMLReverseIP uses WhoisXML's reverse IP API to find hostnames for an IP. At most
+// whoisXMLMaxPages are requested.
+func WhoisXMLReverseIP(ip, key string) (string, Results, error) {
+	task := "whoisxml"
+	results := Results{}
+	from := ""
+	for page := 0; page < whoisXMLMaxPages; page++ {
+		v := url.Values{}
+		v.Set("apiKey", key)
+		v.Set("ip", ip)
+		if from != "" {
+			v.Set("from", from)
+		}
+		m := &whoisXMLReverseIPMessage{}
+		if err := whoisXMLGet(whoisXMLReverseIPURL, v, m); err != nil {
+			return task, results, err
+		}
+		for _, r := range m.Result {
+			results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(strings.TrimSuffix(r.Name, "."))})
+		}
+		if len(m.Result) < whoisXMLPageSize {
+			break
+		}
+		from = m.Result[len(m.Result)-1].Name
+	}
+	return task, results, nil
+}
@@ -0,0 +1,70 @@
+package bsw
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sources of results holding names read from certificates presented by a host.
+var certSources = map[string]bool{"TLS Certificate": true, "SMTP": true}
+
+// WildcardCertDomains returns the domains of wildcard names found in certificates presented by a
+// host, e.g. example.com for *.example.com.
+func WildcardCertDomains(results Results) []string {
+	seen := make(map[string]bool)
+	domains := []string{}
+	for _, r := range results {
+		if !certSources[r.Source] {
+			continue
+		}
+		name := strings.ToLower(strings.TrimRight(r.Hostname, "."))
+		if !strings.HasPrefix(name, "*.") {
+			continue
+		}
+		d := strings.TrimPrefix(name, "*.")
+		if strings.Contains(d, ".") && !seen[d] {
+			seen[d] = true
+			domains = append(domains, d)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// MarkWildcardCerts returns a copy of results where each hostname covered by a wildcard name
+// from a certificate has the wildcard in its wildcard_cert metadata. As with certificate
+// validation, a wildcard covers a single label, so *.example.com covers www.example.com but not
+// example.com or a.www.example.com.
+func MarkWildcardCerts(results Results) Results {
+	marked := append(Results{}, results...)
+	domains := WildcardCertDomains(results)
+	if len(domains) == 0 {
+		return marked
+	}
+	for i, r := range marked {
+		marked[i] = MarkWildcardCert(r, domains)
+	}
+	return marked
+}
+
+// MarkWildcardCert returns r with the wildcard covering its hostname in its wildcard_cert
+// metadata, if it is covered by one of domains as returned by WildcardCertDomains.
+func MarkWildcardCert(r Result, domains []string) Result {
+	name := strings.ToLower(strings.TrimRight(r.Hostname, "."))
+	dot := strings.Index(name, ".")
+	if dot < 1 || strings.HasPrefix(name, "*.") {
+		return r
+	}
+	for _, d := range domains {
+		if name[dot+1:] != d {
+			continue
+		}
+		meta := map[string]string{"wildcard_cert": "*." + d}
+		for k, v := range r.Meta {
+			meta[k] = v
+		}
+		r.Meta = meta
+		break
+	}
+	return r
+}
@@ -0,0 +1,34 @@
+package bsw
+
+import (
+	"testing"
+)
+
+func TestMarkWildcardCerts(t *testing.T) {
+	results := Results{
+		Result{Source: "TLS Certificate", IP: "192.0.2.1", Hostname: "*.dev.example.com", Port: 443},
+		Result{Source: "crt.sh", IP: "", Hostname: "*.example.org"},
+		Result{Source: "Dictionary IPv4", IP: "192.0.2.2", Hostname: "api.dev.example.com"},
+		Result{Source: "Dictionary IPv4", IP: "192.0.2.3", Hostname: "a.api.dev.example.com"},
+		Result{Source: "Dictionary IPv4", IP: "192.0.2.4", Hostname: "www.example.org"},
+	}
+	domains := WildcardCertDomains(results)
+	if len(domains) != 1 || domains[0] != "dev.example.com" {
+		t.Error("WildcardCertDomains returned incorrect domains")
+		t.Log(domains)
+	}
+	marked := MarkWildcardCerts(results)
+	if marked[2].Meta["wildcard_cert"] != "*.dev.example.com" {
+		t.Error("MarkWildcardCerts did not mark a covered hostname")
+		t.Log(marked[2])
+	}
+	for _, i := range []int{0, 3, 4} {
+		if marked[i].Meta["wildcard_cert"] != "" {
+			t.Error("MarkWildcardCerts marked a hostname that is not covered")
+			t.Log(marked[i])
+		}
+	}
+	if results[2].Meta != nil {
+		t.Error("MarkWildcardCerts modified its input")
+	}
+}
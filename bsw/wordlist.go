@@ -0,0 +1,28 @@
+package bsw
+
+import (
+	"embed"
+	"errors"
+	"strings"
+)
+
+// Wordlists built in to the binary, one entry per line.
+//
+//go:embed wordlists/*.txt
+var wordlists embed.FS
+
+// Wordlist returns the entries of a built in wordlist, either "small" or "medium".
+func Wordlist(name string) ([]string, error) {
+	if name != "small" && name != "medium" {
+		return nil, errors.New("\"" + name + "\" is not a built in wordlist, use small or medium")
+	}
+	return readWordlist(name)
+}
+
+func readWordlist(name string) ([]string, error) {
+	data, err := wordlists.ReadFile("wordlists/" + name + ".txt")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(data)), nil
+}
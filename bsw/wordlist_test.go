@@ -0,0 +1,26 @@
+package bsw
+
+import "testing"
+
+func TestWordlist(t *testing.T) {
+	small, err := Wordlist("small")
+	if err != nil {
+		t.Fatal(err)
+	}
+	medium, err := Wordlist("medium")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(small) == 0 || len(medium) <= len(small) {
+		t.Error("Wordlist returned incorrect number of entries")
+		t.Log(len(small), len(medium))
+	}
+	if _, err := Wordlist("srv"); err == nil {
+		t.Error("Wordlist did not return an error for an unknown wordlist")
+	}
+	srv, err := readWordlist("srv")
+	if err != nil || len(srv) == 0 || srv[0] != "_gc._tcp" {
+		t.Error("readWordlist returned incorrect SRV entries")
+		t.Log(srv)
+	}
+}
@@ -0,0 +1,63 @@
+package bsw
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+var yahooSource = newHTTPSource("yahoo", 0)
+
+// Returns the hosts linked from a Yahoo results page. Result links go through a redirect with
+// the destination in its RU path segment.
+func yahooHosts(doc *goquery.Document) []string {
+	hosts := []string{}
+	for _, v := range extract("yahoo", doc) {
+		if host := extractedHostname(v); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+	return hosts
+}
+
+// YahooIP uses yahoo's 'ip:' search operator and scrapes the HTML to find hostnames for an ip.
+func YahooIP(ip string) (string, Results, error) {
+	task := "yahoo"
+	results := Results{}
+	doc, err := yahooSource.scrapeURL("https://search.yahoo.com/search?p=" + url.QueryEscape("ip:"+ip))
+	if err != nil {
+		return task, results, err
+	}
+	for _, host := range yahooHosts(doc) {
+		if host == "search.yahoo.com" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host})
+	}
+	return task, results, nil
+}
+
+// YahooDomain uses yahoo's 'site:' search operator and scrapes the HTML to find ips and
+// hostnames for a domain.
+func YahooDomain(domain, server string) (string, Results, error) {
+	task := "yahoo"
+	results := Results{}
+	doc, err := yahooSource.scrapeURL("https://search.yahoo.com/search?p=" + url.QueryEscape("site:"+domain) + "&n=100")
+	if err != nil {
+		return task, results, err
+	}
+	seen := make(map[string]bool)
+	for _, host := range yahooHosts(doc) {
+		if seen[host] || (host != domain && !strings.HasSuffix(host, "."+domain)) {
+			continue
+		}
+		seen[host] = true
+		ip, answer, err := lookupNameOrCname(host, server)
+		if err != nil || ip == "" {
+			continue
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: host, Meta: answer})
+	}
+	return task, results, nil
+}
@@ -0,0 +1,21 @@
+package bsw
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestYahooHostsOffline(t *testing.T) {
+	page := `<div class="compTitle"><a href="https://r.search.yahoo.com/_ylt=abc/RV=2/RE=1/RO=10/RU=https%3a%2f%2fwww.example.com%2fabout/RK=2/RS=xyz-">About</a></div>
+<div class="compTitle"><a href="https://Mail.Example.com/">Mail</a></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(page))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts := yahooHosts(doc)
+	if len(hosts) != 2 || hosts[0] != "www.example.com" || hosts[1] != "mail.example.com" {
+		t.Errorf("unexpected hosts %v", hosts)
+	}
+}
@@ -1,6 +1,7 @@
 package bsw
 
 import (
+	"bytes"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,6 +9,8 @@ import (
 	"github.com/PuerkitoBio/goquery"
 )
 
+var yandexSource = newHTTPSource("yandex", 0)
+
 // YandexAPI uses Yandex XML API and the 'rhost' search operator to find subdomains of a
 // given domain.
 func YandexAPI(domain, apiURL, serverAddr string) (string, Results, error) {
@@ -17,12 +20,16 @@ func YandexAPI(domain, apiURL, serverAddr string) (string, Results, error) {
 	parts := strings.Split(domain, ".")
 	var query = "rhost:" + parts[1] + "." + parts[0] + ".*"
 	postBody := fmt.Sprintf(xmlTemplate, query)
-	resp, err := http.Post(apiURL, "text/xml", strings.NewReader(postBody))
+	req, err := http.NewRequest("POST", apiURL, strings.NewReader(postBody))
 	if err != nil {
 		return task, results, err
 	}
-	defer resp.Body.Close()
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	req.Header.Set("Content-Type", "text/xml")
+	body, err := yandexSource.fetch(http.DefaultClient, req, false)
+	if err != nil {
+		return task, results, err
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
 	if err != nil {
 		return task, results, nil
 	}
@@ -32,18 +39,11 @@ func YandexAPI(domain, apiURL, serverAddr string) (string, Results, error) {
 		if domainSet[domain] {
 			return
 		}
-		ip, err := LookupName(domain, serverAddr)
+		ip, answer, err := lookupNameOrCname(domain, serverAddr)
 		if err != nil || ip == "" {
-			cfqdn, err := LookupCname(domain, serverAddr)
-			if err != nil || cfqdn == "" {
-				return
-			}
-			ip, err = LookupName(cfqdn, serverAddr)
-			if err != nil || ip == "" {
-				return
-			}
+			return
 		}
-		results = append(results, Result{Source: task, IP: ip, Hostname: domain})
+		results = append(results, Result{Source: task, IP: ip, Hostname: domain, Meta: answer})
 	})
 	return task, results, nil
 }
@@ -0,0 +1,94 @@
+package bsw
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+const zoomEyeURL = "https://api.zoomeye.org/web/search"
+
+var zoomEyeSource = newHTTPSource("zoomeye", 0)
+
+// Each page of results uses quota, so only this many pages are requested for a query.
+const zoomEyeMaxPages = 10
+
+type zoomEyeMessage struct {
+	Total   int `json:"total"`
+	Matches []struct {
+		Site    string   `json:"site"`
+		IP      []string `json:"ip"`
+		Domains []string `json:"domains"`
+	} `json:"matches"`
+}
+
+// Requests pages of a ZoomEye web search, calling fn with each match's site and IPs. Requesting
+// stops when there are no more results or the quota in the response headers is exhausted.
+func zoomEyeSearch(query, key string, fn func(site string, ips []string)) error {
+	for page := 1; page <= zoomEyeMaxPages; page++ {
+		req, err := http.NewRequest("GET", zoomEyeURL+"?query="+url.QueryEscape(query)+"&page="+strconv.Itoa(page), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("API-KEY", key)
+		m := &zoomEyeMessage{}
+		var remaining string
+		err = zoomEyeSource.call(func() error {
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if err := responseError(resp); err != nil {
+				return err
+			}
+			remaining = resp.Header.Get("X-RateLimit-Remaining")
+			return json.NewDecoder(resp.Body).Decode(m)
+		})
+		if err != nil {
+			return err
+		}
+		for _, match := range m.Matches {
+			fn(match.Site, match.IP)
+		}
+		if len(m.Matches) == 0 || page*len(m.Matches) >= m.Total {
+			return nil
+		}
+		if remaining == "0" {
+			return fmt.Errorf("zoomeye quota exhausted after page %d: %w", page, ErrRateLimited)
+		}
+	}
+	return nil
+}
+
+// ZoomEyeDomain uses ZoomEye's web search and 'site:' filter to find ips and hostnames for a domain.
+func ZoomEyeDomain(domain, key string) (string, Results, error) {
+	task := "zoomeye"
+	results := Results{}
+	err := zoomEyeSearch("site:"+domain, key, func(site string, ips []string) {
+		site = strings.ToLower(site)
+		if site != domain && !strings.HasSuffix(site, "."+domain) {
+			return
+		}
+		for _, ip := range ips {
+			results = append(results, Result{Source: task, IP: ip, Hostname: site})
+		}
+	})
+	return task, results, err
+}
+
+// ZoomEyeIP uses ZoomEye's web search and 'ip:' filter to find hostnames for an IP.
+func ZoomEyeIP(ip, key string) (string, Results, error) {
+	task := "zoomeye"
+	results := Results{}
+	err := zoomEyeSearch("ip:"+ip, key, func(site string, _ []string) {
+		if site == "" || site == ip {
+			return
+		}
+		results = append(results, Result{Source: task, IP: ip, Hostname: strings.ToLower(site)})
+	})
+	return task, results, err
+}
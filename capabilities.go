@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// Versions of the JSON written by blacksheepwall, increased whenever one changes in a way that
+// is not backwards compatible.
+var schemaVersions = map[string]int{
+	"capabilities": 1,
+	"result":       1,
+	"event":        1,
+	"plan":         1,
+}
+
+// sourceCapability describes a source of results for orchestration layers.
+type sourceCapability struct {
+	Name    string   `json:"name"`
+	Flag    string   `json:"flag"`
+	Targets []string `json:"targets"`
+	Kind    string   `json:"kind"`
+	Level   string   `json:"level"`
+	// The credential the flag takes, such as an API key, and whether the source needs it.
+	Credential         string `json:"credential,omitempty"`
+	CredentialRequired bool   `json:"credential_required,omitempty"`
+	// Any other value the flag takes, such as a file.
+	Value string `json:"value,omitempty"`
+}
+
+// Each source by the name of its task. Kind is one of dns, api, scrape, dataset, or probe.
+var sourceCapabilities = []sourceCapability{
+	{Name: "dictionary", Flag: "dictionary", Targets: []string{"domain"}, Kind: "dns", Value: "wordlist"},
+	{Name: "ns", Flag: "ns", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "mx", Flag: "mx", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "caa", Flag: "caa", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "soa", Flag: "soa", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "txt", Flag: "txt", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "dmarc", Flag: "dmarc", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "dkim", Flag: "dkim", Targets: []string{"domain"}, Kind: "dns", Value: "wordlist"},
+	{Name: "typosquat", Flag: "typosquat", Targets: []string{"domain"}, Kind: "dns"},
+	{Name: "srv", Flag: "srv", Targets: []string{"domain"}, Kind: "dns", Level: levelNames[levelNormal]},
+	{Name: "delegations", Flag: "delegations", Targets: []string{"domain"}, Kind: "dns", Level: levelNames[levelNormal]},
+	{Name: "reverse", Flag: "reverse", Targets: []string{"ip"}, Kind: "dns"},
+	{Name: "axfr", Flag: "axfr", Targets: []string{"domain"}, Kind: "dns", Level: levelNames[levelNormal]},
+	{Name: "nsec-walk", Flag: "nsec-walk", Targets: []string{"domain"}, Kind: "dns", Level: levelNames[levelNormal]},
+	{Name: "nsec3-hashes", Flag: "nsec3-hashes", Targets: []string{"domain"}, Kind: "dns", Level: levelNames[levelNormal], Value: "file"},
+	{Name: "yandex", Flag: "yandex", Targets: []string{"domain"}, Kind: "api", Credential: "url", CredentialRequired: true},
+	{Name: "bing", Flag: "bing", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "bing-html", Flag: "bing-html", Targets: []string{"ip", "domain"}, Kind: "scrape"},
+	{Name: "baidu", Flag: "baidu", Targets: []string{"domain"}, Kind: "scrape"},
+	{Name: "yahoo", Flag: "yahoo", Targets: []string{"ip", "domain"}, Kind: "scrape"},
+	{Name: "he", Flag: "he", Targets: []string{"ip", "domain"}, Kind: "scrape"},
+	{Name: "netcraft", Flag: "netcraft", Targets: []string{"domain"}, Kind: "scrape"},
+	{Name: "duckduckgo", Flag: "duckduckgo", Targets: []string{"domain"}, Kind: "scrape"},
+	{Name: "shodan", Flag: "shodan", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "viewdns-html", Flag: "viewdns-html", Targets: []string{"ip"}, Kind: "scrape"},
+	{Name: "viewdns", Flag: "viewdns", Targets: []string{"ip"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "reverse-mx", Flag: "reverse-mx", Targets: []string{"domain"}, Kind: "api"},
+	{Name: "reverse-ns", Flag: "reverse-ns", Targets: []string{"domain"}, Kind: "api"},
+	{Name: "robtex", Flag: "robtex", Targets: []string{"ip", "domain"}, Kind: "api"},
+	{Name: "robtex-html", Flag: "robtex-html", Targets: []string{"ip"}, Kind: "scrape"},
+	{Name: "reverseip-api", Flag: "reverseip-api", Targets: []string{"ip", "domain"}, Kind: "api"},
+	{Name: "crtsh", Flag: "crtsh", Targets: []string{"domain"}, Kind: "api"},
+	{Name: "certspotter", Flag: "certspotter", Targets: []string{"domain"}, Kind: "api", Credential: "api key"},
+	{Name: "fb-ct", Flag: "fb-ct", Targets: []string{"domain"}, Kind: "api", Credential: "access token", CredentialRequired: true},
+	{Name: "securitytrails", Flag: "securitytrails", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "virustotal", Flag: "virustotal", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "dnsdb", Flag: "dnsdb", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "circl", Flag: "circl", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "user:pass", CredentialRequired: true},
+	{Name: "otx", Flag: "otx", Targets: []string{"ip", "domain"}, Kind: "api"},
+	{Name: "binaryedge", Flag: "binaryedge", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "zoomeye", Flag: "zoomeye", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "fofa", Flag: "fofa", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "email:key", CredentialRequired: true},
+	{Name: "onyphe", Flag: "onyphe", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "netlas", Flag: "netlas", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "leakix", Flag: "leakix", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key"},
+	{Name: "whoisxml", Flag: "whoisxml", Targets: []string{"ip", "domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "reverse-whois", Flag: "reverse-whois", Targets: []string{"domain"}, Kind: "api", Value: "search term"},
+	{Name: "ipinfo", Flag: "ipinfo", Targets: []string{"ip"}, Kind: "api", Credential: "token", CredentialRequired: true},
+	{Name: "hunterio", Flag: "hunterio", Targets: []string{"domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "hackertarget", Flag: "hackertarget", Targets: []string{"ip", "domain"}, Kind: "api"},
+	{Name: "bufferover", Flag: "bufferover", Targets: []string{"domain"}, Kind: "api", Credential: "api key"},
+	{Name: "threatcrowd", Flag: "threatcrowd", Targets: []string{"ip", "domain"}, Kind: "api"},
+	{Name: "threatminer", Flag: "threatminer", Targets: []string{"ip", "domain"}, Kind: "api"},
+	{Name: "wayback", Flag: "wayback", Targets: []string{"domain"}, Kind: "api"},
+	{Name: "anubis", Flag: "anubis", Targets: []string{"domain"}, Kind: "api"},
+	{Name: "chaos", Flag: "chaos", Targets: []string{"domain"}, Kind: "api", Credential: "api key", CredentialRequired: true},
+	{Name: "github", Flag: "github", Targets: []string{"domain"}, Kind: "api", Credential: "token", CredentialRequired: true},
+	{Name: "fdns", Flag: "fdns", Targets: []string{"domain"}, Kind: "dataset", Value: "file"},
+	{Name: "rdns-file", Flag: "rdns-file", Targets: []string{"ip"}, Kind: "dataset", Value: "file"},
+	{Name: "headers", Flag: "headers", Targets: []string{"ip"}, Kind: "probe", Level: levelNames[levelNormal]},
+	{Name: "tls", Flag: "tls", Targets: []string{"ip"}, Kind: "probe", Level: levelNames[levelNormal]},
+	{Name: "smtp", Flag: "smtp", Targets: []string{"ip"}, Kind: "probe", Level: levelNames[levelNormal]},
+	{Name: "ntp", Flag: "ntp", Targets: []string{"ip"}, Kind: "probe", Level: levelNames[levelNormal]},
+	{Name: "verify-ports", Flag: "verify-ports", Targets: []string{"ip"}, Kind: "probe", Level: levelNames[levelNormal], Value: "ports"},
+	{Name: "tftp", Flag: "tftp", Targets: []string{"ip"}, Kind: "probe", Level: levelNames[levelIntrusive]},
+}
+
+// Returns an error for the first enabled source, by the name of its flag, that requires a higher
+// level than level.
+func checkLevel(enabled map[string]bool, level int) error {
+	for _, c := range sourceCapabilities {
+		if !enabled[c.Flag] {
+			continue
+		}
+		for l, name := range levelNames {
+			if name == c.Level && l > level {
+				return fmt.Errorf("-%s requires -level %s or higher", c.Flag, name)
+			}
+		}
+	}
+	return nil
+}
+
+// capabilities is written by the capabilities subcommand.
+type capabilities struct {
+	Version        string             `json:"version"`
+	SchemaVersions map[string]int     `json:"schema_versions"`
+	Subcommands    []string           `json:"subcommands"`
+	OutputFormats  []string           `json:"output_formats"`
+	Levels         []string           `json:"levels"`
+	Sources        []sourceCapability `json:"sources"`
+	Options        []string           `json:"options"`
+}
+
+// Writes the capabilities of this binary as JSON, with every option defined in flags.
+func writeCapabilities(w io.Writer, flags *flag.FlagSet) error {
+	c := capabilities{
+		Version:        bsw.VERSION,
+		SchemaVersions: schemaVersions,
+		Subcommands:    []string{"run", "wordlist", "capabilities"},
+		OutputFormats:  []string{"text", "clean", "csv", "json", "template", "machine", "plan-json", "indicators", "openioc"},
+		Levels:         levelNames,
+		Options:        []string{},
+	}
+	for _, s := range sourceCapabilities {
+		if s.Level == "" {
+			s.Level = levelNames[levelSafe]
+		}
+		c.Sources = append(c.Sources, s)
+	}
+	flags.VisitAll(func(f *flag.Flag) { c.Options = append(c.Options, f.Name) })
+	sort.Strings(c.Options)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(c)
+}
@@ -0,0 +1,20 @@
+package main
+
+import "testing"
+
+func TestCheckLevel(t *testing.T) {
+	if err := checkLevel(map[string]bool{"axfr": true, "crtsh": true}, levelNormal); err != nil {
+		t.Error("checkLevel rejected -axfr at -level normal")
+		t.Log(err)
+	}
+	if err := checkLevel(map[string]bool{"axfr": true}, levelSafe); err == nil {
+		t.Error("checkLevel did not reject -axfr at -level safe")
+	}
+	if err := checkLevel(map[string]bool{"tftp": true}, levelNormal); err == nil {
+		t.Error("checkLevel did not reject -tftp at -level normal")
+	}
+	if err := checkLevel(map[string]bool{"tftp": false}, levelSafe); err != nil {
+		t.Error("checkLevel rejected a source that is not enabled")
+		t.Log(err)
+	}
+}
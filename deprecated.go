@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// How long a source has to answer its health check at startup.
+const healthCheckTimeout = 5 * time.Second
+
+// deprecatedOption is an option for a source that has gone away. When it is used a warning is
+// logged and the first of its replacements that passes its health check is enabled instead.
+type deprecatedOption struct {
+	name         string
+	reason       string
+	replacements []string
+}
+
+var deprecatedOptions = []deprecatedOption{
+	{"logontube", "logontube.com no longer responds", []string{"reverseip-api", "hackertarget"}},
+}
+
+// Runs the health checks of the sources enabled by the command line options, and of the
+// replacements of deprecated options, at the same time. Returns the error of each source that
+// failed its check.
+func checkSourceHealth() map[string]error {
+	enabled := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { enabled[f.Name] = f.Value.String() != "false" })
+	for _, d := range deprecatedOptions {
+		if enabled[d.name] {
+			for _, r := range d.replacements {
+				enabled[r] = true
+			}
+		}
+	}
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed := make(map[string]error)
+	for _, name := range bsw.HealthChecked() {
+		if !enabled[name] {
+			continue
+		}
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := bsw.CheckHealth(name, healthCheckTimeout); err != nil {
+				mu.Lock()
+				failed[name] = err
+				mu.Unlock()
+			}
+		}(name)
+	}
+	wg.Wait()
+	return failed
+}
+
+// Replaces each deprecated option that is set with its first healthy replacement, and disables
+// the other sources that failed their health check, with a warning for each.
+func applyDeprecations(failed map[string]error) {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = f.Value.String() != "false" })
+	for _, d := range deprecatedOptions {
+		if !set[d.name] {
+			continue
+		}
+		flag.Set(d.name, "false")
+		replaced := false
+		for _, r := range d.replacements {
+			if failed[r] != nil {
+				continue
+			}
+			log.Printf("WARNING: -%s is deprecated as %s, using -%s instead", d.name, d.reason, r)
+			flag.Set(r, "true")
+			replaced = true
+			break
+		}
+		if !replaced {
+			log.Printf("WARNING: -%s is deprecated as %s, and no replacement is responding, it is disabled", d.name, d.reason)
+		}
+	}
+	for name, err := range failed {
+		if f := flag.Lookup(name); f != nil && f.Value.String() == "true" {
+			log.Printf("WARNING: -%s is disabled as it failed its health check: %s", name, err.Error())
+			flag.Set(name, "false")
+		}
+	}
+}
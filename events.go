@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// event is a single line of output in -machine mode.
+type event struct {
+	Type     string      `json:"type"`
+	Time     time.Time   `json:"time"`
+	Task     string      `json:"task,omitempty"`
+	PlanTask *int        `json:"plan_task,omitempty"`
+	Error    string      `json:"error,omitempty"`
+	Result   *bsw.Result `json:"result,omitempty"`
+	Stats    *stats      `json:"stats,omitempty"`
+}
+
+// stats are the counts reported by progress and summary events.
+type stats struct {
+	Completed int64          `json:"completed"`
+	Failed    int64          `json:"failed"`
+	Results   int64          `json:"results"`
+	Duration  float64        `json:"duration"`
+	Failures  map[string]int `json:"failures,omitempty"`
+}
+
+// eventWriter writes newline delimited JSON events and keeps the counts reported
+// by progress and summary events. With a redactor every string in an event is redacted. It
+// is safe for concurrent use.
+type eventWriter struct {
+	mu        sync.Mutex
+	enc       *json.Encoder
+	redact    *redactor
+	started   time.Time
+	completed int64
+	failed    int64
+	results   int64
+}
+
+func newEventWriter(w io.Writer, redact *redactor) *eventWriter {
+	return &eventWriter{enc: json.NewEncoder(w), started: time.Now(), redact: redact}
+}
+
+func (e *eventWriter) emit(ev event) {
+	ev.Time = time.Now()
+	// Errors carry the targets of the requests that failed, such as a hostname or URL.
+	if e.redact != nil {
+		ev.Task, ev.Error = e.redact.value(ev.Task), e.redact.value(ev.Error)
+		if ev.Result != nil {
+			r := e.redact.result(*ev.Result)
+			ev.Result = &r
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.enc.Encode(ev)
+}
+
+// Records a completed task, emitting an error event if it failed.
+func (e *eventWriter) task(name string, err error) {
+	atomic.AddInt64(&e.completed, 1)
+	if err != nil {
+		atomic.AddInt64(&e.failed, 1)
+		e.emit(event{Type: "error", Task: name, Error: err.Error()})
+	}
+}
+
+// Emits a marker that the task with id in a plan has finished.
+func (e *eventWriter) planTask(id int, err error) {
+	ev := event{Type: "plan_task", PlanTask: &id}
+	if err != nil {
+		ev.Error = err.Error()
+	}
+	e.emit(ev)
+}
+
+// Emits a unique result.
+func (e *eventWriter) result(r bsw.Result) {
+	atomic.AddInt64(&e.results, 1)
+	e.emit(event{Type: "result", Result: &r})
+}
+
+func (e *eventWriter) counts(typ string) event {
+	return event{Type: typ, Stats: &stats{
+		Completed: atomic.LoadInt64(&e.completed),
+		Failed:    atomic.LoadInt64(&e.failed),
+		Results:   atomic.LoadInt64(&e.results),
+		Duration:  time.Since(e.started).Seconds(),
+	}}
+}
+
+// Emits a progress event every interval until done is closed.
+func (e *eventWriter) progress(interval time.Duration, done <-chan empty) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			e.emit(e.counts("progress"))
+		case <-done:
+			return
+		}
+	}
+}
+
+// Emits the final summary event, including the count of task failures in each category.
+func (e *eventWriter) summary(failures map[string]int) {
+	ev := e.counts("summary")
+	ev.Stats.Failures = failures
+	e.emit(ev)
+}
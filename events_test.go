@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+func TestEventWriterRedact(t *testing.T) {
+	var buf bytes.Buffer
+	e := newEventWriter(&buf, newRedactor("key"))
+	e.task("crt.sh", errors.New(`Get "https://crt.sh/?q=%25.example.com&output=json": dial tcp: lookup crt.sh on 192.0.2.53:53: no such host`))
+	e.task("mx", errors.New("lookup mail.example.com. on 192.0.2.53:53: no such host"))
+	e.planTask(1, errors.New("-axfr example.com: zone transfer refused by ns1.example.com"))
+	e.result(bsw.Result{Source: "txt", IP: "192.0.2.1", Hostname: "www.example.com", Meta: map[string]string{"txt": "v=spf1 include:_spf.example.com -all"}})
+	e.summary(map[string]int{"timeout": 1})
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 5 {
+		t.Fatal("eventWriter wrote incorrect number of events")
+	}
+	for _, line := range lines {
+		if strings.Contains(line, "example") || strings.Contains(line, "192.0.2.1") || strings.Contains(line, "192.0.2.53") {
+			t.Error("eventWriter wrote a target with -redact")
+			t.Log(line)
+		}
+	}
+}
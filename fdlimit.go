@@ -0,0 +1,17 @@
+package main
+
+// File descriptors kept free of tasks, for output files, logs, and idle HTTP connections.
+const fdReserve = 64
+
+// The most file descriptors a task holds at once: the socket it dials and a connection kept
+// alive for its source.
+const fdsPerTask = 2
+
+// Returns the most tasks that can run at once within a limit of open file descriptors, or 0 if
+// the limit leaves no room for any.
+func fdConcurrency(limit uint64) uint64 {
+	if limit <= fdReserve {
+		return 0
+	}
+	return (limit - fdReserve) / fdsPerTask
+}
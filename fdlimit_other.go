@@ -0,0 +1,9 @@
+//go:build !unix
+
+package main
+
+// Returns the limit of open file descriptors of the process, and whether there is one. Windows
+// limits sockets by memory rather than by count, so there is none to detect.
+func fdLimit() (uint64, bool) {
+	return 0, false
+}
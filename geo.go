@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// geoCluster counts the unique IPs and hostnames hosted by a provider in a city.
+type geoCluster struct {
+	Country   string
+	City      string
+	Provider  string
+	IPs       int
+	Hostnames int
+}
+
+// Returns the unique IPs of results.
+func resultIPs(results bsw.Results) []string {
+	seen := make(map[string]bool)
+	ips := []string{}
+	for _, r := range results {
+		if net.ParseIP(r.IP) == nil || seen[r.IP] {
+			continue
+		}
+		seen[r.IP] = true
+		ips = append(ips, r.IP)
+	}
+	return ips
+}
+
+// Groups results by the country, city, and hosting provider of their IP. IPs that could not be
+// located are grouped as unknown. Clusters with the most IPs are first.
+func clusterByGeo(results bsw.Results, byIP map[string]bsw.GeoInfo) []geoCluster {
+	type sets struct {
+		ips, names map[string]bool
+	}
+	byLocation := make(map[bsw.GeoInfo]*sets)
+	for _, r := range results {
+		if net.ParseIP(r.IP) == nil {
+			continue
+		}
+		info, ok := byIP[r.IP]
+		if !ok {
+			info = bsw.GeoInfo{Country: "unknown"}
+		}
+		s, ok := byLocation[info]
+		if !ok {
+			s = &sets{make(map[string]bool), make(map[string]bool)}
+			byLocation[info] = s
+		}
+		s.ips[r.IP] = true
+		if name := strings.ToLower(strings.TrimSuffix(r.Hostname, ".")); name != "" && name != r.IP {
+			s.names[name] = true
+		}
+	}
+	clusters := []geoCluster{}
+	for info, s := range byLocation {
+		clusters = append(clusters, geoCluster{Country: info.Country, City: info.City, Provider: info.Provider, IPs: len(s.ips), Hostnames: len(s.names)})
+	}
+	sort.Slice(clusters, func(i, j int) bool {
+		a, b := clusters[i], clusters[j]
+		if a.IPs != b.IPs {
+			return a.IPs > b.IPs
+		}
+		if a.Country != b.Country {
+			return a.Country < b.Country
+		}
+		if a.City != b.City {
+			return a.City < b.City
+		}
+		return a.Provider < b.Provider
+	})
+	return clusters
+}
+
+func outputGeoClusters(out io.Writer, clusters []geoCluster) {
+	w := tabwriter.NewWriter(out, 0, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "Country\tCity\tProvider\tIPs\tHostnames")
+	for _, c := range clusters {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\n", c.Country, c.City, c.Provider, c.IPs, c.Hostnames)
+	}
+	w.Flush()
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// indicator is a hostname and IP pair found by a scan, for blocklist and abuse teams.
+type indicator struct {
+	Hostname  string
+	IP        string
+	FirstSeen time.Time
+	Sources   []string
+}
+
+// sightings records when each hostname and IP pair was first found. It is only used by the
+// result gatherer.
+type sightings map[[2]string]time.Time
+
+func (s sightings) add(r bsw.Result) {
+	key := [2]string{strings.ToLower(r.Hostname), r.IP}
+	if _, ok := s[key]; !ok {
+		s[key] = time.Now()
+	}
+}
+
+// Collapses results into one indicator for each hostname and IP pair, with the sources that
+// found it, ordered by hostname and then IP.
+func buildIndicators(results bsw.Results, seen sightings) []indicator {
+	index := make(map[[2]string]int)
+	list := []indicator{}
+	for _, r := range results {
+		if r.Hostname == "" && r.IP == "" {
+			continue
+		}
+		key := [2]string{strings.ToLower(r.Hostname), r.IP}
+		i, ok := index[key]
+		if !ok {
+			i = len(list)
+			index[key] = i
+			list = append(list, indicator{Hostname: key[0], IP: r.IP, FirstSeen: seen[key]})
+		}
+		found := false
+		for _, s := range list[i].Sources {
+			if s == r.Source {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list[i].Sources = append(list[i].Sources, r.Source)
+		}
+	}
+	for i := range list {
+		sort.Strings(list[i].Sources)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Hostname != list[j].Hostname {
+			return list[i].Hostname < list[j].Hostname
+		}
+		return list[i].IP < list[j].IP
+	})
+	return list
+}
+
+// Writes indicators as CSV with a header of hostname, ip, first_seen, and source. Multiple
+// sources are separated by a space and times are RFC 3339 in UTC.
+func writeIndicatorsCSV(w io.Writer, list []indicator) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"hostname", "ip", "first_seen", "source"})
+	for _, in := range list {
+		firstSeen := ""
+		if !in.FirstSeen.IsZero() {
+			firstSeen = in.FirstSeen.UTC().Format(time.RFC3339)
+		}
+		cw.Write([]string{in.Hostname, in.IP, firstSeen, strings.Join(in.Sources, " ")})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// The subset of the OpenIOC 1.0 schema needed for DNS and IP indicators.
+type openIOC struct {
+	XMLName          xml.Name         `xml:"ioc"`
+	Xmlns            string           `xml:"xmlns,attr"`
+	ID               string           `xml:"id,attr"`
+	LastModified     string           `xml:"last-modified,attr"`
+	ShortDescription string           `xml:"short_description"`
+	Description      string           `xml:"description"`
+	AuthoredBy       string           `xml:"authored_by"`
+	AuthoredDate     string           `xml:"authored_date"`
+	Links            struct{}         `xml:"links"`
+	Definition       openIOCIndicator `xml:"definition>Indicator"`
+}
+
+type openIOCIndicator struct {
+	Operator   string        `xml:"operator,attr"`
+	ID         string        `xml:"id,attr"`
+	Indicators []openIOCItem `xml:"IndicatorItem"`
+}
+
+type openIOCItem struct {
+	ID        string `xml:"id,attr"`
+	Condition string `xml:"condition,attr"`
+	Context   struct {
+		Document string `xml:"document,attr"`
+		Search   string `xml:"search,attr"`
+		Type     string `xml:"type,attr"`
+	} `xml:"Context"`
+	Content struct {
+		Type  string `xml:"type,attr"`
+		Value string `xml:",chardata"`
+	} `xml:"Content"`
+}
+
+// Returns a random version 4 UUID.
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// Writes indicators as an OpenIOC 1.0 document matching any of the unique hostnames or IPs.
+func writeOpenIOC(w io.Writer, list []indicator, domains []string, finished time.Time) error {
+	stamp := finished.UTC().Format("2006-01-02T15:04:05")
+	doc := openIOC{
+		Xmlns:            "http://schemas.mandiant.com/2010/ioc",
+		ID:               newUUID(),
+		LastModified:     stamp,
+		ShortDescription: "blacksheepwall findings",
+		AuthoredBy:       "blacksheepwall " + bsw.VERSION,
+		AuthoredDate:     stamp,
+		Definition:       openIOCIndicator{Operator: "OR", ID: newUUID()},
+	}
+	doc.Description = fmt.Sprintf("%d hostname and IP pairs found by blacksheepwall", len(list))
+	if len(domains) > 0 {
+		doc.Description += " for " + strings.Join(domains, ", ")
+	}
+	doc.Description += "."
+	seen := make(map[string]bool)
+	add := func(search, contentType, value string) {
+		if value == "" || seen[search+value] {
+			return
+		}
+		seen[search+value] = true
+		item := openIOCItem{ID: newUUID(), Condition: "is"}
+		item.Context.Document = strings.Split(search, "/")[0]
+		item.Context.Search = search
+		item.Context.Type = "mir"
+		item.Content.Type = contentType
+		item.Content.Value = value
+		doc.Definition.Indicators = append(doc.Definition.Indicators, item)
+	}
+	for _, in := range list {
+		add("DnsEntryItem/Host", "string", in.Hostname)
+	}
+	for _, in := range list {
+		add("PortItem/remoteIP", "IP", in.IP)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// Creates path and writes to it with fn.
+func writeFile(path string, fn func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := fn(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
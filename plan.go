@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"text/tabwriter"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// planTask is a single task in a plan. ID is the position of the task in the order tasks are
+// queued for the plan's options and targets.
+type planTask struct {
+	ID     int    `json:"id"`
+	Source string `json:"source"`
+	Target string `json:"target,omitempty"`
+}
+
+// plan is the list of tasks a scan would run, as written by -dry-run. Credentials are not
+// included, only the names of the options they were given with.
+type plan struct {
+	Version     string            `json:"version"`
+	Options     map[string]string `json:"options"`
+	Credentials []string          `json:"credentials,omitempty"`
+	Domains     []string          `json:"domains,omitempty"`
+	IPs         []string          `json:"ips,omitempty"`
+	Total       int               `json:"total"`
+	Tasks       []planTask        `json:"tasks"`
+}
+
+// Options that select the targets or the mode rather than the tasks, which are not recorded
+// in a plan.
+var planIgnored = map[string]bool{"domain": true, "input": true, "dry-run": true, "plan-json": true}
+
+// Options holding secrets other than credentials, whose values are withheld from a plan like
+// credentials.
+var planSecrets = []string{"redact"}
+
+// Returns an empty plan recording the options that have been set. It should be called before
+// any options are modified.
+func newPlan() *plan {
+	exclusive := make(map[string]bool)
+	for _, name := range append(append([]string{}, profileExclusive...), planSecrets...) {
+		exclusive[name] = true
+	}
+	p := &plan{Version: bsw.VERSION, Options: make(map[string]string)}
+	flag.Visit(func(f *flag.Flag) {
+		switch {
+		case planIgnored[f.Name]:
+		case exclusive[f.Name]:
+			p.Credentials = append(p.Credentials, f.Name)
+		default:
+			p.Options[f.Name] = f.Value.String()
+		}
+	})
+	return p
+}
+
+// Writes a plan as JSON, or as a table of tasks.
+func writePlan(out io.Writer, p *plan, asJSON bool) {
+	if asJSON {
+		j, _ := json.MarshalIndent(p, "", "    ")
+		fmt.Fprintln(out, string(j))
+		return
+	}
+	w := tabwriter.NewWriter(out, 0, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "ID\tSource\tTarget")
+	for _, t := range p.Tasks {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", t.ID, t.Source, t.Target)
+	}
+	w.Flush()
+}
+
+// Reads a plan, or part of one, written by -dry-run -plan-json.
+func loadPlan(path string) (*plan, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p := &plan{}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, errors.New("error parsing " + path + " " + err.Error())
+	}
+	if len(p.Tasks) == 0 {
+		return nil, errors.New(path + " does not contain any tasks")
+	}
+	return p, nil
+}
+
+// Applies the options recorded in a plan to those not set on the command line, and checks that
+// the credentials the plan was made with have been given. Targets come from the plan, so may not
+// be given on the command line.
+func applyPlan(p *plan) error {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for name := range planIgnored {
+		if set[name] {
+			return errors.New("-" + name + " can not be used with -execute-plan")
+		}
+	}
+	if flag.NArg() > 0 {
+		return errors.New("targets can not be given with -execute-plan")
+	}
+	for _, name := range p.Credentials {
+		if !set[name] {
+			return errors.New("the plan was made with -" + name + ", provide it again")
+		}
+	}
+	for name, value := range p.Options {
+		if set[name] {
+			continue
+		}
+		if flag.Lookup(name) == nil {
+			return errors.New("plan option \"" + name + "\" is not an option")
+		}
+		if err := flag.Set(name, value); err != nil {
+			return errors.New("plan option \"" + name + "\": " + err.Error())
+		}
+	}
+	return nil
+}
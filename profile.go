@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// Options holding API credentials or sending results somewhere. When a profile is used these
+// may only be set by the profile, so one client's keys and sinks are never mixed with another's.
+var profileExclusive = []string{
+	"shodan", "bing", "yandex", "viewdns", "securitytrails", "virustotal", "dnsdb", "circl",
+	"binaryedge", "zoomeye", "fofa", "onyphe", "netlas", "hunterio", "leakix", "whoisxml",
+	"ipinfo", "certspotter", "fb-ct", "bufferover", "chaos", "github", "thehive", "thehive-key",
+	"upload",
+}
+
+// Environment variables holding upload credentials. When a profile is used they are only
+// taken from the profile's env.
+var profileExclusiveEnv = []string{
+	"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_REGION",
+	"GOOGLE_HMAC_ACCESS_ID", "GOOGLE_HMAC_SECRET",
+}
+
+// profile is a named set of options and environment variables read from the config file.
+type profile struct {
+	Options map[string]interface{} `json:"options"`
+	Env     map[string]string      `json:"env"`
+}
+
+type config struct {
+	Profiles   map[string]profile         `json:"profiles"`
+	Extractors map[string][]bsw.Extractor `json:"extractors"`
+}
+
+// Returns the default path of the config file.
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "blacksheepwall", "config.json")
+}
+
+// Reads the config file at path.
+func loadConfig(path string) (config, error) {
+	c := config{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, errors.New("error parsing " + path + " " + err.Error())
+	}
+	return c, nil
+}
+
+// Replaces the extractors of the scrapers named in the config file.
+func applyExtractors(c config) error {
+	for source, list := range c.Extractors {
+		if err := bsw.SetExtractors(source, list); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reads the named profile from the config file at path.
+func loadProfile(path, name string) (profile, error) {
+	if path == "" {
+		return profile{}, errors.New("no config file was found, use -config")
+	}
+	c, err := loadConfig(path)
+	if err != nil {
+		return profile{}, err
+	}
+	p, ok := c.Profiles[name]
+	if !ok {
+		names := []string{}
+		for n := range c.Profiles {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return profile{}, errors.New("profile \"" + name + "\" is not in " + path + ", profiles are: " + strings.Join(names, ", "))
+	}
+	return p, nil
+}
+
+// Applies a profile to the parsed command line options. Options set on the command line take
+// precedence, except for credentials and sinks which may only come from the profile.
+func applyProfile(p profile) error {
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, name := range profileExclusive {
+		if set[name] {
+			return errors.New("-" + name + " can not be used with -profile, set it in the profile instead")
+		}
+	}
+	for _, env := range profileExclusiveEnv {
+		os.Unsetenv(env)
+	}
+	for k, v := range p.Env {
+		os.Setenv(k, v)
+	}
+	for name, value := range p.Options {
+		if set[name] {
+			continue
+		}
+		if flag.Lookup(name) == nil {
+			return errors.New("profile option \"" + name + "\" is not an option")
+		}
+		v, err := optionValue(name, value)
+		if err != nil {
+			return err
+		}
+		if err := flag.Set(name, v); err != nil {
+			return errors.New("profile option \"" + name + "\": " + err.Error())
+		}
+	}
+	return nil
+}
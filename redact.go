@@ -0,0 +1,135 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// Hostnames to redact, which unlike domainReg allows the underscores of service names such as
+// _spf and _dmarc.
+var redactHostnameReg = regexp.MustCompile(`^\.?(?:[a-z\d_](?:[a-z\d_\-]*[a-z\d_])?\.)+[a-z\d_](?:[a-z\d_\-]*[a-z\d_])?$`)
+
+// redactor masks IPs and hashes hostnames so a result set can be shared. Hostnames are hashed
+// with HMAC-SHA256 so that the same name always has the same hash for a given key, allowing
+// results to be correlated without revealing the name.
+type redactor struct {
+	key []byte
+}
+
+// Returns a redactor using key. Without a key a random one is used and hashes can only be
+// correlated within a single run.
+func newRedactor(key string) *redactor {
+	if key != "" {
+		return &redactor{key: []byte(key)}
+	}
+	k := make([]byte, 32)
+	rand.Read(k)
+	return &redactor{key: k}
+}
+
+// Zeroes the last octet of an IPv4 address, or the last 64 bits of an IPv6 address.
+func (r *redactor) ip(s string) string {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return s
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(64, 128)).String()
+}
+
+func (r *redactor) hostname(s string) string {
+	if s == "" {
+		return s
+	}
+	h := hmac.New(sha256.New, r.key)
+	h.Write([]byte(strings.ToLower(s)))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// Characters that separate the hostnames and addresses embedded in a metadata value or error,
+// such as the mechanisms of an SPF record, the user and domain of a mailbox, or the escaped
+// parameters of a URL's query.
+const redactSeparators = " \t\r\n,;\"'<>()[]=@?&#%"
+
+// Redacts every IP, network, and hostname in a metadata value, leaving the rest of it intact.
+func (r *redactor) value(s string) string {
+	var b strings.Builder
+	start := 0
+	for i, c := range s {
+		if strings.ContainsRune(redactSeparators, c) {
+			b.WriteString(r.token(s[start:i]))
+			b.WriteRune(c)
+			start = i + len(string(c))
+		}
+	}
+	b.WriteString(r.token(s[start:]))
+	return b.String()
+}
+
+// Redacts a token of a metadata value. IPs are masked, and networks and hostnames are hashed.
+// Tokens such as "include:host", "ip4:network", and URLs are split on their ':' and '/' and each
+// part is redacted.
+func (r *redactor) token(s string) string {
+	if s == "" {
+		return s
+	}
+	if net.ParseIP(s) != nil {
+		return r.ip(s)
+	}
+	if _, _, err := net.ParseCIDR(s); err == nil {
+		return r.hostname(s)
+	}
+	if i := strings.IndexAny(s, ":/"); i >= 0 {
+		return r.token(s[:i]) + s[i:i+1] + r.token(s[i+1:])
+	}
+	if strings.HasPrefix(s, "*.") {
+		return "*." + r.token(s[2:])
+	}
+	name := strings.TrimSuffix(s, ".")
+	if redactHostnameReg.MatchString(strings.ToLower(name)) {
+		return r.hostname(name)
+	}
+	return s
+}
+
+func (r *redactor) result(res bsw.Result) bsw.Result {
+	res.IP = r.ip(res.IP)
+	res.Hostname = r.hostname(res.Hostname)
+	if res.Meta != nil {
+		meta := make(map[string]string, len(res.Meta))
+		for k, v := range res.Meta {
+			meta[k] = r.value(v)
+		}
+		res.Meta = meta
+	}
+	return res
+}
+
+// Redacts and sorts results. Results that become identical once redacted are kept, as they
+// were distinct before.
+func (r *redactor) results(results bsw.Results) bsw.Results {
+	redacted := make(bsw.Results, len(results))
+	for i, res := range results {
+		redacted[i] = r.result(res)
+	}
+	sort.Sort(redacted)
+	return redacted
+}
+
+func (r *redactor) strings(values []string) []string {
+	redacted := make([]string, len(values))
+	for i, v := range values {
+		redacted[i] = r.value(v)
+	}
+	return redacted
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactValue(t *testing.T) {
+	r := newRedactor("key")
+	h := r.hostname
+	tests := map[string]string{
+		"192.0.2.10":       "192.0.2.0",
+		"mail.example.com": h("mail.example.com"),
+		"v=spf1 include:_spf.example.com ip4:192.0.2.0/24 ip6:2001:db8::/32 -all": "v=spf1 include:" + h("_spf.example.com") + " ip4:" + h("192.0.2.0/24") + " ip6:" + h("2001:db8::/32") + " -all",
+		"198.51.100.0/22":                     h("198.51.100.0/22"),
+		"hostmaster@example.com":              "hostmaster@" + h("example.com"),
+		"mailto:security@example.com":         "mailto:security@" + h("example.com"),
+		"https://iodef.example.com/report":    "https://" + h("iodef.example.com") + "/report",
+		"*.example.com":                       "*." + h("example.com"),
+		"\"google-site-verification=abc123\"": "\"google-site-verification=abc123\"",
+	}
+	for value, expected := range tests {
+		if redacted := r.value(value); redacted != expected {
+			t.Error("redactor did not redact the metadata value correctly")
+			t.Log(value, redacted)
+		}
+	}
+	for _, leaked := range []string{"example.com", "192.0.2.10", "198.51.100", "2001:db8"} {
+		for value := range tests {
+			if strings.Contains(r.value(value), leaked) {
+				t.Error("redactor left an address or hostname in a metadata value")
+				t.Log(value, leaked)
+			}
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const runUsage = `
+ Usage: blacksheepwall run <scan file>
+
+ Runs the scan described by a JSON scan file, e.g.
+
+  {
+    "targets": {"domains": ["example.com"], "ips": ["192.0.2.0/24"]},
+    "sources": {"crtsh": true, "dictionary": "builtin:small", "virustotal": "env:VT_API_KEY"},
+    "options": {"level": "safe", "concurrency": 50},
+    "output": {"format": "json", "file": "example-{time}.json", "upload": "s3://bucket/recon/"},
+    "schedule": {"every": "24h"}
+  }
+
+ Sources and options are command line options without the leading '-'. A value of
+ true enables an option, false omits it, and any other value is passed as its
+ argument. Values starting with "env:" are read from the named environment
+ variable, so keys can be kept out of the file, and are passed to the scan through
+ its environment rather than its command line. Output is written to stdout unless
+ a file is given, "{time}" in its name is replaced with the time the scan started.
+ With a schedule the scan is repeated at the interval until interrupted.
+`
+
+// scanFile is a declarative description of a scan, run with the run subcommand.
+type scanFile struct {
+	Targets struct {
+		Domains []string `json:"domains"`
+		IPs     []string `json:"ips"`
+	} `json:"targets"`
+	Sources map[string]interface{} `json:"sources"`
+	Options map[string]interface{} `json:"options"`
+	Output  struct {
+		Format   string `json:"format"`
+		Template string `json:"template"`
+		File     string `json:"file"`
+		Upload   string `json:"upload"`
+	} `json:"output"`
+	Schedule struct {
+		Every string `json:"every"`
+	} `json:"schedule"`
+}
+
+// Handles the run subcommand.
+func runScanCommand(args []string) {
+	if len(args) != 1 || args[0] == "-h" || args[0] == "--help" {
+		fmt.Print(runUsage)
+		os.Exit(1)
+	}
+	data, err := ioutil.ReadFile(args[0])
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	scan := &scanFile{}
+	if err := json.Unmarshal(data, scan); err != nil {
+		log.Fatal("Error parsing " + args[0] + " " + err.Error())
+	}
+	var every time.Duration
+	if scan.Schedule.Every != "" {
+		every, err = time.ParseDuration(scan.Schedule.Every)
+		if err != nil || every <= 0 {
+			log.Fatal("schedule every must be a positive duration such as \"24h\"")
+		}
+	}
+	// The binary is found once, rather than through os.Args[0], which may be relative to a
+	// directory that is no longer the working directory or name another binary on the PATH.
+	binary, err := os.Executable()
+	if err != nil {
+		log.Fatal("Error finding the blacksheepwall binary to run the scan " + err.Error())
+	}
+	for {
+		started := time.Now()
+		err := scan.run(binary, started)
+		if every == 0 {
+			if exitErr, ok := err.(*exec.ExitError); ok {
+				os.Exit(exitErr.ExitCode())
+			}
+			if err != nil {
+				log.Fatal(err.Error())
+			}
+			return
+		}
+		if err != nil {
+			log.Printf("Scheduled scan failed: %s", err.Error())
+		}
+		next := started.Add(every)
+		log.Printf("Next scan at %s", next.Format(time.RFC3339))
+		time.Sleep(time.Until(next))
+	}
+}
+
+// Environment variable holding the options of a scan whose values were read from the
+// environment, as a JSON object, so that keys are not visible in its command line.
+const scanSecretsEnv = "BSW_SCAN_SECRETS"
+
+// Runs the scan once by executing binary, this blacksheepwall, with the equivalent command line
+// options.
+func (s *scanFile) run(binary string, started time.Time) error {
+	args, secrets, cleanup, err := s.args()
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(binary, args...)
+	if len(secrets) > 0 {
+		data, err := json.Marshal(secrets)
+		if err != nil {
+			return err
+		}
+		cmd.Env = append(os.Environ(), scanSecretsEnv+"="+string(data))
+	}
+	cmd.Stderr = os.Stderr
+	var out io.Writer = os.Stdout
+	if s.Output.File != "" {
+		name := strings.Replace(s.Output.File, "{time}", started.UTC().Format("20060102T150405Z"), -1)
+		f, err := os.Create(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	cmd.Stdout = out
+	return cmd.Run()
+}
+
+// Returns the command line options for the scan, and the options whose values were read from
+// the environment. Target lists are written to temporary files that are removed by calling
+// cleanup.
+func (s *scanFile) args() ([]string, map[string]string, func(), error) {
+	files := []string{}
+	cleanup := func() {
+		for _, f := range files {
+			os.Remove(f)
+		}
+	}
+	writeList := func(lines []string) (string, error) {
+		f, err := ioutil.TempFile("", "bsw-scan-")
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+		files = append(files, f.Name())
+		_, err = f.WriteString(strings.Join(lines, "\n") + "\n")
+		return f.Name(), err
+	}
+
+	if len(s.Targets.Domains) == 0 && len(s.Targets.IPs) == 0 {
+		return nil, nil, cleanup, errors.New("scan file does not contain any targets")
+	}
+	args := []string{}
+	if len(s.Targets.Domains) > 0 {
+		name, err := writeList(s.Targets.Domains)
+		if err != nil {
+			return nil, nil, cleanup, err
+		}
+		args = append(args, "-domain", name)
+	}
+	if len(s.Targets.IPs) > 0 {
+		name, err := writeList(s.Targets.IPs)
+		if err != nil {
+			return nil, nil, cleanup, err
+		}
+		args = append(args, "-input", name)
+	}
+	secrets := make(map[string]string)
+	for _, m := range []map[string]interface{}{s.Sources, s.Options} {
+		opts, err := scanOptions(m, secrets)
+		if err != nil {
+			return nil, nil, cleanup, err
+		}
+		args = append(args, opts...)
+	}
+	switch s.Output.Format {
+	case "", "table":
+	case "json", "csv", "clean":
+		args = append(args, "-"+s.Output.Format)
+	case "template":
+		args = append(args, "-template", s.Output.Template)
+	default:
+		return nil, nil, cleanup, errors.New("\"" + s.Output.Format + "\" is not an output format, use table, json, csv, clean, or template")
+	}
+	if s.Output.Upload != "" {
+		args = append(args, "-upload", s.Output.Upload)
+	}
+	return args, secrets, cleanup, nil
+}
+
+// Converts a map of option names to values into command line options, sorted by name. Options
+// whose values are read from the environment are added to secrets instead.
+func scanOptions(m map[string]interface{}, secrets map[string]string) ([]string, error) {
+	names := []string{}
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	args := []string{}
+	for _, name := range names {
+		if v, ok := m[name].(bool); ok {
+			if v {
+				args = append(args, "-"+name)
+			}
+			continue
+		}
+		v, err := optionValue(name, m[name])
+		if err != nil {
+			return nil, err
+		}
+		if s, ok := m[name].(string); ok && strings.HasPrefix(s, "env:") {
+			secrets[name] = v
+			continue
+		}
+		args = append(args, "-"+name+"="+v)
+	}
+	return args, nil
+}
+
+// Converts the value of an option in a scan file or profile to its command line argument.
+// Strings starting with "env:" are read from the named environment variable.
+func optionValue(name string, value interface{}) (string, error) {
+	switch v := value.(type) {
+	case bool:
+		return strconv.FormatBool(v), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case string:
+		if strings.HasPrefix(v, "env:") {
+			env := strings.TrimPrefix(v, "env:")
+			v = os.Getenv(env)
+			if v == "" {
+				return "", errors.New("environment variable " + env + " for " + name + " is not set")
+			}
+		}
+		return v, nil
+	}
+	return "", errors.New("value for " + name + " must be a boolean, number, or string")
+}
+
+// Sets the options passed by the run subcommand through the environment, removing them from
+// the environment so that they are not inherited further.
+func applyScanSecrets() error {
+	data := os.Getenv(scanSecretsEnv)
+	if data == "" {
+		return nil
+	}
+	os.Unsetenv(scanSecretsEnv)
+	secrets := make(map[string]string)
+	if err := json.Unmarshal([]byte(data), &secrets); err != nil {
+		return errors.New(scanSecretsEnv + " is not valid: " + err.Error())
+	}
+	for name, v := range secrets {
+		if err := flag.Set(name, v); err != nil {
+			return errors.New("scan option \"" + name + "\": " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"container/heap"
+	"encoding/json"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+// Most run files kept before they are merged into one, so that reading results does not need
+// more open files than a typical limit allows.
+const maxRuns = 64
+
+// resultStore holds the unique results of a scan. With a limit, once it holds more than limit
+// results in memory they are sorted and written to a run file in a temporary directory, and the
+// runs are merged when results are read. A result that has been written to a run can be added
+// again, so these duplicates are only removed when the runs are merged. Only a hash of the key
+// of each written result is kept, to tell whether a result added again is new.
+type resultStore struct {
+	limit   int
+	mem     map[bsw.ResultKey]bsw.Result
+	dir     string
+	runs    []string
+	written map[uint64]bool
+}
+
+func newResultStore(limit int) *resultStore {
+	return &resultStore{limit: limit, mem: make(map[bsw.ResultKey]bsw.Result), written: make(map[uint64]bool)}
+}
+
+// Returns a hash of k.
+func keyHash(k bsw.ResultKey) uint64 {
+	h := fnv.New64a()
+	for _, f := range []string{k.Source, k.IP, k.Hostname, strconv.Itoa(k.Port), k.Protocol} {
+		h.Write([]byte(f))
+		h.Write([]byte{0})
+	}
+	return h.Sum64()
+}
+
+// Adds r, merging its metadata into the result with the same key if it is held in memory.
+// Returns whether r was not added before.
+func (s *resultStore) add(r bsw.Result) (bool, error) {
+	if existing, ok := s.mem[r.Key()]; ok {
+		s.mem[r.Key()] = bsw.Results{existing, r}.Dedupe()[0]
+		return false, nil
+	}
+	s.mem[r.Key()] = r
+	added := !s.written[keyHash(r.Key())]
+	if s.limit > 0 && len(s.mem) > s.limit {
+		return added, s.spill()
+	}
+	return added, nil
+}
+
+// Returns the results held in memory sorted by key.
+func (s *resultStore) sortedMem() bsw.Results {
+	results := make(bsw.Results, 0, len(s.mem))
+	for _, r := range s.mem {
+		results = append(results, r)
+	}
+	sort.Slice(results, func(i, j int) bool { return keyLess(results[i].Key(), results[j].Key()) })
+	return results
+}
+
+// Writes the results held in memory to a new run file.
+func (s *resultStore) spill() error {
+	if s.dir == "" {
+		dir, err := ioutil.TempDir("", "bsw-results-")
+		if err != nil {
+			return err
+		}
+		s.dir = dir
+	}
+	if len(s.runs) >= maxRuns {
+		if err := s.compact(); err != nil {
+			return err
+		}
+	}
+	path, err := s.writeRun(func(fn func(bsw.Result) error) error {
+		for _, r := range s.sortedMem() {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.runs = append(s.runs, path)
+	for k := range s.mem {
+		s.written[keyHash(k)] = true
+	}
+	s.mem = make(map[bsw.ResultKey]bsw.Result)
+	return nil
+}
+
+// Merges every run file into one.
+func (s *resultStore) compact() error {
+	path, err := s.writeRun(func(fn func(bsw.Result) error) error {
+		return mergeRuns(s.runs, nil, fn)
+	})
+	if err != nil {
+		return err
+	}
+	for _, run := range s.runs {
+		os.Remove(run)
+	}
+	s.runs = []string{path}
+	return nil
+}
+
+// Writes the sorted results produced by each to a new run file, returning its path.
+func (s *resultStore) writeRun(each func(fn func(bsw.Result) error) error) (string, error) {
+	f, err := ioutil.TempFile(s.dir, "run-")
+	if err != nil {
+		return "", err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	err = each(func(r bsw.Result) error { return enc.Encode(r) })
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+	return f.Name(), nil
+}
+
+// Returns whether any results have been written to disk.
+func (s *resultStore) spilled() bool {
+	return len(s.runs) > 0
+}
+
+// Calls fn with each unique result. Once results have been written to disk they are read in
+// key order, otherwise in no particular order.
+func (s *resultStore) each(fn func(bsw.Result) error) error {
+	if len(s.runs) == 0 {
+		for _, r := range s.mem {
+			if err := fn(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return mergeRuns(s.runs, s.sortedMem(), fn)
+}
+
+// Returns every unique result.
+func (s *resultStore) results() (bsw.Results, error) {
+	results := bsw.Results{}
+	err := s.each(func(r bsw.Result) error {
+		results = append(results, r)
+		return nil
+	})
+	return results, err
+}
+
+// Removes any run files.
+func (s *resultStore) close() {
+	if s.dir != "" {
+		os.RemoveAll(s.dir)
+	}
+}
+
+// Orders result keys by IP, then hostname, source, port, and protocol. Duplicates are adjacent
+// in any list sorted by this order.
+func keyLess(a, b bsw.ResultKey) bool {
+	if c := bytes.Compare(ipSortKey(a.IP), ipSortKey(b.IP)); c != 0 {
+		return c < 0
+	}
+	if a.IP != b.IP {
+		return a.IP < b.IP
+	}
+	if a.Hostname != b.Hostname {
+		return a.Hostname < b.Hostname
+	}
+	if a.Source != b.Source {
+		return a.Source < b.Source
+	}
+	if a.Port != b.Port {
+		return a.Port < b.Port
+	}
+	return a.Protocol < b.Protocol
+}
+
+func ipSortKey(s string) []byte {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip.To16()
+	}
+	return nil
+}
+
+// run is a source of results sorted by key, either a run file or a slice.
+type run struct {
+	dec     *json.Decoder
+	results bsw.Results
+	current bsw.Result
+}
+
+// Advances to the next result, returning false at the end of the run.
+func (r *run) next() (bool, error) {
+	if r.dec == nil {
+		if len(r.results) == 0 {
+			return false, nil
+		}
+		r.current, r.results = r.results[0], r.results[1:]
+		return true, nil
+	}
+	r.current = bsw.Result{}
+	if err := r.dec.Decode(&r.current); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+type runHeap []*run
+
+func (h runHeap) Len() int            { return len(h) }
+func (h runHeap) Less(i, j int) bool  { return keyLess(h[i].current.Key(), h[j].current.Key()) }
+func (h runHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *runHeap) Push(x interface{}) { *h = append(*h, x.(*run)) }
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	r := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return r
+}
+
+// Merges the run files at paths and the sorted results in mem, calling fn with each unique
+// result in order. The metadata of duplicates is merged.
+func mergeRuns(paths []string, mem bsw.Results, fn func(bsw.Result) error) error {
+	runs := []*run{{results: mem}}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		runs = append(runs, &run{dec: json.NewDecoder(bufio.NewReader(f))})
+	}
+	h := &runHeap{}
+	for _, r := range runs {
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			*h = append(*h, r)
+		}
+	}
+	heap.Init(h)
+	var pending *bsw.Result
+	for h.Len() > 0 {
+		r := (*h)[0]
+		current := r.current
+		ok, err := r.next()
+		if err != nil {
+			return err
+		}
+		if ok {
+			heap.Fix(h, 0)
+		} else {
+			heap.Pop(h)
+		}
+		if pending != nil && pending.Key() == current.Key() {
+			merged := bsw.Results{*pending, current}.Dedupe()[0]
+			pending = &merged
+			continue
+		}
+		if pending != nil {
+			if err := fn(*pending); err != nil {
+				return err
+			}
+		}
+		pending = &current
+	}
+	if pending != nil {
+		return fn(*pending)
+	}
+	return nil
+}
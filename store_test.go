@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+func TestResultStoreSpill(t *testing.T) {
+	s := newResultStore(1)
+	defer s.close()
+	a := bsw.Result{Source: "crt.sh", IP: "192.0.2.1", Hostname: "www.example.com"}
+	b := bsw.Result{Source: "crt.sh", IP: "192.0.2.2", Hostname: "mail.example.com"}
+	for _, r := range []bsw.Result{a, b} {
+		if added, err := s.add(r); err != nil || !added {
+			t.Error("resultStore did not add a new result")
+			t.Log(r, err)
+		}
+	}
+	if !s.spilled() {
+		t.Fatal("resultStore did not write results to disk")
+	}
+	if added, err := s.add(a); err != nil || added {
+		t.Error("resultStore added a result that was already written to disk")
+		t.Log(err)
+	}
+	results, err := s.results()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Error("resultStore returned incorrect number of results")
+		t.Log(results)
+	}
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+	"golang.org/x/net/publicsuffix"
+)
+
+// domainSummary rolls up the results within a registrable domain.
+type domainSummary struct {
+	Domain     string
+	Subdomains int
+	IPs        int
+	Ranges     int
+	Sources    []string
+}
+
+// Returns the /24 of an IPv4 address or the /64 of an IPv6 address.
+func ipRange(s string) string {
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return ""
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(24, 32)).String() + "/24"
+	}
+	return ip.Mask(net.CIDRMask(64, 128)).String() + "/64"
+}
+
+// Groups results by the registrable domain of their hostname, counting the unique hostnames,
+// IPs, and IP ranges within each, and the sources that found them. Domains with the most
+// hostnames are first.
+func summarize(results bsw.Results) []domainSummary {
+	type sets struct {
+		names, ips, ranges, sources map[string]bool
+	}
+	byDomain := make(map[string]*sets)
+	for _, r := range results {
+		name := strings.ToLower(strings.TrimSuffix(r.Hostname, "."))
+		name = strings.TrimPrefix(name, "*.")
+		if name == "" || net.ParseIP(name) != nil {
+			continue
+		}
+		d, err := publicsuffix.EffectiveTLDPlusOne(name)
+		if err != nil {
+			continue
+		}
+		s, ok := byDomain[d]
+		if !ok {
+			s = &sets{make(map[string]bool), make(map[string]bool), make(map[string]bool), make(map[string]bool)}
+			byDomain[d] = s
+		}
+		s.names[name] = true
+		s.sources[r.Source] = true
+		if rng := ipRange(r.IP); rng != "" {
+			s.ips[r.IP] = true
+			s.ranges[rng] = true
+		}
+	}
+	summaries := []domainSummary{}
+	for d, s := range byDomain {
+		sources := []string{}
+		for src := range s.sources {
+			sources = append(sources, src)
+		}
+		sort.Strings(sources)
+		summaries = append(summaries, domainSummary{Domain: d, Subdomains: len(s.names), IPs: len(s.ips), Ranges: len(s.ranges), Sources: sources})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Subdomains != summaries[j].Subdomains {
+			return summaries[i].Subdomains > summaries[j].Subdomains
+		}
+		return summaries[i].Domain < summaries[j].Domain
+	})
+	return summaries
+}
+
+func outputSummary(out io.Writer, summaries []domainSummary) {
+	w := tabwriter.NewWriter(out, 0, 8, 4, ' ', 0)
+	fmt.Fprintln(w, "Domain\tHostnames\tIPs\tRanges\tSources")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", s.Domain, s.Subdomains, s.IPs, s.Ranges, strings.Join(s.Sources, ", "))
+	}
+	w.Flush()
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"strings"
+)
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// Builds the client configuration for the TLS, headers, and SMTP tasks from the -tls-* options.
+// Empty values keep Go's defaults.
+func buildTLSConfig(certFile, keyFile, minVersion, maxVersion, ciphers string) (*tls.Config, error) {
+	c := &tls.Config{}
+	if certFile != "" || keyFile != "" {
+		if certFile == "" || keyFile == "" {
+			return nil, errors.New("-tls-cert and -tls-key must be used together")
+		}
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+	for _, v := range []struct {
+		name  string
+		value string
+		dest  *uint16
+	}{{"-tls-min", minVersion, &c.MinVersion}, {"-tls-max", maxVersion, &c.MaxVersion}} {
+		if v.value == "" {
+			continue
+		}
+		version, ok := tlsVersions[v.value]
+		if !ok {
+			return nil, errors.New(v.name + " must be one of 1.0, 1.1, 1.2, or 1.3")
+		}
+		*v.dest = version
+	}
+	if c.MinVersion != 0 && c.MaxVersion != 0 && c.MinVersion > c.MaxVersion {
+		return nil, errors.New("-tls-min is greater than -tls-max")
+	}
+	if ciphers != "" {
+		ids := make(map[string]uint16)
+		for _, s := range append(tls.CipherSuites(), tls.InsecureCipherSuites()...) {
+			ids[s.Name] = s.ID
+		}
+		for _, name := range strings.Split(ciphers, ",") {
+			id, ok := ids[strings.TrimSpace(name)]
+			if !ok {
+				return nil, errors.New("\"" + strings.TrimSpace(name) + "\" is not a known cipher suite")
+			}
+			c.CipherSuites = append(c.CipherSuites, id)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// Asks the Tor control port at addr for a new circuit so that requests leave from a different
+// exit. The control password is read from TOR_CONTROL_PASSWORD, if it is not set no password is
+// sent, which works when the control port has no authentication.
+func newTorCircuit(addr string) error {
+	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	password := strings.Replace(os.Getenv("TOR_CONTROL_PASSWORD"), "\"", "\\\"", -1)
+	for _, cmd := range []string{"AUTHENTICATE \"" + password + "\"", "SIGNAL NEWNYM"} {
+		if _, err := conn.Write([]byte(cmd + "\r\n")); err != nil {
+			return err
+		}
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(line, "250") {
+			return errors.New("tor control: " + strings.TrimSpace(line))
+		}
+	}
+	conn.Write([]byte("QUIT\r\n"))
+	return nil
+}
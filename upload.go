@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// objectStore is an S3 or GCS bucket and key prefix that output is uploaded to. Both are
+// written to using the S3 PUT Object API and AWS Signature Version 4, GCS supports this
+// through its XML API when using HMAC keys.
+type objectStore struct {
+	scheme    string
+	bucket    string
+	prefix    string
+	region    string
+	accessKey string
+	secretKey string
+	token     string
+}
+
+// Parses a destination in the form s3://bucket/prefix/ or gs://bucket/prefix/ and reads
+// credentials for it from the environment.
+func parseObjectStore(dest string) (*objectStore, error) {
+	parts := strings.SplitN(dest, "://", 2)
+	if len(parts) != 2 || (parts[0] != "s3" && parts[0] != "gs") {
+		return nil, errors.New("\"" + dest + "\" is not an s3:// or gs:// destination")
+	}
+	bp := strings.SplitN(parts[1], "/", 2)
+	if bp[0] == "" {
+		return nil, errors.New("\"" + dest + "\" does not contain a bucket")
+	}
+	s := &objectStore{scheme: parts[0], bucket: bp[0]}
+	if len(bp) == 2 {
+		s.prefix = bp[1]
+	}
+	if s.scheme == "s3" {
+		s.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.token = os.Getenv("AWS_SESSION_TOKEN")
+		s.region = os.Getenv("AWS_REGION")
+		if s.region == "" {
+			s.region = "us-east-1"
+		}
+	} else {
+		s.accessKey = os.Getenv("GOOGLE_HMAC_ACCESS_ID")
+		s.secretKey = os.Getenv("GOOGLE_HMAC_SECRET")
+		s.region = "auto"
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, errors.New("credentials for " + s.scheme + ":// uploads are not set in the environment")
+	}
+	return s, nil
+}
+
+func (s *objectStore) url(name string) string {
+	return s.scheme + "://" + s.bucket + "/" + s.prefix + name
+}
+
+// Returns the host and path used to PUT an object.
+func (s *objectStore) endpoint(name string) (string, string) {
+	key := uriEncode(s.prefix + name)
+	if s.scheme == "gs" {
+		return "storage.googleapis.com", "/" + s.bucket + "/" + key
+	}
+	return s.bucket + ".s3." + s.region + ".amazonaws.com", "/" + key
+}
+
+// Uploads data to the prefix joined with name.
+func (s *objectStore) put(name string, data []byte) error {
+	host, path := s.endpoint(name)
+	req, err := http.NewRequest("PUT", "https://"+host+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	sum := sha256.Sum256(data)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	headers := map[string]string{
+		"host":                 host,
+		"x-amz-content-sha256": payloadHash,
+		"x-amz-date":           amzDate,
+	}
+	signed := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	if s.token != "" {
+		headers["x-amz-security-token"] = s.token
+		signed = append(signed, "x-amz-security-token")
+	}
+	var canonicalHeaders string
+	for _, h := range signed {
+		canonicalHeaders += h + ":" + headers[h] + "\n"
+		if h != "host" {
+			req.Header.Set(h, headers[h])
+		}
+	}
+	canonicalRequest := strings.Join([]string{
+		"PUT",
+		path,
+		"",
+		canonicalHeaders,
+		strings.Join(signed, ";"),
+		payloadHash,
+	}, "\n")
+	crSum := sha256.Sum256([]byte(canonicalRequest))
+	scope := date + "/" + s.region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(crSum[:])
+
+	key := []byte("AWS4" + s.secretKey)
+	for _, v := range []string{date, s.region, "s3", "aws4_request"} {
+		key = hmacSHA256(key, v)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.accessKey, scope, strings.Join(signed, ";"), signature))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// Percent-encodes an object key as required by Signature Version 4, leaving '/' intact.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', c == '/':
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
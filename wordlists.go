@@ -0,0 +1,242 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tomsteele/blacksheepwall/bsw"
+)
+
+const wordlistUsage = `
+ Usage: blacksheepwall wordlist list
+        blacksheepwall wordlist fetch [-update] <name>
+
+ Downloads a public subdomain wordlist to the wordlist directory, after which it
+ can be used with -dictionary wordlist:<name>. The directory is $BSW_WORDLISTS, or
+ blacksheepwall/wordlists in the user cache directory.
+
+ The SHA-256 of a wordlist is recorded when it is first fetched and checked when it
+ is fetched again or read for -dictionary. Use -update to accept a changed wordlist.
+`
+
+// Public wordlists that can be fetched by name.
+var remoteWordlists = map[string]string{
+	"seclists-5000":   "https://raw.githubusercontent.com/danielmiessler/SecLists/master/Discovery/DNS/subdomains-top1million-5000.txt",
+	"seclists-20000":  "https://raw.githubusercontent.com/danielmiessler/SecLists/master/Discovery/DNS/subdomains-top1million-20000.txt",
+	"seclists-110000": "https://raw.githubusercontent.com/danielmiessler/SecLists/master/Discovery/DNS/subdomains-top1million-110000.txt",
+	"n0kovo-tiny":     "https://raw.githubusercontent.com/n0kovo/n0kovo_subdomains/main/n0kovo_subdomains_tiny.txt",
+	"n0kovo-small":    "https://raw.githubusercontent.com/n0kovo/n0kovo_subdomains/main/n0kovo_subdomains_small.txt",
+}
+
+// Returns the directory fetched wordlists are stored in.
+func wordlistDir() (string, error) {
+	if dir := os.Getenv("BSW_WORDLISTS"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "blacksheepwall", "wordlists"), nil
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Handles the wordlist subcommand.
+func runWordlistCommand(args []string) {
+	fs := flag.NewFlagSet("wordlist", flag.ExitOnError)
+	fs.Usage = func() { fmt.Print(wordlistUsage) }
+	flUpdate := fs.Bool("update", false, "")
+	if len(args) < 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	fs.Parse(args[1:])
+	switch args[0] {
+	case "list":
+		dir, _ := wordlistDir()
+		names := []string{}
+		for name := range remoteWordlists {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			status := ""
+			if _, err := os.Stat(filepath.Join(dir, name+".txt")); err == nil {
+				status = " (fetched)"
+			}
+			fmt.Printf("%s%s\n  %s\n", name, status, remoteWordlists[name])
+		}
+	case "fetch":
+		if fs.NArg() != 1 {
+			fs.Usage()
+			os.Exit(1)
+		}
+		path, err := fetchWordlist(fs.Arg(0), *flUpdate)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+		fmt.Println("Wrote " + path)
+	default:
+		fs.Usage()
+		os.Exit(1)
+	}
+}
+
+// Client for downloading wordlists. The largest are tens of megabytes, so the timeout is long.
+var wordlistClient = &http.Client{Timeout: 5 * time.Minute}
+
+// Downloads a wordlist by name and writes it and its checksum to the wordlist directory. If the
+// wordlist was fetched before, the download must match the recorded checksum unless update is set.
+func fetchWordlist(name string, update bool) (string, error) {
+	u, ok := remoteWordlists[name]
+	if !ok {
+		return "", errors.New("\"" + name + "\" is not a known wordlist")
+	}
+	dir, err := wordlistDir()
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	resp, err := wordlistClient.Get(u)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("fetching " + name + ": " + resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256Hex(data)
+	sumPath := filepath.Join(dir, name+".sha256")
+	if recorded, err := ioutil.ReadFile(sumPath); err == nil && !update {
+		if strings.TrimSpace(string(recorded)) != sum {
+			return "", errors.New("checksum of " + name + " does not match the one recorded when it was first fetched, use -update to accept it")
+		}
+	}
+	path := filepath.Join(dir, name+".txt")
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return "", err
+	}
+	if err := ioutil.WriteFile(sumPath, []byte(sum+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Reads a fetched wordlist by name, verifying it against its recorded checksum.
+func readFetchedWordlist(name string) ([]string, error) {
+	if _, ok := remoteWordlists[name]; !ok {
+		return nil, errors.New("\"" + name + "\" is not a known wordlist")
+	}
+	dir, err := wordlistDir()
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, name+".txt"))
+	if os.IsNotExist(err) {
+		return nil, errors.New(name + " has not been fetched, use blacksheepwall wordlist fetch " + name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	recorded, err := ioutil.ReadFile(filepath.Join(dir, name+".sha256"))
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(string(recorded)) != sha256Hex(data) {
+		return nil, errors.New("checksum of " + name + " does not match, fetch it again")
+	}
+	return strings.Fields(string(data)), nil
+}
+
+// Reads the names for -dictionary. The value is a file, builtin:<name>, or wordlist:<name>,
+// optionally followed by :top=N to keep only the N most frequent names. Names are sorted so
+// that the most frequent are resolved first. A line may hold a name and its count, in either
+// order, otherwise the list is assumed to already be ordered by frequency.
+func loadDictionary(spec string) ([]string, error) {
+	top := 0
+	if i := strings.LastIndex(spec, ":top="); i != -1 {
+		n, err := strconv.Atoi(spec[i+5:])
+		if err != nil || n < 1 {
+			return nil, errors.New("\"" + spec[i+1:] + "\" must be a positive number of names")
+		}
+		top = n
+		spec = spec[:i]
+	}
+	var lines []string
+	var err error
+	switch {
+	case strings.HasPrefix(spec, "builtin:"):
+		lines, err = bsw.Wordlist(strings.TrimPrefix(spec, "builtin:"))
+	case strings.HasPrefix(spec, "wordlist:"):
+		lines, err = readFetchedWordlist(strings.TrimPrefix(spec, "wordlist:"))
+	default:
+		lines, err = readFileLines(spec)
+	}
+	if err != nil {
+		return nil, err
+	}
+	names := sortByFrequency(lines)
+	if top > 0 && len(names) > top {
+		names = names[:top]
+	}
+	return names, nil
+}
+
+// Returns the unique names in lines, highest count first. Names without a count keep their
+// order and follow those with one.
+func sortByFrequency(lines []string) []string {
+	type candidate struct {
+		name  string
+		count int
+	}
+	seen := make(map[string]bool)
+	candidates := []candidate{}
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		c := candidate{name: fields[0], count: -1}
+		if len(fields) > 1 {
+			if n, err := strconv.Atoi(fields[1]); err == nil {
+				c.count = n
+			} else if n, err := strconv.Atoi(fields[0]); err == nil {
+				c = candidate{name: fields[1], count: n}
+			}
+		}
+		c.name = strings.ToLower(c.name)
+		if seen[c.name] {
+			continue
+		}
+		seen[c.name] = true
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].count > candidates[j].count })
+	names := make([]string, len(candidates))
+	for i, c := range candidates {
+		names[i] = c.name
+	}
+	return names
+}